@@ -0,0 +1,113 @@
+package deepl
+
+import (
+	"bufio"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// apiGolden lists the exported API, one declaration per line. Run the tests
+// with updateAPIEnv set to regenerate it after a deliberate change.
+const (
+	apiGolden    = "testdata/api.txt"
+	updateAPIEnv = "DEEPL_UPDATE_API"
+)
+
+// exportedAPI type-checks the package in dir and returns its exported
+// declarations: funcs, vars, consts, types, exported struct fields and the
+// method sets of pointers to named types.
+func exportedAPI(t *testing.T, dir string) []string {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []*ast.File
+	for _, f := range pkgs["deepl"].Files {
+		files = append(files, f)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("github.com/DaikiYamakawa/deepl-go", fset, files, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qualifier := types.RelativeTo(pkg)
+	var api []string
+	for _, name := range pkg.Scope().Names() {
+		obj := pkg.Scope().Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		typeName, ok := obj.(*types.TypeName)
+		if !ok {
+			api = append(api, types.ObjectString(obj, qualifier))
+			continue
+		}
+		if st, ok := typeName.Type().Underlying().(*types.Struct); ok {
+			api = append(api, "type "+name+" struct")
+			for i := 0; i < st.NumFields(); i++ {
+				if f := st.Field(i); f.Exported() {
+					api = append(api, "field "+name+"."+f.Name()+" "+types.TypeString(f.Type(), qualifier))
+				}
+			}
+		} else {
+			api = append(api, types.ObjectString(obj, qualifier))
+		}
+		methods := types.NewMethodSet(types.NewPointer(typeName.Type()))
+		for i := 0; i < methods.Len(); i++ {
+			if m := methods.At(i).Obj(); m.Exported() {
+				api = append(api, "method "+types.ObjectString(m, qualifier))
+			}
+		}
+	}
+	sort.Strings(api)
+	return api
+}
+
+// TestAPICompatibility fails when an exported declaration of apiGolden was
+// removed or changed. Additions are ignored.
+func TestAPICompatibility(t *testing.T) {
+	if os.Getenv(codecEnv) != "" {
+		t.Skip("the API doesn't depend on the codec")
+	}
+	api := exportedAPI(t, ".")
+	if os.Getenv(updateAPIEnv) != "" {
+		if err := ioutil.WriteFile(apiGolden, []byte(strings.Join(api, "\n")+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	f, err := os.Open(apiGolden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	current := make(map[string]bool, len(api))
+	for _, decl := range api {
+		current[decl] = true
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if decl := scanner.Text(); decl != "" && !current[decl] {
+			t.Errorf("removed or changed: %s", decl)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if t.Failed() {
+		t.Logf("if the change is deliberate, regenerate %s with %s=1 go test -run TestAPICompatibility", apiGolden, updateAPIEnv)
+	}
+}
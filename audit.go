@@ -0,0 +1,62 @@
+package deepl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// AuditRecordVersion is the schema version of AuditRecord. Fields are only
+// ever added; a change to the meaning of a field bumps the version.
+const AuditRecordVersion = 1
+
+// AuditRecord describes the effective request behind a TranslateResult
+// without the texts themselves, for audit logs.
+type AuditRecord struct {
+	Version            int            `json:"version"`
+	SourceLang         string         `json:"source_lang,omitempty"`
+	TargetLang         string         `json:"target_lang"`
+	Formality          Formality      `json:"formality,omitempty"`
+	GlossaryID         string         `json:"glossary_id,omitempty"`
+	TagHandling        TagHandling    `json:"tag_handling,omitempty"`
+	ModelType          ModelType      `json:"model_type,omitempty"`
+	SplitSentences     SplitSentences `json:"split_sentences,omitempty"`
+	PreserveFormatting bool           `json:"preserve_formatting,omitempty"`
+	// TextHashes are hex SHA-256 hashes of the texts, in request order.
+	TextHashes []string `json:"text_hashes"`
+	// RequestHash is CanonicalRequestHash of the request.
+	RequestHash string `json:"request_hash"`
+	// CostTags are the tags of WithCostTag.
+	CostTags []string `json:"cost_tags,omitempty"`
+	// RequestID is Metadata.RequestID of the result.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// WithAuditRecord makes Translate attach an AuditRecord to every result.
+func WithAuditRecord() Option {
+	return func(c *Client) error {
+		c.AuditRecords = true
+		return nil
+	}
+}
+
+func newAuditRecord(req TranslateRequest) *AuditRecord {
+	canonical := req.canonical()
+	record := &AuditRecord{
+		Version:            AuditRecordVersion,
+		SourceLang:         canonical.SourceLang,
+		TargetLang:         canonical.TargetLang,
+		Formality:          canonical.Formality,
+		GlossaryID:         canonical.GlossaryID,
+		TagHandling:        canonical.TagHandling,
+		ModelType:          canonical.ModelType,
+		SplitSentences:     canonical.SplitSentences,
+		PreserveFormatting: canonical.PreserveFormatting,
+		TextHashes:         make([]string, len(canonical.Text)),
+		RequestHash:        CanonicalRequestHash(req),
+	}
+	for i, text := range canonical.Text {
+		sum := sha256.Sum256([]byte(text))
+		record.TextHashes[i] = hex.EncodeToString(sum[:])
+	}
+	return record
+}
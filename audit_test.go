@@ -0,0 +1,52 @@
+package deepl
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestClient_TranslateAuditRecord(t *testing.T) {
+	cli, _, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+
+	req := TranslateRequest{
+		Text:       []string{"secret text"},
+		SourceLang: "en",
+		TargetLang: "de",
+		Formality:  FormalityMore,
+		GlossaryID: "def3a26b-3e84-45b3-84ae-0c0aaf3525f7",
+	}
+	res, err := cli.Translate(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Audit != nil {
+		t.Fatal("expected no audit record without WithAuditRecord")
+	}
+
+	cli.AuditRecords = true
+	res, err = cli.Translate(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(res.Audit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// pins the versioned schema
+	expected := `{"version":1,"source_lang":"EN","target_lang":"DE","formality":"more",` +
+		`"glossary_id":"def3a26b-3e84-45b3-84ae-0c0aaf3525f7",` +
+		`"text_hashes":["486a2d2abec341f9a9513329e29ea386e98b2a280d38d12a0b4a53c92ad8b26b"],` +
+		`"request_hash":"` + CanonicalRequestHash(req) + `",` +
+		`"request_id":"` + res.Metadata.RequestID + `"}`
+	got := string(b)
+	if strings.Contains(got, "secret") {
+		t.Fatalf("audit record contains the text: %s", got)
+	}
+	if got != expected {
+		t.Fatalf("audit record wrong.\nwant=%s\ngot =%s", expected, got)
+	}
+}
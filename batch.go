@@ -0,0 +1,232 @@
+package deepl
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// batchSendTimeout bounds a batch request when a caller has no deadline.
+const batchSendTimeout = 2 * time.Minute
+
+// WithMicroBatching makes TranslateText queue texts for up to maxWait or
+// maxItems texts, whichever comes first, and send them as one request. The
+// request doesn't use the values of the callers' contexts: it has a request
+// ID of its own and runs until the latest deadline of the callers, or for at
+// most two minutes when one of them has none.
+func WithMicroBatching(maxWait time.Duration, maxItems int) Option {
+	return func(c *Client) error {
+		if maxWait <= 0 || maxItems < 1 {
+			return xerrors.Errorf("Failed to configure micro-batching: invalid wait %v or size %d", maxWait, maxItems)
+		}
+		if maxItems > maxTextsPerRequest {
+			maxItems = maxTextsPerRequest
+		}
+		c.BatchMaxWait = maxWait
+		c.BatchMaxItems = maxItems
+		return nil
+	}
+}
+
+// BatchObserver receives events about logical items of batched calls, as
+// opposed to HTTP requests. Events are delivered synchronously from the
+// goroutine sending the batch, so implementations must be fast and safe for
+// concurrent use.
+type BatchObserver interface {
+	// OnFlush is called after a batch of batchSize texts was sent.
+	OnFlush(batchSize int, duration time.Duration)
+	// OnItemDone is called for every text of a batch with its position
+	// in the batch and its error, if any.
+	OnItemDone(index int, err error)
+}
+
+// Hooks are callbacks for metrics, such as the counters and histograms of
+// a metrics library. Nil hooks are skipped.
+type Hooks struct {
+	// BatchFlushed observes the size and duration of a sent batch.
+	BatchFlushed func(batchSize int, duration time.Duration)
+	// BatchItemDone counts the texts of batches, failed or not.
+	BatchItemDone func(failed bool)
+}
+
+// BatchObserverFromHooks returns a BatchObserver that forwards its events
+// to h.
+func BatchObserverFromHooks(h Hooks) BatchObserver {
+	return hooksBatchObserver{h}
+}
+
+type hooksBatchObserver struct {
+	hooks Hooks
+}
+
+func (o hooksBatchObserver) OnFlush(batchSize int, duration time.Duration) {
+	if o.hooks.BatchFlushed != nil {
+		o.hooks.BatchFlushed(batchSize, duration)
+	}
+}
+
+func (o hooksBatchObserver) OnItemDone(index int, err error) {
+	if o.hooks.BatchItemDone != nil {
+		o.hooks.BatchItemDone(err != nil)
+	}
+}
+
+// WithBatchObserver sets Client.BatchObserver.
+func WithBatchObserver(o BatchObserver) Option {
+	return func(c *Client) error {
+		c.BatchObserver = o
+		return nil
+	}
+}
+
+// TranslateText translates a single text. With micro-batching enabled it
+// is sent together with concurrent calls for the same language pair.
+func (c *Client) TranslateText(ctx context.Context, text, sourceLang, targetLang string) (*Translation, error) {
+	if c.BatchMaxWait <= 0 || c.BatchMaxItems < 1 {
+		result, err := c.Translate(ctx, TranslateRequest{Text: []string{text}, SourceLang: sourceLang, TargetLang: targetLang})
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Translations) != 1 {
+			return nil, xerrors.Errorf("Failed to translate text: expected 1 translation, got %d", len(result.Translations))
+		}
+		return &result.Translations[0], nil
+	}
+
+	c.batcherOnce.Do(func() {
+		c.batcher = &microBatcher{client: c, pending: make(map[batchKey]*batch)}
+	})
+	return c.batcher.add(ctx, text, TranslateRequest{SourceLang: sourceLang, TargetLang: targetLang}.canonical())
+}
+
+type batchKey struct {
+	src, dst string
+}
+
+type batchResult struct {
+	translation *Translation
+	err         error
+}
+
+type batchItem struct {
+	ctx  context.Context
+	text string
+	done chan batchResult
+}
+
+type batch struct {
+	items []*batchItem
+	timer Timer
+}
+
+// microBatcher coalesces TranslateText calls per language pair.
+type microBatcher struct {
+	client *Client
+
+	mu      sync.Mutex
+	pending map[batchKey]*batch
+}
+
+func (b *microBatcher) add(ctx context.Context, text string, req TranslateRequest) (*Translation, error) {
+	key := batchKey{src: req.SourceLang, dst: req.TargetLang}
+	item := &batchItem{ctx: ctx, text: text, done: make(chan batchResult, 1)}
+
+	b.mu.Lock()
+	pending, ok := b.pending[key]
+	if !ok {
+		pending = &batch{}
+		b.pending[key] = pending
+		pending.timer = b.client.clock().AfterFunc(b.client.BatchMaxWait, func() { b.flush(key, pending) })
+	}
+	pending.items = append(pending.items, item)
+	full := len(pending.items) >= b.client.BatchMaxItems
+	if full {
+		// later calls start a new batch
+		delete(b.pending, key)
+	}
+	b.mu.Unlock()
+
+	if full && pending.timer.Stop() {
+		go b.flush(key, pending)
+	}
+
+	select {
+	case res := <-item.done:
+		return res.translation, res.err
+	case <-ctx.Done():
+		return nil, xerrors.Errorf("Failed to translate text: %w", ctx.Err())
+	}
+}
+
+// flush sends the texts of p whose callers are still waiting, in the order
+// they were queued.
+func (b *microBatcher) flush(key batchKey, p *batch) {
+	b.mu.Lock()
+	if b.pending[key] == p {
+		delete(b.pending, key)
+	}
+	var items []*batchItem
+	for _, item := range p.items {
+		if item.ctx.Err() == nil {
+			items = append(items, item)
+		}
+	}
+	b.mu.Unlock()
+	if len(items) == 0 {
+		return
+	}
+
+	texts := make([]string, len(items))
+	for i, item := range items {
+		texts[i] = item.text
+	}
+	for _, ch := range b.client.chunks(texts) {
+		b.send(key, items[ch.start:ch.end], texts[ch.start:ch.end])
+	}
+}
+
+// batchContext returns the context of the request for items, with its own
+// request ID and the latest deadline of the items.
+func batchContext(items []*batchItem) (context.Context, context.CancelFunc) {
+	ctx, _ := withRequestID(context.Background())
+	var latest time.Time
+	for _, item := range items {
+		deadline, ok := item.ctx.Deadline()
+		if !ok {
+			return context.WithTimeout(ctx, batchSendTimeout)
+		}
+		if deadline.After(latest) {
+			latest = deadline
+		}
+	}
+	return context.WithDeadline(ctx, latest)
+}
+
+// send translates texts in one request and hands each item its result.
+func (b *microBatcher) send(key batchKey, items []*batchItem, texts []string) {
+	req := TranslateRequest{SourceLang: key.src, TargetLang: key.dst, Text: texts}
+	// callers cancel their own wait, not the shared request
+	ctx, cancel := batchContext(items)
+	defer cancel()
+	start := b.client.clock().Now()
+	result, err := b.client.Translate(ctx, req)
+	if err == nil && len(result.Translations) != len(items) {
+		err = xerrors.Errorf("Failed to translate batch: expected %d translations, got %d", len(items), len(result.Translations))
+	}
+	observer := b.client.BatchObserver
+	if observer != nil {
+		observer.OnFlush(len(items), b.client.clock().Now().Sub(start))
+	}
+	for i, item := range items {
+		if observer != nil {
+			observer.OnItemDone(i, err)
+		}
+		if err != nil {
+			item.done <- batchResult{err: err}
+			continue
+		}
+		item.done <- batchResult{translation: &result.Translations[i]}
+	}
+}
@@ -0,0 +1,214 @@
+package deepl
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+func TestClient_TranslateTextMicroBatching(t *testing.T) {
+	cli, received, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	clock := newFakeClock()
+	cli.Clock = clock
+	cli.BatchMaxWait = time.Minute
+	cli.BatchMaxItems = 3
+
+	texts := []string{"one", "two", "three", "four"}
+	results := make([]string, len(texts))
+	var wg sync.WaitGroup
+	for i, text := range texts {
+		wg.Add(1)
+		go func(i int, text string) {
+			defer wg.Done()
+			translation, err := cli.TranslateText(context.Background(), text, "EN", "DE")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			results[i] = translation.Text
+		}(i, text)
+		// keep the queue order deterministic
+		time.Sleep(5 * time.Millisecond)
+	}
+	clock.waitTimers(t, 1)
+	clock.Advance(time.Minute)
+	wg.Wait()
+
+	for i, text := range texts {
+		if results[i] != "DE:"+text {
+			t.Errorf("result %d = %q, expected %q", i, results[i], "DE:"+text)
+		}
+	}
+	// three texts fill the first batch, the fourth waits for the timer
+	if len(*received) != 2 {
+		t.Fatalf("sent %d requests, expected 2", len(*received))
+	}
+	first := (*received)[0].Text
+	if len(first) != 3 || first[0] != "one" || first[1] != "two" || first[2] != "three" {
+		t.Fatalf("unexpected first batch %q", first)
+	}
+}
+
+func TestClient_TranslateTextMicroBatchingCanceled(t *testing.T) {
+	cli, received, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	cli.BatchMaxWait = 50 * time.Millisecond
+	cli.BatchMaxItems = 10
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error, 1)
+	go func() {
+		_, err := cli.TranslateText(ctx, "dropped", "EN", "DE")
+		canceled <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+	if err := <-canceled; err == nil {
+		t.Fatal("expected error for canceled call")
+	}
+
+	translation, err := cli.TranslateText(context.Background(), "kept", "EN", "DE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if translation.Text != "DE:kept" {
+		t.Fatalf("unexpected translation %q", translation.Text)
+	}
+	if len(*received) != 1 || len((*received)[0].Text) != 1 || (*received)[0].Text[0] != "kept" {
+		t.Fatalf("canceled text was sent: %+v", *received)
+	}
+}
+
+func TestClient_TranslateTextUnbatched(t *testing.T) {
+	cli, received, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+
+	translation, err := cli.TranslateText(context.Background(), "Hello", "EN", "JA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if translation.Text != "JA:Hello" || len(*received) != 1 {
+		t.Fatalf("unexpected translation %q after %d requests", translation.Text, len(*received))
+	}
+	if _, err := New("https://api.deepl.com", nil, WithMicroBatching(0, 10)); err == nil {
+		t.Fatal("expected error for zero batch wait")
+	}
+}
+
+type recordingObserver struct {
+	mu     sync.Mutex
+	sizes  []int
+	items  []int
+	errors int
+}
+
+func (o *recordingObserver) OnFlush(batchSize int, duration time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.sizes = append(o.sizes, batchSize)
+}
+
+func (o *recordingObserver) OnItemDone(index int, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.items = append(o.items, index)
+	if err != nil {
+		o.errors++
+	}
+}
+
+func TestClient_BatchObserver(t *testing.T) {
+	cli, _, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	observer := &recordingObserver{}
+	cli.BatchMaxWait = 20 * time.Millisecond
+	cli.BatchMaxItems = 2
+	cli.BatchObserver = observer
+
+	var wg sync.WaitGroup
+	for _, text := range []string{"one", "two"} {
+		wg.Add(1)
+		go func(text string) {
+			defer wg.Done()
+			if _, err := cli.TranslateText(context.Background(), text, "EN", "DE"); err != nil {
+				t.Error(err)
+			}
+		}(text)
+	}
+	wg.Wait()
+
+	observer.mu.Lock()
+	defer observer.mu.Unlock()
+	if len(observer.sizes) != 1 || observer.sizes[0] != 2 {
+		t.Fatalf("unexpected flushes %v", observer.sizes)
+	}
+	if len(observer.items) != 2 || observer.items[0] != 0 || observer.items[1] != 1 || observer.errors != 0 {
+		t.Fatalf("unexpected item events %v with %d errors", observer.items, observer.errors)
+	}
+}
+
+func TestBatchObserverFromHooks(t *testing.T) {
+	var sizes []int
+	var failed []bool
+	observer := BatchObserverFromHooks(Hooks{
+		BatchFlushed:  func(batchSize int, duration time.Duration) { sizes = append(sizes, batchSize) },
+		BatchItemDone: func(f bool) { failed = append(failed, f) },
+	})
+	observer.OnFlush(2, time.Millisecond)
+	observer.OnItemDone(0, nil)
+	observer.OnItemDone(1, xerrors.New("failed"))
+	if !reflect.DeepEqual(sizes, []int{2}) || !reflect.DeepEqual(failed, []bool{false, true}) {
+		t.Fatalf("unexpected events: sizes %v, failed %v", sizes, failed)
+	}
+
+	// hooks left nil are skipped
+	observer = BatchObserverFromHooks(Hooks{})
+	observer.OnFlush(1, time.Millisecond)
+	observer.OnItemDone(0, nil)
+}
+
+func TestClient_TranslateTextMicroBatchingDeadline(t *testing.T) {
+	released := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// the upstream hangs until the batch request gives up; the body must
+		// be read for the server to notice
+		ioutil.ReadAll(req.Body)
+		<-req.Context().Done()
+		released <- struct{}{}
+	}))
+	defer server.Close()
+	cli, err := New(server.URL, nil, WithAPIKey(testAPIKey), WithMicroBatching(time.Millisecond, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ContextWithRequestID(context.Background(), "caller"), 50*time.Millisecond)
+	defer cancel()
+	if _, err := cli.TranslateText(ctx, "Hello", "EN", "DE"); err == nil {
+		t.Fatal("expected error for the hanging upstream")
+	}
+	select {
+	case <-released:
+	case <-time.After(5 * time.Second):
+		t.Fatal("batch request outlived the deadline of its caller")
+	}
+
+	items := []*batchItem{{ctx: ctx}, {ctx: context.Background()}}
+	batchCtx, cancelBatch := batchContext(items)
+	defer cancelBatch()
+	deadline, ok := batchCtx.Deadline()
+	if !ok || time.Until(deadline) <= batchSendTimeout-time.Minute {
+		t.Fatalf("expected the bounded timeout for a caller without deadline, got %v", deadline)
+	}
+	if id, ok := RequestIDFromContext(batchCtx); !ok || id == "caller" {
+		t.Fatalf("expected a request ID of the batch, got %q", id)
+	}
+}
@@ -0,0 +1,122 @@
+package deepl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+// BatchItem is a text of TranslateBatch with its own options, which take
+// precedence over the options of the call.
+type BatchItem struct {
+	Text    string
+	Options []TranslateOption
+}
+
+// BatchGroup describes the items of a TranslateBatch call that were sent
+// with the same effective options.
+type BatchGroup struct {
+	// Options are the effective options of the group, without texts.
+	Options ResolvedOptions
+	// Indexes are the positions of the group's items in the batch.
+	Indexes []int
+	// Characters is the number of characters sent for the group.
+	Characters int
+	// Metadata has an entry per request sent for the group.
+	Metadata []Metadata
+}
+
+// BatchResult is the result of TranslateBatch.
+type BatchResult struct {
+	// Translations has a translation per item, in batch order.
+	Translations []Translation
+	// Groups are ordered by their first item.
+	Groups []BatchGroup
+}
+
+// Texts returns the translated texts in batch order.
+func (r *BatchResult) Texts() []string {
+	texts := make([]string, len(r.Translations))
+	for i, t := range r.Translations {
+		texts[i] = t.Text
+	}
+	return texts
+}
+
+// TranslateBatch translates items whose options may differ. Items are
+// grouped by their effective options, see ResolveOptions, and every group
+// is sent in requests of its own, split according to MaxTextsPerRequest
+// and MaxRequestBytes. req.Text is ignored. Only options changing request
+// parameters, the timeout or soft-fail mode are used. On error, the partial
+// result holds the translations of the requests that succeeded, and the
+// JobReport of a *QuotaExceededError identifies items by their index.
+func (c *Client) TranslateBatch(ctx context.Context, req TranslateRequest, items []BatchItem, opts ...TranslateOption) (*BatchResult, error) {
+	// the requests are one logical call
+	ctx, _ = withRequestID(ctx)
+	req.Text = nil
+	result := &BatchResult{Translations: make([]Translation, len(items))}
+	groups := map[string]int{}
+	for i, item := range items {
+		resolved := c.ResolveOptions(req, append(append([]TranslateOption(nil), opts...), item.Options...)...)
+		key := fmt.Sprintf("%s %v %t", CanonicalRequestHash(resolved.Request), resolved.Timeout, resolved.SoftFail)
+		g, ok := groups[key]
+		if !ok {
+			g = len(result.Groups)
+			groups[key] = g
+			result.Groups = append(result.Groups, BatchGroup{Options: resolved})
+		}
+		result.Groups[g].Indexes = append(result.Groups[g].Indexes, i)
+	}
+
+	var completed []string
+	characters := 0
+	for g := range result.Groups {
+		group := &result.Groups[g]
+		texts := make([]string, len(group.Indexes))
+		ids := make([]string, len(group.Indexes))
+		for j, i := range group.Indexes {
+			texts[j] = items[i].Text
+			ids[j] = strconv.Itoa(i)
+			group.Characters += utf8.RuneCountInString(items[i].Text)
+		}
+		var callOpts []TranslateOption
+		if group.Options.Timeout > 0 {
+			callOpts = append(callOpts, WithCallTimeout(group.Options.Timeout))
+		}
+		if group.Options.SoftFail {
+			callOpts = append(callOpts, WithCallSoftFail())
+		}
+		err := c.runJob(ctx, group.Options.Request, texts, ids, func(start int, res *TranslateResult) error {
+			if start+len(res.Translations) > len(texts) {
+				return &TranslationCountError{Expected: len(texts) - start, Got: len(res.Translations)}
+			}
+			for j, t := range res.Translations {
+				result.Translations[group.Indexes[start+j]] = t
+			}
+			group.Metadata = append(group.Metadata, res.Metadata)
+			return nil
+		}, callOpts...)
+		var quotaErr *QuotaExceededError
+		if xerrors.As(err, &quotaErr) {
+			// report the whole batch, not only this group
+			report := quotaErr.Report
+			report.Completed = append(completed, report.Completed...)
+			report.Characters += characters
+			for _, later := range result.Groups[g+1:] {
+				for _, i := range later.Indexes {
+					report.Remaining = append(report.Remaining, strconv.Itoa(i))
+				}
+			}
+			return result, &QuotaExceededError{Report: report, Err: quotaErr.Err}
+		}
+		if err != nil {
+			return result, err
+		}
+		completed = append(completed, ids...)
+		characters += group.Characters
+	}
+	return result, nil
+}
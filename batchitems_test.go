@@ -0,0 +1,83 @@
+package deepl
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestClient_TranslateBatch(t *testing.T) {
+	cli, received, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	cli.MaxTextsPerRequest = 2
+
+	items := []BatchItem{
+		{Text: "a"},
+		{Text: "b", Options: []TranslateOption{WithFormality(FormalityMore)}},
+		{Text: "c", Options: []TranslateOption{WithGlossary("g1")}},
+		{Text: "d"},
+		{Text: "e", Options: []TranslateOption{WithFormality("MORE")}},
+		{Text: "f", Options: []TranslateOption{WithFormality(FormalityLess)}},
+		{Text: "g"},
+	}
+	res, err := cli.TranslateBatch(context.Background(), TranslateRequest{TargetLang: "DE"}, items, WithFormality(FormalityLess))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"DE:a", "DE:b", "DE:c", "DE:d", "DE:e", "DE:f", "DE:g"}; !reflect.DeepEqual(res.Texts(), expected) {
+		t.Fatalf("translations wrong.\nwant=%q\ngot =%q", expected, res.Texts())
+	}
+
+	expectedRequests := []TranslateRequest{
+		{Text: []string{"a", "d"}, TargetLang: "DE", Formality: FormalityLess},
+		{Text: []string{"f", "g"}, TargetLang: "DE", Formality: FormalityLess},
+		{Text: []string{"b", "e"}, TargetLang: "DE", Formality: FormalityMore},
+		{Text: []string{"c"}, TargetLang: "DE", Formality: FormalityLess, GlossaryID: "g1"},
+	}
+	if !reflect.DeepEqual(*received, expectedRequests) {
+		t.Fatalf("requests wrong.\nwant=%+v\ngot =%+v", expectedRequests, *received)
+	}
+
+	expectedIndexes := [][]int{{0, 3, 5, 6}, {1, 4}, {2}}
+	expectedFormalities := []Formality{FormalityLess, FormalityMore, FormalityLess}
+	if len(res.Groups) != len(expectedIndexes) {
+		t.Fatalf("expected %d groups, got %+v", len(expectedIndexes), res.Groups)
+	}
+	for g, group := range res.Groups {
+		if !reflect.DeepEqual(group.Indexes, expectedIndexes[g]) || group.Characters != len(group.Indexes) {
+			t.Fatalf("group %d wrong: %+v", g, group)
+		}
+		if group.Options.Request.Formality != expectedFormalities[g] || group.Options.Request.Text != nil {
+			t.Fatalf("group %d options wrong: %+v", g, group.Options)
+		}
+	}
+	if len(res.Groups[0].Metadata) != 2 || res.Groups[0].Metadata[0].RequestID != res.Groups[2].Metadata[0].RequestID {
+		t.Fatalf("group metadata wrong: %+v", res.Groups)
+	}
+}
+
+func TestClient_TranslateBatchQuotaExceeded(t *testing.T) {
+	cli, teardown := initQuotaServer(t, 2)
+	defer teardown()
+
+	items := []BatchItem{
+		{Text: "a"},
+		{Text: "b", Options: []TranslateOption{WithFormality(FormalityMore)}},
+		{Text: "c"},
+		{Text: "d"},
+	}
+	res, err := cli.TranslateBatch(context.Background(), TranslateRequest{TargetLang: "DE"}, items)
+	quotaErr, ok := err.(*QuotaExceededError)
+	if !ok || !IsQuotaError(err) {
+		t.Fatalf("expected *QuotaExceededError, got %v", err)
+	}
+	if res == nil || !reflect.DeepEqual(res.Texts(), []string{"DE:a", "", "DE:c", ""}) {
+		t.Fatalf("unexpected partial result %+v", res)
+	}
+
+	expected := JobReport{Completed: []string{"0", "2"}, Remaining: []string{"3", "1"}, Characters: 2}
+	if !reflect.DeepEqual(quotaErr.Report, expected) {
+		t.Fatalf("report wrong. want=%+v, got=%+v", expected, quotaErr.Report)
+	}
+}
@@ -0,0 +1,181 @@
+package deepl
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+// Benchmarks are named so that `go test -run '^$' -bench . -benchmem -count 10`
+// output from two commits can be compared with benchstat.
+
+const benchTextCount = 50
+
+func benchTexts() []string {
+	texts := make([]string, benchTextCount)
+	for i := range texts {
+		texts[i] = strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20)
+	}
+	return texts
+}
+
+func benchResponseBody(b *testing.B) []byte {
+	result := TranslateResult{Translations: make([]Translation, benchTextCount)}
+	for i := range result.Translations {
+		result.Translations[i] = Translation{
+			DetectedSourceLanguage: "EN",
+			Text:                   strings.Repeat("素早い茶色の狐がのろまな犬を飛び越える。", 20),
+		}
+	}
+	body, err := json.Marshal(result)
+	if err != nil {
+		b.Fatalf("failed to build response body: %s", err.Error())
+	}
+	return body
+}
+
+func BenchmarkTranslateBody_JSON(b *testing.B) {
+	cli := &Client{}
+	req := &TranslateRequest{Text: benchTexts(), SourceLang: "EN", TargetLang: "JA", Formality: FormalityMore}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := cli.translateBody(req, false).Encode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTranslateBody_Form(b *testing.B) {
+	cli := &Client{RequestEncoding: RequestEncodingForm}
+	req := &TranslateRequest{Text: benchTexts(), SourceLang: "EN", TargetLang: "JA", Formality: FormalityMore}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := cli.translateBody(req, false).Encode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkResponseParse_Large(b *testing.B) {
+	body := benchResponseBody(b)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var result TranslateResult
+		resp := &http.Response{StatusCode: http.StatusOK, ContentLength: int64(len(body)), Body: ioutil.NopCloser(bytes.NewReader(body))}
+		if err := responseParse(resp, &result, stdJSON{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchClient returns a client of a server answering with
+// benchResponseBody.
+func benchClient(b *testing.B) (*Client, func()) {
+	body := benchResponseBody(b)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		w.Write(body)
+	}))
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		b.Fatalf("failed to get mock server URL: %s", err.Error())
+	}
+	return &Client{BaseURL: serverURL, HTTPClient: server.Client(), APIKey: testAPIKey}, server.Close
+}
+
+func BenchmarkClient_TranslateRoundTrip(b *testing.B) {
+	cli, teardown := benchClient(b)
+	defer teardown()
+	req := TranslateRequest{Text: benchTexts(), SourceLang: "EN", TargetLang: "JA"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := cli.Translate(context.Background(), req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkClient_TranslateCacheHit(b *testing.B) {
+	cli, teardown := benchClient(b)
+	defer teardown()
+	cli.TranslationCache = &mapCache{m: map[string][]Translation{}}
+	req := TranslateRequest{Text: benchTexts(), SourceLang: "EN", TargetLang: "JA"}
+	// warm the cache
+	if _, err := cli.Translate(context.Background(), req); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		res, err := cli.Translate(context.Background(), req)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if !res.Metadata.Cached {
+			b.Fatal("expected a cache hit")
+		}
+	}
+}
+
+func BenchmarkClient_TranslateCacheMiss(b *testing.B) {
+	cli, teardown := benchClient(b)
+	defer teardown()
+	cache := &mapCache{}
+	cli.TranslationCache = cache
+	req := TranslateRequest{Text: benchTexts(), SourceLang: "EN", TargetLang: "JA"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// a cold cache for every call
+		cache.m = map[string][]Translation{}
+		res, err := cli.Translate(context.Background(), req)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if res.Metadata.Cached {
+			b.Fatal("expected a cache miss")
+		}
+	}
+}
+
+func BenchmarkClient_EndpointCached(b *testing.B) {
+	baseURL, _ := url.Parse("https://api.deepl.com/proxy/")
+	cli := &Client{BaseURL: baseURL}
+	params := url.Values{"auth_key": {"key"}}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = cli.endpoint("/v2/translate").requestURL(params)
+	}
+}
+
+func BenchmarkClient_EndpointUncached(b *testing.B) {
+	baseURL, _ := url.Parse("https://api.deepl.com/proxy/")
+	params := url.Values{"auth_key": {"key"}}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = resolveEndpoint(baseURL, "/v2/translate").requestURL(params)
+	}
+}
@@ -0,0 +1,149 @@
+package deepl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SentencePair is a source sentence and its translation.
+type SentencePair struct {
+	// TextIndex is the index of the request text the pair belongs to.
+	TextIndex int
+	Source    string
+	Target    string
+	// Chunk is set when sentence alignment failed for the text and the pair
+	// covers the whole text instead of a single sentence.
+	Chunk bool
+}
+
+// BilingualResult is returned by TranslateBilingual.
+type BilingualResult struct {
+	Pairs    []SentencePair
+	Metadata Metadata
+	// Requests is the number of API requests that were made.
+	Requests int
+}
+
+// TranslateBilingual translates req.Text sentence by sentence and returns the
+// sentences aligned one-to-one with their translations.
+//
+// Each text is split into sentences on the client and every sentence is
+// sent as its own text with split_sentences=0, so at most 50 sentences fit in
+// a request (fewer with WithMaxTextsPerRequest) and DeepL translates each
+// sentence without the surrounding context. Texts whose alignment fails are translated again as a whole and
+// returned as a single Chunk pair. Both cost more requests than Translate;
+// the count is reported in BilingualResult.Requests. The JobReport of a
+// *QuotaExceededError identifies sentences by text and sentence index, such
+// as "1.0" for the first sentence of the second text, and texts translated
+// as a whole by their index.
+func (c *Client) TranslateBilingual(ctx context.Context, req TranslateRequest) (*BilingualResult, error) {
+	var result BilingualResult
+
+	var sentences, sentenceIDs []string
+	var owners []int
+	for i, text := range req.Text {
+		for k, s := range splitSentences(text) {
+			sentences = append(sentences, s)
+			sentenceIDs = append(sentenceIDs, fmt.Sprintf("%d.%d", i, k))
+			owners = append(owners, i)
+		}
+	}
+
+	sentenceReq := req
+	sentenceReq.SplitSentences = SplitSentencesOff
+	targets := make([]string, 0, len(sentences))
+	err := c.runJob(ctx, sentenceReq, sentences, sentenceIDs, func(start int, translated *TranslateResult) error {
+		result.Requests++
+		result.Metadata = translated.Metadata
+		targets = append(targets, translated.Texts()...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// a text is misaligned when DeepL returned a different number of
+	// translations or dropped a sentence
+	misaligned := make(map[int]bool)
+	if len(targets) != len(sentences) {
+		for i := range req.Text {
+			misaligned[i] = true
+		}
+	} else {
+		for i, target := range targets {
+			if strings.TrimSpace(target) == "" && strings.TrimSpace(sentences[i]) != "" {
+				misaligned[owners[i]] = true
+			}
+		}
+	}
+
+	var chunkTexts, chunkIDs []string
+	var chunkIndexes []int
+	for i, text := range req.Text {
+		if misaligned[i] {
+			chunkTexts = append(chunkTexts, text)
+			chunkIDs = append(chunkIDs, strconv.Itoa(i))
+			chunkIndexes = append(chunkIndexes, i)
+		}
+	}
+	chunkTargets := make(map[int]string, len(chunkTexts))
+	if len(chunkTexts) > 0 {
+		err = c.runJob(ctx, req, chunkTexts, chunkIDs, func(start int, translated *TranslateResult) error {
+			result.Requests++
+			for j, t := range translated.Translations {
+				chunkTargets[chunkIndexes[start+j]] = t.Text
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, text := range req.Text {
+		if misaligned[i] {
+			result.Pairs = append(result.Pairs, SentencePair{TextIndex: i, Source: text, Target: chunkTargets[i], Chunk: true})
+			continue
+		}
+		for j, s := range sentences {
+			if owners[j] == i {
+				result.Pairs = append(result.Pairs, SentencePair{TextIndex: i, Source: s, Target: targets[j]})
+			}
+		}
+	}
+	return &result, nil
+}
+
+// splitSentences splits text after sentence-ending punctuation. Latin
+// terminators need following whitespace so that "3.5" or "e.g." inside a
+// word stay intact; CJK terminators end a sentence immediately.
+func splitSentences(text string) []string {
+	var sentences []string
+	runes := []rune(text)
+	start := 0
+	for i, r := range runes {
+		end := -1
+		switch r {
+		case '。', '！', '？':
+			end = i + 1
+		case '.', '!', '?':
+			if i+1 == len(runes) || unicode.IsSpace(runes[i+1]) {
+				end = i + 1
+			}
+		}
+		if end < 0 {
+			continue
+		}
+		if s := strings.TrimSpace(string(runes[start:end])); s != "" {
+			sentences = append(sentences, s)
+		}
+		start = end
+	}
+	if s := strings.TrimSpace(string(runes[start:])); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
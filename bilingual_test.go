@@ -0,0 +1,108 @@
+package deepl
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestSplitSentences(t *testing.T) {
+	tt := []struct {
+		name string
+
+		inputText string
+
+		expectedSentences []string
+	}{
+		{name: "latin", inputText: "Hello world. How are you? Fine!", expectedSentences: []string{"Hello world.", "How are you?", "Fine!"}},
+		{name: "decimal stays", inputText: "It costs 3.5 euros. Cheap.", expectedSentences: []string{"It costs 3.5 euros.", "Cheap."}},
+		{name: "cjk", inputText: "こんにちは。元気ですか？", expectedSentences: []string{"こんにちは。", "元気ですか？"}},
+		{name: "no terminator", inputText: "  just words ", expectedSentences: []string{"just words"}},
+		{name: "empty", inputText: "", expectedSentences: nil},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := splitSentences(tc.inputText); !reflect.DeepEqual(got, tc.expectedSentences) {
+				t.Fatalf("sentences wrong. want=%q, got=%q", tc.expectedSentences, got)
+			}
+		})
+	}
+}
+
+func TestClient_TranslateBilingual(t *testing.T) {
+	tt := []struct {
+		name string
+
+		inputTexts []string
+		translate  func(req TranslateRequest) []Translation
+
+		expectedPairs    []SentencePair
+		expectedRequests int
+	}{
+		{
+			name: "aligned",
+
+			inputTexts: []string{"Hello. Bye.", "Thanks!"},
+			translate:  prefixTranslations,
+
+			expectedPairs: []SentencePair{
+				{TextIndex: 0, Source: "Hello.", Target: "JA:Hello."},
+				{TextIndex: 0, Source: "Bye.", Target: "JA:Bye."},
+				{TextIndex: 1, Source: "Thanks!", Target: "JA:Thanks!"},
+			},
+			expectedRequests: 1,
+		},
+		{
+			name: "dropped sentence falls back to chunk",
+
+			inputTexts: []string{"Hello. Bye.", "Thanks!"},
+			translate: func(req TranslateRequest) []Translation {
+				translations := prefixTranslations(req)
+				for i, text := range req.Text {
+					if text == "Bye." {
+						translations[i].Text = ""
+					}
+				}
+				return translations
+			},
+
+			expectedPairs: []SentencePair{
+				{TextIndex: 0, Source: "Hello. Bye.", Target: "JA:Hello. Bye.", Chunk: true},
+				{TextIndex: 1, Source: "Thanks!", Target: "JA:Thanks!"},
+			},
+			expectedRequests: 2,
+		},
+		{
+			name: "more than one request worth of sentences",
+
+			inputTexts: []string{strings.Repeat("a. ", 60)},
+			translate:  prefixTranslations,
+
+			expectedRequests: 2,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, received, teardown := initTranslateServer(t, tc.translate)
+			defer teardown()
+
+			result, err := cli.TranslateBilingual(context.Background(), TranslateRequest{Text: tc.inputTexts, TargetLang: "JA"})
+			if err != nil {
+				t.Fatalf("response error should be nil. got=%s", err.Error())
+			}
+			if result.Requests != tc.expectedRequests || len(*received) != tc.expectedRequests {
+				t.Fatalf("request count wrong. want=%d, got=%d (server saw %d)", tc.expectedRequests, result.Requests, len(*received))
+			}
+			if (*received)[0].SplitSentences != SplitSentencesOff {
+				t.Fatalf("split_sentences wrong. want=%s, got=%s", SplitSentencesOff, (*received)[0].SplitSentences)
+			}
+			if tc.expectedPairs != nil && !reflect.DeepEqual(result.Pairs, tc.expectedPairs) {
+				t.Fatalf("pairs wrong. want=%+v, got=%+v", tc.expectedPairs, result.Pairs)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package deepl
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/url"
+	"sort"
+
+	"golang.org/x/xerrors"
+)
+
+// RequestBody encodes a request payload for Client.Do. The returned content
+// type always matches the encoded bytes.
+type RequestBody interface {
+	Encode() (contentType string, body []byte, err error)
+}
+
+// FormBody is sent as application/x-www-form-urlencoded. For GET requests
+// it is encoded into the query string instead.
+type FormBody url.Values
+
+func (b FormBody) Encode() (string, []byte, error) {
+	return "application/x-www-form-urlencoded", []byte(url.Values(b).Encode()), nil
+}
+
+// JSONBody is sent as application/json.
+type JSONBody struct {
+	Value interface{}
+}
+
+func (b JSONBody) Encode() (string, []byte, error) {
+	bodyBytes, err := json.Marshal(b.Value)
+	if err != nil {
+		return "", nil, xerrors.Errorf("Failed to encode JSON body: %w", err)
+	}
+	return "application/json", bodyBytes, nil
+}
+
+// MultipartBody is sent as multipart/form-data. Fields are written in key
+// order followed by Files. A random boundary is used when Boundary is empty.
+type MultipartBody struct {
+	Boundary string
+	Fields   url.Values
+	Files    []MultipartFile
+}
+
+type MultipartFile struct {
+	FieldName string
+	FileName  string
+	Content   []byte
+}
+
+func (b MultipartBody) Encode() (string, []byte, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if b.Boundary != "" {
+		if err := w.SetBoundary(b.Boundary); err != nil {
+			return "", nil, xerrors.Errorf("Failed to set multipart boundary: %w", err)
+		}
+	}
+
+	keys := make([]string, 0, len(b.Fields))
+	for key := range b.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, v := range b.Fields[key] {
+			if err := w.WriteField(key, v); err != nil {
+				return "", nil, xerrors.Errorf("Failed to write multipart field: %w", err)
+			}
+		}
+	}
+
+	for _, f := range b.Files {
+		part, err := w.CreateFormFile(f.FieldName, f.FileName)
+		if err != nil {
+			return "", nil, xerrors.Errorf("Failed to create multipart file: %w", err)
+		}
+		if _, err := part.Write(f.Content); err != nil {
+			return "", nil, xerrors.Errorf("Failed to write multipart file: %w", err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return "", nil, xerrors.Errorf("Failed to close multipart body: %w", err)
+	}
+	return w.FormDataContentType(), buf.Bytes(), nil
+}
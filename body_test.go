@@ -0,0 +1,193 @@
+package deepl
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestRequestBody_Encode(t *testing.T) {
+	tt := []struct {
+		name string
+
+		inputBody RequestBody
+
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name: "form",
+
+			inputBody: FormBody{"text": {"hello", "a&b"}, "target_lang": {"JA"}},
+
+			expectedContentType: "application/x-www-form-urlencoded",
+			expectedBody:        "target_lang=JA&text=hello&text=a%26b",
+		},
+		{
+			name: "empty form",
+
+			inputBody: FormBody{},
+
+			expectedContentType: "application/x-www-form-urlencoded",
+			expectedBody:        "",
+		},
+		{
+			name: "json",
+
+			inputBody: JSONBody{Value: map[string]interface{}{"text": []string{"hello"}, "target_lang": "JA"}},
+
+			expectedContentType: "application/json",
+			expectedBody:        `{"target_lang":"JA","text":["hello"]}`,
+		},
+		{
+			name: "multipart",
+
+			inputBody: MultipartBody{
+				Boundary: "test-boundary",
+				Fields:   url.Values{"target_lang": {"JA"}, "filename": {"doc.txt"}},
+				Files:    []MultipartFile{{FieldName: "file", FileName: "doc.txt", Content: []byte("hello\r\n")}},
+			},
+
+			expectedContentType: "multipart/form-data; boundary=test-boundary",
+			expectedBody: "--test-boundary\r\n" +
+				"Content-Disposition: form-data; name=\"filename\"\r\n\r\n" +
+				"doc.txt\r\n" +
+				"--test-boundary\r\n" +
+				"Content-Disposition: form-data; name=\"target_lang\"\r\n\r\n" +
+				"JA\r\n" +
+				"--test-boundary\r\n" +
+				"Content-Disposition: form-data; name=\"file\"; filename=\"doc.txt\"\r\n" +
+				"Content-Type: application/octet-stream\r\n\r\n" +
+				"hello\r\n\r\n" +
+				"--test-boundary--\r\n",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			contentType, body, err := tc.inputBody.Encode()
+			if err != nil {
+				t.Fatalf("encode error should be nil. got=%s", err.Error())
+			}
+			if contentType != tc.expectedContentType {
+				t.Fatalf("content type wrong. want=%s, got=%s", tc.expectedContentType, contentType)
+			}
+			if string(body) != tc.expectedBody {
+				t.Fatalf("body wrong. want=%q, got=%q", tc.expectedBody, body)
+			}
+		})
+	}
+}
+
+func TestMultipartBody_RandomBoundary(t *testing.T) {
+	contentType, body, err := MultipartBody{Fields: url.Values{"a": {"b"}}}.Encode()
+	if err != nil {
+		t.Fatalf("encode error should be nil. got=%s", err.Error())
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Content-Type", contentType)
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatalf("failed to parse multipart body: %s", err.Error())
+	}
+	if got := req.FormValue("a"); got != "b" {
+		t.Fatalf("multipart field wrong. want=b, got=%s", got)
+	}
+}
+
+func TestMultipartBody_InvalidBoundary(t *testing.T) {
+	_, _, err := MultipartBody{Boundary: "bad boundary\n"}.Encode()
+	if err == nil {
+		t.Fatalf("encode error should not be non-nil. got=nil")
+	}
+}
+
+func TestClient_DoContentType(t *testing.T) {
+	tt := []struct {
+		name string
+
+		inputMethod string
+		inputBody   RequestBody
+
+		expectedContentType string
+		expectedRawQuery    string
+	}{
+		{
+			name: "form",
+
+			inputMethod: http.MethodPost,
+			inputBody:   FormBody{"a": {"b"}},
+
+			expectedContentType: "application/x-www-form-urlencoded",
+			expectedRawQuery:    "",
+		},
+		{
+			name: "json",
+
+			inputMethod: http.MethodPost,
+			inputBody:   JSONBody{Value: []string{"a"}},
+
+			expectedContentType: "application/json",
+			expectedRawQuery:    "",
+		},
+		{
+			name: "multipart",
+
+			inputMethod: http.MethodPost,
+			inputBody:   MultipartBody{Boundary: "b"},
+
+			expectedContentType: "multipart/form-data; boundary=b",
+			expectedRawQuery:    "",
+		},
+		{
+			name: "form on GET goes to query",
+
+			inputMethod: http.MethodGet,
+			inputBody:   FormBody{"type": {"target"}},
+
+			expectedContentType: "",
+			expectedRawQuery:    "type=target",
+		},
+		{
+			name: "no body",
+
+			inputMethod: http.MethodPost,
+			inputBody:   nil,
+
+			expectedContentType: "",
+			expectedRawQuery:    "",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if got := req.Header.Get("Content-Type"); got != tc.expectedContentType {
+					t.Fatalf("content type wrong. want=%s, got=%s", tc.expectedContentType, got)
+				}
+				if req.URL.RawQuery != tc.expectedRawQuery {
+					t.Fatalf("request query wrong. want=%s, got=%s", tc.expectedRawQuery, req.URL.RawQuery)
+				}
+				if got := req.Header.Get("Authorization"); got != "DeepL-Auth-Key "+testAPIKey {
+					t.Fatalf("request Authorization wrong. want=DeepL-Auth-Key %s, got=%s", testAPIKey, got)
+				}
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			serverURL, _ := url.Parse(server.URL)
+			cli := &Client{BaseURL: serverURL, HTTPClient: server.Client(), APIKey: testAPIKey}
+
+			var out struct{}
+			if err := cli.Do(context.Background(), tc.inputMethod, "/v2/made-up", tc.inputBody, &out); err != nil {
+				t.Fatalf("response error should be nil. got=%s", err.Error())
+			}
+		})
+	}
+}
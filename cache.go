@@ -0,0 +1,28 @@
+package deepl
+
+// TranslationCache stores the translations of whole requests, keyed by
+// CanonicalRequestHash. Get must return translations for the same number of
+// texts that were stored under key. It must be safe for concurrent use.
+// With a TranslationMemory, only the texts the memory doesn't know are
+// cached, so that later memory entries win over cached translations.
+type TranslationCache interface {
+	Get(key string) ([]Translation, bool)
+	Set(key string, translations []Translation)
+}
+
+// WithTranslationCache sets Client.TranslationCache.
+func WithTranslationCache(cache TranslationCache) Option {
+	return func(c *Client) error {
+		c.TranslationCache = cache
+		return nil
+	}
+}
+
+// cachedTranslations returns a copy of the n translations cached for key.
+func (c *Client) cachedTranslations(key string, n int) ([]Translation, bool) {
+	translations, ok := c.TranslationCache.Get(key)
+	if !ok || len(translations) != n {
+		return nil, false
+	}
+	return append([]Translation(nil), translations...), true
+}
@@ -0,0 +1,137 @@
+package deepl
+
+import (
+	"sync"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+type mapCache struct {
+	mu sync.Mutex
+	m  map[string][]Translation
+}
+
+func (c *mapCache) Get(key string) ([]Translation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, ok := c.m[key]
+	return t, ok
+}
+
+func (c *mapCache) Set(key string, translations []Translation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m[key] = translations
+}
+
+func TestClient_TranslationCache(t *testing.T) {
+	cli, received, teardown := initTranslateServer(t, func(req TranslateRequest) []Translation {
+		translations := prefixTranslations(req)
+		for i := range translations {
+			translations[i].ModelTypeUsed = "latency_optimized"
+		}
+		return translations
+	})
+	defer teardown()
+	cache := &mapCache{m: make(map[string][]Translation)}
+	if err := WithTranslationCache(cache)(cli); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, req := range []TranslateRequest{
+		{Text: []string{"Hello", "World"}, TargetLang: "DE"},
+		{Text: []string{"Hello", "World"}, TargetLang: " de"},
+		{Text: []string{"Hello"}, TargetLang: "DE"},
+	} {
+		res, err := cli.Translate(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// a cache hit reports the model that produced the translation
+		if res.Translations[0].Text != "DE:Hello" || res.Translations[0].ModelTypeUsed != "latency_optimized" || res.Metadata.Cached != (i == 1) {
+			t.Fatalf("request %d: unexpected result %+v", i, res)
+		}
+		res.Translations[0].Text = "modified"
+	}
+	if len(*received) != 2 || len(cache.m) != 2 {
+		t.Fatalf("expected 2 requests and cache entries, got %d and %d", len(*received), len(cache.m))
+	}
+}
+
+func TestClient_TranslationCacheWithMemory(t *testing.T) {
+	cli, received, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	tm := NewMapTranslationMemory()
+	cache := &mapCache{m: make(map[string][]Translation)}
+	if err := WithTranslationMemory(tm)(cli); err != nil {
+		t.Fatal(err)
+	}
+	if err := WithTranslationCache(cache)(cli); err != nil {
+		t.Fatal(err)
+	}
+	req := TranslateRequest{Text: []string{"Hello", "World"}, SourceLang: "EN", TargetLang: "DE"}
+
+	tt := []struct {
+		name string
+
+		addToMemory bool
+
+		expectedTexts    []string
+		expectedSources  []string
+		expectedCached   bool
+		expectedRequests int
+	}{
+		{
+			name: "translated",
+
+			expectedTexts:    []string{"DE:Hello", "DE:World"},
+			expectedSources:  []string{SourceAPI, SourceAPI},
+			expectedRequests: 1,
+		},
+		{
+			name: "memory entry added after caching wins",
+
+			addToMemory: true,
+
+			expectedTexts:    []string{"Hallo", "DE:World"},
+			expectedSources:  []string{SourceTM, SourceAPI},
+			expectedRequests: 2,
+		},
+		{
+			name: "texts missed by the memory are cached",
+
+			expectedTexts:    []string{"Hallo", "DE:World"},
+			expectedSources:  []string{SourceTM, SourceAPI},
+			expectedCached:   true,
+			expectedRequests: 2,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.addToMemory {
+				tm.Add("Hello", "EN", "DE", "Hallo")
+			}
+			res, err := cli.Translate(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, translation := range res.Translations {
+				if translation.Text != tc.expectedTexts[i] || translation.Source != tc.expectedSources[i] {
+					t.Fatalf("translation %d wrong. want=%s (%q), got=%s (%q)", i, tc.expectedTexts[i], tc.expectedSources[i], translation.Text, translation.Source)
+				}
+			}
+			if res.Metadata.Cached != tc.expectedCached || len(*received) != tc.expectedRequests {
+				t.Fatalf("got cached=%v after %d requests, want cached=%v after %d", res.Metadata.Cached, len(*received), tc.expectedCached, tc.expectedRequests)
+			}
+			for _, entry := range cache.m {
+				for _, translation := range entry {
+					if translation.Source == SourceTM {
+						t.Fatalf("memory answer cached: %+v", entry)
+					}
+				}
+			}
+		})
+	}
+}
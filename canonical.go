@@ -0,0 +1,33 @@
+package deepl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// canonical returns a copy of r with language codes upper-cased and enum
+// values lower-cased, so that logically identical requests encode to the
+// same bytes. Form bodies are additionally sorted by parameter name by
+// url.Values.Encode, which keeps the order of repeated text values.
+func (r TranslateRequest) canonical() TranslateRequest {
+	r.SourceLang = strings.ToUpper(strings.TrimSpace(r.SourceLang))
+	r.TargetLang = strings.ToUpper(strings.TrimSpace(r.TargetLang))
+	r.SplitSentences = normalizeEnum(r.SplitSentences)
+	r.Formality = normalizeEnum(r.Formality)
+	r.TagHandling = normalizeEnum(r.TagHandling)
+	r.ModelType = normalizeEnum(r.ModelType)
+	return r
+}
+
+// CanonicalRequestHash returns a hex SHA-256 of the canonical encoding of
+// req. Caching proxies and clients can use it as a shared cache key.
+func CanonicalRequestHash(req TranslateRequest) string {
+	canonical := req.canonical()
+	// TranslateRequest only holds strings, bools and string slices, so
+	// marshaling cannot fail
+	b, _ := json.Marshal(&canonical)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,94 @@
+package deepl
+
+import (
+	"testing"
+)
+
+func TestTranslateRequest_Canonical(t *testing.T) {
+	tt := []struct {
+		name string
+
+		inputRequests []TranslateRequest
+	}{
+		{
+			name: "language case",
+
+			inputRequests: []TranslateRequest{
+				{Text: []string{"hello"}, SourceLang: "en", TargetLang: "en-gb"},
+				{Text: []string{"hello"}, SourceLang: "EN", TargetLang: "EN-GB"},
+				{Text: []string{"hello"}, SourceLang: " En", TargetLang: "En-Gb "},
+			},
+		},
+		{
+			name: "enum case",
+
+			inputRequests: []TranslateRequest{
+				{Text: []string{"hello"}, TargetLang: "DE", Formality: "More", TagHandling: "HTML", SplitSentences: "NoNewlines"},
+				{Text: []string{"hello"}, TargetLang: "DE", Formality: FormalityMore, TagHandling: TagHandlingHTML, SplitSentences: SplitSentencesNoNewlines},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, encoding := range []RequestEncoding{RequestEncodingJSON, RequestEncodingForm} {
+				cli := &Client{RequestEncoding: encoding}
+
+				_, want, err := cli.translateBody(&tc.inputRequests[0], false).Encode()
+				if err != nil {
+					t.Fatalf("encode error should be nil. got=%s", err.Error())
+				}
+				wantHash := CanonicalRequestHash(tc.inputRequests[0])
+
+				for _, req := range tc.inputRequests[1:] {
+					_, got, err := cli.translateBody(&req, false).Encode()
+					if err != nil {
+						t.Fatalf("encode error should be nil. got=%s", err.Error())
+					}
+					if string(got) != string(want) {
+						t.Fatalf("body not canonical. want=%s, got=%s", want, got)
+					}
+					if gotHash := CanonicalRequestHash(req); gotHash != wantHash {
+						t.Fatalf("hash not canonical. want=%s, got=%s", wantHash, gotHash)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCanonicalRequestHash_Distinct(t *testing.T) {
+	tt := []struct {
+		name string
+
+		inputA TranslateRequest
+		inputB TranslateRequest
+	}{
+		{
+			name: "text order",
+
+			inputA: TranslateRequest{Text: []string{"a", "b"}, TargetLang: "DE"},
+			inputB: TranslateRequest{Text: []string{"b", "a"}, TargetLang: "DE"},
+		},
+		{
+			name: "target language",
+
+			inputA: TranslateRequest{Text: []string{"a"}, TargetLang: "DE"},
+			inputB: TranslateRequest{Text: []string{"a"}, TargetLang: "FR"},
+		},
+		{
+			name: "boolean option",
+
+			inputA: TranslateRequest{Text: []string{"a"}, TargetLang: "DE"},
+			inputB: TranslateRequest{Text: []string{"a"}, TargetLang: "DE", PreserveFormatting: true},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if CanonicalRequestHash(tc.inputA) == CanonicalRequestHash(tc.inputB) {
+				t.Fatalf("hash should differ for %+v and %+v", tc.inputA, tc.inputB)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package deepl
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+// DeepL's documented request limits.
+const (
+	maxTextsPerRequest = 50
+	maxRequestBytes    = 128 << 10
+)
+
+// WithMaxTextsPerRequest lowers the number of texts the batch helpers send
+// in one request. n must be between 1 and 50.
+func WithMaxTextsPerRequest(n int) Option {
+	return func(c *Client) error {
+		if n < 1 || n > maxTextsPerRequest {
+			return xerrors.Errorf("Failed to set texts per request: %d is not between 1 and %d", n, maxTextsPerRequest)
+		}
+		c.MaxTextsPerRequest = n
+		return nil
+	}
+}
+
+// WithMaxRequestBytes lowers the total text size the batch helpers send in
+// one request. n must be between 1 and 128 KiB.
+func WithMaxRequestBytes(n int) Option {
+	return func(c *Client) error {
+		if n < 1 || n > maxRequestBytes {
+			return xerrors.Errorf("Failed to set request size: %d is not between 1 and %d bytes", n, maxRequestBytes)
+		}
+		c.MaxRequestBytes = n
+		return nil
+	}
+}
+
+// ErrOversizedText is returned by PlanChunks for a text larger than
+// MaxBytes when RejectOversized is set.
+var ErrOversizedText = xerrors.New("Text exceeds the request size limit")
+
+// ErrCharacterBudgetExceeded is returned by PlanChunks when the texts have
+// more characters than CharacterBudget.
+var ErrCharacterBudgetExceeded = xerrors.New("Character budget exceeded")
+
+// ChunkLimits bounds the requests planned by PlanChunks.
+type ChunkLimits struct {
+	// MaxTexts and MaxBytes limit the texts and the total text size of one
+	// request. Zero means DeepL's limits of 50 texts and 128 KiB.
+	MaxTexts int
+	MaxBytes int
+	// CharacterBudget is the number of characters left to translate. Zero
+	// means unlimited.
+	CharacterBudget int
+	// RejectOversized makes a text larger than MaxBytes an error. Otherwise
+	// it is sent alone. Splitting such a text is not supported: a plan
+	// holds indexes of whole texts, so callers that need it must split the
+	// text before planning.
+	RejectOversized bool
+}
+
+// PlanChunks groups the indexes of texts into requests within limits,
+// keeping the order of texts. The same input always gives the same plan.
+// When the texts exceed CharacterBudget, the groups that fit are returned
+// along with an error matching ErrCharacterBudgetExceeded.
+func PlanChunks(texts []string, limits ChunkLimits) ([][]int, error) {
+	maxTexts, maxBytes := limits.MaxTexts, limits.MaxBytes
+	if maxTexts < 0 || maxTexts > maxTextsPerRequest {
+		return nil, xerrors.Errorf("Failed to plan chunks: %d texts per request is not between 1 and %d", maxTexts, maxTextsPerRequest)
+	}
+	if maxBytes < 0 || maxBytes > maxRequestBytes {
+		return nil, xerrors.Errorf("Failed to plan chunks: %d bytes per request is not between 1 and %d", maxBytes, maxRequestBytes)
+	}
+	if limits.CharacterBudget < 0 {
+		return nil, xerrors.Errorf("Failed to plan chunks: negative character budget %d", limits.CharacterBudget)
+	}
+	if maxTexts == 0 {
+		maxTexts = maxTextsPerRequest
+	}
+	if maxBytes == 0 {
+		maxBytes = maxRequestBytes
+	}
+
+	var groups [][]int
+	var group []int
+	size, characters := 0, 0
+	for i, text := range texts {
+		if limits.RejectOversized && len(text) > maxBytes {
+			return nil, xerrors.Errorf("Failed to plan chunks: text %d has %d bytes: %w", i, len(text), ErrOversizedText)
+		}
+		characters += utf8.RuneCountInString(text)
+		if limits.CharacterBudget > 0 && characters > limits.CharacterBudget {
+			if len(group) > 0 {
+				groups = append(groups, group)
+			}
+			return groups, xerrors.Errorf("Failed to plan chunks: text %d exceeds %d characters: %w", i, limits.CharacterBudget, ErrCharacterBudgetExceeded)
+		}
+		if len(group) > 0 && (len(group) >= maxTexts || size+len(text) > maxBytes) {
+			groups = append(groups, group)
+			group, size = nil, 0
+		}
+		group = append(group, i)
+		size += len(text)
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// chunk is the range texts[start:end] sent in one request.
+type chunk struct {
+	start, end int
+}
+
+// chunks splits texts into request-sized ranges according to
+// MaxTextsPerRequest and MaxRequestBytes. A text larger than the byte limit
+// is sent on its own.
+func (c *Client) chunks(texts []string) []chunk {
+	limits := ChunkLimits{MaxTexts: c.MaxTextsPerRequest, MaxBytes: c.MaxRequestBytes}
+	if limits.MaxTexts < 0 || limits.MaxTexts > maxTextsPerRequest {
+		limits.MaxTexts = 0
+	}
+	if limits.MaxBytes < 0 || limits.MaxBytes > maxRequestBytes {
+		limits.MaxBytes = 0
+	}
+	// without a budget or RejectOversized planning can't fail
+	groups, _ := PlanChunks(texts, limits)
+
+	var chunks []chunk
+	for _, group := range groups {
+		chunks = append(chunks, chunk{start: group[0], end: group[len(group)-1] + 1})
+	}
+	return chunks
+}
@@ -0,0 +1,143 @@
+package deepl
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+func TestClient_chunks(t *testing.T) {
+	tt := []struct {
+		name string
+
+		maxTexts int
+		maxBytes int
+		texts    []string
+
+		expected []chunk
+	}{
+		{
+			name: "defaults",
+
+			texts: make([]string, 120),
+
+			expected: []chunk{{0, 50}, {50, 100}, {100, 120}},
+		},
+		{
+			name: "fewer texts per request",
+
+			maxTexts: 2,
+			texts:    []string{"a", "b", "c", "d", "e"},
+
+			expected: []chunk{{0, 2}, {2, 4}, {4, 5}},
+		},
+		{
+			name: "byte limit",
+
+			maxBytes: 10,
+			texts:    []string{"12345", "12345", "1", "1234567890", "123"},
+
+			expected: []chunk{{0, 2}, {2, 3}, {3, 4}, {4, 5}},
+		},
+		{
+			name: "oversized text is sent alone",
+
+			maxBytes: 4,
+			texts:    []string{"12345", "1"},
+
+			expected: []chunk{{0, 1}, {1, 2}},
+		},
+		{
+			name: "no texts",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Client{MaxTextsPerRequest: tc.maxTexts, MaxRequestBytes: tc.maxBytes}
+			if got := c.chunks(tc.texts); !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("got %v, expected %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestPlanChunks(t *testing.T) {
+	tt := []struct {
+		name string
+
+		texts  []string
+		limits ChunkLimits
+
+		expected    [][]int
+		expectedErr error
+	}{
+		{name: "no texts", limits: ChunkLimits{}},
+		{name: "default text limit", texts: make([]string, 51), expected: [][]int{seq(0, 50), {50}}},
+		{name: "text limit", texts: []string{"a", "b", "c"}, limits: ChunkLimits{MaxTexts: 2}, expected: [][]int{{0, 1}, {2}}},
+		{name: "byte limit is inclusive", texts: []string{"12", "34", "5"}, limits: ChunkLimits{MaxBytes: 4}, expected: [][]int{{0, 1}, {2}}},
+		{name: "bytes not characters", texts: []string{"ää", "b"}, limits: ChunkLimits{MaxBytes: 4}, expected: [][]int{{0}, {1}}},
+		{name: "oversized text alone", texts: []string{"a", "12345", "b"}, limits: ChunkLimits{MaxBytes: 4}, expected: [][]int{{0}, {1}, {2}}},
+		{name: "oversized text rejected", texts: []string{"a", "12345"}, limits: ChunkLimits{MaxBytes: 4, RejectOversized: true}, expectedErr: ErrOversizedText},
+		{name: "empty texts count", texts: []string{"", "", ""}, limits: ChunkLimits{MaxTexts: 2}, expected: [][]int{{0, 1}, {2}}},
+		{name: "budget fits exactly", texts: []string{"äb", "c"}, limits: ChunkLimits{CharacterBudget: 3}, expected: [][]int{{0, 1}}},
+		{name: "budget exceeded", texts: []string{"ab", "c", "de"}, limits: ChunkLimits{MaxTexts: 1, CharacterBudget: 4}, expected: [][]int{{0}, {1}}, expectedErr: ErrCharacterBudgetExceeded},
+		{name: "budget exceeded by first text", texts: []string{"abc"}, limits: ChunkLimits{CharacterBudget: 2}, expectedErr: ErrCharacterBudgetExceeded},
+		{name: "too many texts per request", limits: ChunkLimits{MaxTexts: 51}, expectedErr: errAny},
+		{name: "too many bytes per request", limits: ChunkLimits{MaxBytes: 128<<10 + 1}, expectedErr: errAny},
+		{name: "negative budget", limits: ChunkLimits{CharacterBudget: -1}, expectedErr: errAny},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := PlanChunks(tc.texts, tc.limits)
+			switch {
+			case tc.expectedErr == nil && err != nil:
+				t.Fatalf("unexpected error %v", err)
+			case tc.expectedErr == errAny && err == nil, tc.expectedErr != nil && tc.expectedErr != errAny && !xerrors.Is(err, tc.expectedErr):
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("got %v, expected %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+// errAny expects an error without checking its kind.
+var errAny = xerrors.New("any error")
+
+func seq(start, end int) []int {
+	s := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		s = append(s, i)
+	}
+	return s
+}
+
+func TestWithMaxTextsPerRequest(t *testing.T) {
+	for _, opt := range []Option{WithMaxTextsPerRequest(0), WithMaxTextsPerRequest(51), WithMaxRequestBytes(0), WithMaxRequestBytes(128<<10 + 1)} {
+		if _, err := New("https://api.deepl.com", nil, opt); err == nil {
+			t.Error("expected error for a limit outside the API maximum")
+		}
+	}
+
+	cli, received, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	if err := WithMaxTextsPerRequest(2)(cli); err != nil {
+		t.Fatal(err)
+	}
+	res, err := cli.TranslateBilingual(context.Background(), TranslateRequest{Text: []string{"One. Two. Three."}, TargetLang: "DE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Requests != 2 || len(*received) != 2 || len((*received)[0].Text) != 2 {
+		t.Fatalf("unexpected requests %+v", *received)
+	}
+	if got := strings.Join([]string{res.Pairs[0].Target, res.Pairs[2].Target}, "|"); got != "DE:One.|DE:Three." {
+		t.Fatalf("unexpected pairs %q", got)
+	}
+}
@@ -0,0 +1,121 @@
+package deepl
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Clock is the time source of a Client. Retries, rate and character
+// limits, micro-batching, pipeline stages and polling all use it, so tests
+// can replace it with WithClock instead of waiting.
+type Clock interface {
+	Now() time.Time
+	// NewTimer returns a timer that sends on C after d.
+	NewTimer(d time.Duration) Timer
+	// AfterFunc calls f in its own goroutine after d. The timer's C is nil.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer created by a Clock.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// WithClock sets Client.Clock, primarily for tests.
+func WithClock(clock Clock) Option {
+	return func(c *Client) error {
+		if clock == nil {
+			return xerrors.New("Failed to configure clock: clock is nil")
+		}
+		c.Clock = clock
+		return nil
+	}
+}
+
+// clock returns Client.Clock or the system clock.
+func (c *Client) clock() Clock {
+	if c.Clock == nil {
+		return systemClock{}
+	}
+	return c.Clock
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return systemTimer{time.AfterFunc(d, f)}
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// ClockSkew returns how far the server clock was ahead of the local clock
+// according to the Date header of the last response. It is zero until a
+// response with a valid Date header was received.
+func (c *Client) ClockSkew() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.clockSkew))
+}
+
+// serverSkew returns the skew of the Date header of resp against now.
+func serverSkew(resp *http.Response, now time.Time) (time.Duration, bool) {
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, false
+	}
+	return date.Sub(now), true
+}
+
+// recordClock stores the clock skew of resp. Responses without a valid
+// Date header keep the previous value.
+func (c *Client) recordClock(resp *http.Response, now time.Time) {
+	if skew, ok := serverSkew(resp, now); ok {
+		atomic.StoreInt64(&c.clockSkew, int64(skew))
+	}
+}
+
+// retryAfter returns the delay requested by the Retry-After header of resp.
+// HTTP dates are interpreted against the server's Date header so that
+// clients with skewed clocks wait the intended time; without a usable Date
+// the local clock is used.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if skew, ok := serverSkew(resp, now); ok {
+		now = now.Add(skew)
+	}
+	if delay := at.Sub(now); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
@@ -0,0 +1,283 @@
+package deepl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	serverNow := now.Add(time.Hour)
+
+	tt := []struct {
+		name string
+
+		retryAfter string
+		date       string
+
+		expected   time.Duration
+		expectedOK bool
+	}{
+		{name: "missing"},
+		{name: "seconds", retryAfter: "3", expected: 3 * time.Second, expectedOK: true},
+		{name: "negative seconds", retryAfter: "-1"},
+		{name: "garbage", retryAfter: "soon"},
+		{
+			name:       "http date relative to server clock",
+			retryAfter: serverNow.Add(5 * time.Second).Format(http.TimeFormat),
+			date:       serverNow.Format(http.TimeFormat),
+			expected:   5 * time.Second,
+			expectedOK: true,
+		},
+		{
+			name:       "http date without server date uses local clock",
+			retryAfter: now.Add(5 * time.Second).Format(http.TimeFormat),
+			expected:   5 * time.Second,
+			expectedOK: true,
+		},
+		{
+			name:       "unparsable server date uses local clock",
+			retryAfter: now.Add(5 * time.Second).Format(http.TimeFormat),
+			date:       "yesterday",
+			expected:   5 * time.Second,
+			expectedOK: true,
+		},
+		{
+			name:       "http date in the past",
+			retryAfter: now.Add(-time.Minute).Format(http.TimeFormat),
+			expectedOK: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tc.retryAfter != "" {
+				resp.Header.Set("Retry-After", tc.retryAfter)
+			}
+			if tc.date != "" {
+				resp.Header.Set("Date", tc.date)
+			}
+			got, ok := retryAfter(resp, now)
+			if got != tc.expected || ok != tc.expectedOK {
+				t.Fatalf("got %v, %v, expected %v, %v", got, ok, tc.expected, tc.expectedOK)
+			}
+		})
+	}
+}
+
+func TestClient_ClockSkew(t *testing.T) {
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Date"] = []string{date}
+		w.Write([]byte(`{"translations":[{"text":"Hallo"}]}`))
+	}))
+	defer ts.Close()
+
+	cli, err := New(ts.URL, nil, WithAPIKey(testAPIKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, skew := range []time.Duration{res.Metadata.ClockSkew, cli.ClockSkew()} {
+		if skew < 59*time.Minute || skew > time.Hour+time.Second {
+			t.Fatalf("clock skew = %v, expected about 1h", skew)
+		}
+	}
+
+	date = "not a date"
+	res, err = cli.Translate(context.Background(), TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Metadata.ClockSkew != 0 || cli.ClockSkew() < 59*time.Minute {
+		t.Fatalf("invalid Date header changed the skew: %v, %v", res.Metadata.ClockSkew, cli.ClockSkew())
+	}
+}
+
+func TestClient_RetryAfterHeader(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"character_count":0,"character_limit":0}`))
+	}))
+	defer ts.Close()
+
+	cli, err := New(ts.URL, nil, WithAPIKey(testAPIKey), WithRetries(1, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the header, not the backoff, decides the wait
+	cli.RetryBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := cli.GetAccountStatus(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Fatalf("sent %d requests, expected 2", requests)
+	}
+}
+
+// fakeClock is a Clock that only moves on Advance.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock *fakeClock
+	when  time.Time
+	c     chan time.Time
+	f     func()
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2020, 8, 12, 20, 33, 5, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer {
+	return c.add(d, &fakeTimer{c: make(chan time.Time, 1)})
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	return c.add(d, &fakeTimer{f: f})
+}
+
+func (c *fakeClock) add(d time.Duration, t *fakeTimer) *fakeTimer {
+	c.mu.Lock()
+	t.clock = c
+	t.when = c.now.Add(d)
+	c.timers = append(c.timers, t)
+	c.mu.Unlock()
+	if d <= 0 {
+		c.Advance(0)
+	}
+	return t
+}
+
+// Advance moves the clock forward by d and fires the timers that are due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	var due []*fakeTimer
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.when.After(c.now) {
+			pending = append(pending, t)
+		} else {
+			due = append(due, t)
+		}
+	}
+	c.timers = pending
+	now := c.now
+	c.mu.Unlock()
+
+	for _, t := range due {
+		if t.f != nil {
+			go t.f()
+		} else {
+			t.c <- now
+		}
+	}
+}
+
+// Timers returns the number of timers that haven't fired or been stopped.
+func (c *fakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// waitTimers waits until n timers are pending.
+func (c *fakeClock) waitTimers(t *testing.T, n int) {
+	t.Helper()
+	waitFor(t, func() bool { return c.Timers() >= n })
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	for i, pending := range t.clock.timers {
+		if pending == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func TestWithClock(t *testing.T) {
+	if _, err := New("https://api.deepl.com", nil, WithClock(nil)); err == nil {
+		t.Fatal("expected error for a nil clock")
+	}
+}
+
+func TestClient_RetryBackoffClock(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"character_count":0,"character_limit":0}`))
+	}))
+	defer ts.Close()
+
+	clock := newFakeClock()
+	cli, err := New(ts.URL, nil, WithAPIKey(testAPIKey), WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.MaxRetries = 2
+	cli.RetryBackoff = time.Minute
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := cli.GetAccountStatus(context.Background())
+		done <- err
+	}()
+	// the backoff doubles: one minute, then two
+	clock.waitTimers(t, 1)
+	clock.Advance(59 * time.Second)
+	if atomic.LoadInt32(&requests) != 1 {
+		t.Fatal("retried before the backoff")
+	}
+	clock.Advance(time.Second)
+	waitFor(t, func() bool { return atomic.LoadInt32(&requests) == 2 })
+	clock.waitTimers(t, 1)
+	clock.Advance(time.Minute)
+	if atomic.LoadInt32(&requests) != 2 {
+		t.Fatal("second backoff didn't double")
+	}
+	clock.Advance(time.Minute)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,60 @@
+package deepl
+
+import (
+	"encoding/json"
+
+	"golang.org/x/xerrors"
+)
+
+// JSONCodec encodes JSON request bodies and decodes JSON responses, both
+// successful ones and error messages. It must be safe for concurrent use.
+type JSONCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// stdJSON is the encoding/json codec used when Client.JSONCodec is nil.
+type stdJSON struct{}
+
+func (stdJSON) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdJSON) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// defaultJSONCodec is replaced by tests to run the suite with another codec.
+var defaultJSONCodec JSONCodec = stdJSON{}
+
+// WithJSONCodec replaces encoding/json for request and response bodies.
+// CanonicalRequestHash keeps using encoding/json so that hashes don't depend
+// on the codec.
+func WithJSONCodec(codec JSONCodec) Option {
+	return func(c *Client) error {
+		if codec == nil {
+			return xerrors.New("Failed to set JSON codec: codec is nil")
+		}
+		c.JSONCodec = codec
+		return nil
+	}
+}
+
+func (c *Client) codec() JSONCodec {
+	if c.JSONCodec != nil {
+		return c.JSONCodec
+	}
+	return defaultJSONCodec
+}
+
+// encodeBody encodes body, marshaling JSONBody values with the client codec.
+func (c *Client) encodeBody(body RequestBody) (string, []byte, error) {
+	if b, ok := body.(JSONBody); ok {
+		bodyBytes, err := c.codec().Marshal(b.Value)
+		if err != nil {
+			return "", nil, xerrors.Errorf("Failed to encode JSON body: %w", err)
+		}
+		return "application/json", bodyBytes, nil
+	}
+	return body.Encode()
+}
@@ -0,0 +1,100 @@
+package deepl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+const codecEnv = "DEEPL_TEST_JSON_CODEC"
+
+// countingJSON is a second codec built on json.Encoder and json.Decoder
+// that counts its calls.
+type countingJSON struct {
+	marshals   int64
+	unmarshals int64
+}
+
+func (c *countingJSON) Marshal(v interface{}) ([]byte, error) {
+	atomic.AddInt64(&c.marshals, 1)
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func (c *countingJSON) Unmarshal(data []byte, v interface{}) error {
+	atomic.AddInt64(&c.unmarshals, 1)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return xerrors.New("invalid character after top-level value")
+	}
+	return nil
+}
+
+// TestMain runs the suite with countingJSON as the default codec when
+// codecEnv is set, see TestJSONCodecConformance.
+func TestMain(m *testing.M) {
+	if os.Getenv(codecEnv) == "" {
+		os.Exit(m.Run())
+	}
+	codec := &countingJSON{}
+	defaultJSONCodec = codec
+	code := m.Run()
+	if code == 0 && (codec.marshals == 0 || codec.unmarshals == 0) {
+		fmt.Fprintf(os.Stderr, "codec unused: %d marshals, %d unmarshals\n", codec.marshals, codec.unmarshals)
+		code = 1
+	}
+	os.Exit(code)
+}
+
+func TestJSONCodecConformance(t *testing.T) {
+	if os.Getenv(codecEnv) != "" {
+		t.Skip("already running with the second codec")
+	}
+	if testing.Short() {
+		t.Skip("reruns the whole suite")
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^Test")
+	cmd.Env = append(os.Environ(), codecEnv+"=counting")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("suite failed with a second codec: %v\n%s", err, out)
+	}
+}
+
+func TestClient_JSONCodec(t *testing.T) {
+	cli, received, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	codec := &countingJSON{}
+	if err := WithJSONCodec(codec)(cli); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Translations[0].Text != "DE:Hello" || len(*received) != 1 {
+		t.Fatalf("unexpected result %+v", res.Translations)
+	}
+	if codec.marshals != 1 || codec.unmarshals != 1 {
+		t.Fatalf("codec calls: %d marshals, %d unmarshals", codec.marshals, codec.unmarshals)
+	}
+
+	if err := WithJSONCodec(nil)(cli); err == nil {
+		t.Fatal("nil codec should be rejected")
+	}
+}
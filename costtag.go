@@ -0,0 +1,100 @@
+package deepl
+
+import (
+	"context"
+	"sync"
+	"unicode/utf8"
+)
+
+type costTagsKey struct{}
+
+// WithCostTag returns a context whose calls are attributed to tag, such as
+// "feature=checkout", in CallInfo, AuditRecord and UsageTracker. Tags add
+// up: a context derived from a tagged one carries both tags. Tags are never
+// sent to DeepL.
+func WithCostTag(ctx context.Context, tag string) context.Context {
+	tags := CostTags(ctx)
+	for _, t := range tags {
+		if t == tag {
+			return ctx
+		}
+	}
+	return context.WithValue(ctx, costTagsKey{}, append(tags[:len(tags):len(tags)], tag))
+}
+
+// CostTags returns the cost tags of ctx in the order they were added.
+func CostTags(ctx context.Context) []string {
+	tags, _ := ctx.Value(costTagsKey{}).([]string)
+	return tags
+}
+
+// UsageTracker counts the characters translated by DeepL per cost tag. It is
+// safe for concurrent use.
+type UsageTracker struct {
+	mu    sync.Mutex
+	usage Usage
+}
+
+// Usage is a snapshot of a UsageTracker. Characters of calls with several
+// tags count towards each of them; Untagged counts calls without a tag.
+type Usage struct {
+	Characters int
+	Untagged   int
+	ByTag      map[string]int
+}
+
+// NewUsageTracker returns an empty tracker.
+func NewUsageTracker() *UsageTracker {
+	return &UsageTracker{usage: Usage{ByTag: make(map[string]int)}}
+}
+
+// WithUsageTracker sets Client.UsageTracker.
+func WithUsageTracker(t *UsageTracker) Option {
+	return func(c *Client) error {
+		c.UsageTracker = t
+		return nil
+	}
+}
+
+// Add counts characters for tags.
+func (t *UsageTracker) Add(tags []string, characters int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.usage.Characters += characters
+	if len(tags) == 0 {
+		t.usage.Untagged += characters
+	}
+	for _, tag := range tags {
+		t.usage.ByTag[tag] += characters
+	}
+}
+
+// Snapshot returns the counts so far.
+func (t *UsageTracker) Snapshot() Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	usage := t.usage
+	usage.ByTag = make(map[string]int, len(t.usage.ByTag))
+	for tag, n := range t.usage.ByTag {
+		usage.ByTag[tag] = n
+	}
+	return usage
+}
+
+// billedCharacters returns the characters DeepL billed for result, counting
+// the source texts when the response has no billed_characters. Texts
+// answered without DeepL are free.
+func billedCharacters(texts []string, result *TranslateResult) int {
+	n := 0
+	for i, t := range result.Translations {
+		if t.Source != "" && t.Source != SourceAPI {
+			continue
+		}
+		if t.BilledCharacters > 0 {
+			n += t.BilledCharacters
+		} else if i < len(texts) {
+			n += utf8.RuneCountInString(texts[i])
+		}
+	}
+	return n
+}
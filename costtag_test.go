@@ -0,0 +1,69 @@
+package deepl
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestWithCostTag(t *testing.T) {
+	ctx := WithCostTag(context.Background(), "feature=checkout")
+	a := WithCostTag(ctx, "team=payments")
+	b := WithCostTag(ctx, "team=growth")
+	if got := CostTags(a); !reflect.DeepEqual(got, []string{"feature=checkout", "team=payments"}) {
+		t.Fatalf("unexpected tags %v", got)
+	}
+	if got := CostTags(b); !reflect.DeepEqual(got, []string{"feature=checkout", "team=growth"}) {
+		t.Fatalf("unexpected tags %v", got)
+	}
+	if got := CostTags(WithCostTag(a, "feature=checkout")); len(got) != 2 {
+		t.Fatalf("expected duplicate tag to be ignored, got %v", got)
+	}
+	if CostTags(context.Background()) != nil {
+		t.Fatal("expected no tags")
+	}
+}
+
+func TestClient_CostTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		for _, part := range []string{string(body), req.URL.String(), strings.Join(req.Header.Values("User-Agent"), "")} {
+			if strings.Contains(part, "checkout") {
+				t.Errorf("cost tag sent to DeepL: %s", part)
+			}
+		}
+		w.Write([]byte(`{"translations":[{"detected_source_language":"EN","text":"Hallo","billed_characters":7},{"detected_source_language":"EN","text":"Welt"}]}`))
+	}))
+	defer server.Close()
+
+	tracker := NewUsageTracker()
+	o := &recordingCallObserver{}
+	cli, err := New(server.URL, nil, WithAPIKey("test"), WithUsageTracker(tracker), WithAuditRecord(), WithCallObserver(o))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := TranslateRequest{Text: []string{"Hello", "World"}, TargetLang: "DE"}
+
+	ctx := WithCostTag(WithCostTag(context.Background(), "feature=checkout"), "team=payments")
+	res, err := cli.Translate(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.Translate(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	tags := []string{"feature=checkout", "team=payments"}
+	if !reflect.DeepEqual(res.Audit.CostTags, tags) || !reflect.DeepEqual(o.calls[0].CostTags, tags) || o.calls[1].CostTags != nil {
+		t.Fatalf("unexpected tags %v and %+v", res.Audit.CostTags, o.calls)
+	}
+	expected := Usage{Characters: 24, Untagged: 12, ByTag: map[string]int{"feature=checkout": 12, "team=payments": 12}}
+	if got := tracker.Snapshot(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("got %+v, expected %+v", got, expected)
+	}
+}
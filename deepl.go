@@ -1,49 +1,265 @@
 package deepl
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
-	"io/ioutil"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
-	"path"
+	"reflect"
+	"regexp"
+	"sync"
+	"time"
 
 	"golang.org/x/xerrors"
 )
 
+const (
+	defaultRetryBackoff = 500 * time.Millisecond
+	// maxRetryBackoff caps the growth of the exponential backoff, which
+	// would overflow after a few dozen attempts
+	maxRetryBackoff     = 5 * time.Minute
+	maxPreallocatedBody = 8 << 20
+	maxRawResponse      = 1 << 20
+)
+
 type Client struct {
+	// BaseURL is resolved into endpoint URLs on the first request and must
+	// not be modified afterwards.
 	BaseURL    *url.URL
 	HTTPClient *http.Client
 	Logger     *log.Logger
+
+	// APIKey authenticates requests. When empty, APIKeyProvider is asked or,
+	// without one, DEEPL_API_KEY is read on every request.
+	APIKey         string
+	APIKeyProvider func() (string, error)
+	// LegacyAuthInQuery sends the API key as the auth_key query parameter
+	// instead of the Authorization header, for old mock servers. Keys in
+	// URLs end up in access and proxy logs. It is deprecated, see
+	// DeprecatedAuthInQuery.
+	LegacyAuthInQuery bool
+
+	// MaxRetries is the number of times a request is resent after a
+	// transport error, 429 or 5xx response. RetryBackoff is the initial
+	// wait between attempts and doubles on each retry, up to 5 minutes.
+	MaxRetries   int
+	RetryBackoff time.Duration
+
+	// RequestEncoding selects JSON or form bodies for translate requests.
+	RequestEncoding RequestEncoding
+
+	// FallbackBaseURL, when set, receives one more attempt of a request
+	// that failed against BaseURL with a transport error or 5xx status.
+	FallbackBaseURL *url.URL
+
+	// TranslationMemory is consulted by Translate before calling DeepL and
+	// MissHandler decides what happens to texts it doesn't know.
+	TranslationMemory TranslationMemory
+	MissHandler       MissHandler
+
+	// TranslationCache answers repeated Translate requests without
+	// calling DeepL.
+	TranslationCache TranslationCache
+
+	// CallObserver is notified of every API call.
+	CallObserver CallObserver
+
+	// UsageTracker counts translated characters per cost tag.
+	UsageTracker *UsageTracker
+
+	// Clock is the time source, see WithClock. Nil means the system clock.
+	Clock Clock
+
+	// SilenceDeprecations turns off the warnings logged once per process
+	// for deprecated usages.
+	SilenceDeprecations bool
+
+	// HTMLEntities controls entities in translations requested without
+	// TagHandling.
+	HTMLEntities HTMLEntityHandling
+
+	// NormalizeNewlines sends texts with \n line endings and
+	// RestoreNewlines gives translations the line endings of their source.
+	NormalizeNewlines bool
+	RestoreNewlines   bool
+
+	// ProtectedPatterns match tokens, such as numbers, that are sent as
+	// placeholders and come back verbatim.
+	ProtectedPatterns []*regexp.Regexp
+
+	// PostProcessors rewrite translations before they are returned, see
+	// EscapeForXML.
+	PostProcessors []PostProcessor
+
+	// VariantPreference maps bare target languages to regional variants,
+	// see WithVariantPreference.
+	VariantPreference map[string]string
+
+	// DowngradeFormality softens formality for targets without formality
+	// support, see WithFormalityDowngrade.
+	DowngradeFormality bool
+
+	// FormalityRetry resends requests whose formality DeepL rejects, see
+	// WithFormalityRetry.
+	FormalityRetry bool
+
+	// SoftFail turns retryable translate errors into untranslated results,
+	// see WithSoftFail.
+	SoftFail bool
+
+	// AuditRecords attaches an AuditRecord to translate results.
+	AuditRecords bool
+
+	// JSONCodec encodes and decodes JSON bodies. Nil means encoding/json.
+	JSONCodec JSONCodec
+
+	// PrivateErrors keeps request texts out of error messages, see
+	// WithPrivateErrors.
+	PrivateErrors bool
+
+	// IdempotencyKeys sends an Idempotency-Key header with every call, see
+	// WithIdempotencyKeys.
+	IdempotencyKeys bool
+
+	// ErrorLocalizer supplies the UserMessage of API errors.
+	ErrorLocalizer ErrorLocalizer
+
+	// MaxTextsPerRequest and MaxRequestBytes limit the requests of the
+	// batch helpers. Zero means DeepL's limits of 50 texts and 128 KiB.
+	MaxTextsPerRequest int
+	MaxRequestBytes    int
+
+	// BatchMaxWait and BatchMaxItems configure micro-batching of
+	// TranslateText. Batching is off while either is zero.
+	BatchMaxWait  time.Duration
+	BatchMaxItems int
+	BatchObserver BatchObserver
+
+	// RequestsPerSecond and RateBurst limit the request rate and
+	// MaxConcurrency the number of calls in flight. Zero means unlimited.
+	// WithPlanDefaults sets them from a plan.
+	RequestsPerSecond float64
+	RateBurst         int
+	MaxConcurrency    int
+
+	warmupOnCreate bool
+
+	// characterRate is set by WithCharacterRate
+	characterRate *characterWindow
+	// pseudo answers translate requests, see WithPseudoTranslation
+	pseudo *PseudoTranslator
+	// truncationCheck is set by WithTruncationCheck
+	truncationCheck *truncationChecker
+	// duplicates is set by WithDuplicateDetection
+	duplicates *duplicateDetector
+	// pseudoWithoutKey is set by ProfileCI
+	pseudoWithoutKey bool
+	// shortInputs is set by WithShortInputPolicy
+	shortInputs *ShortInputPolicy
+	// sourcePolicy is set by WithAllowedSourceLanguages
+	sourcePolicy sourceLanguagePolicy
+
+	endpointsOnce sync.Once
+	endpoints     map[string]endpoint
+
+	// transport is the transport of HTTPClient when options configured it
+	transport *http.Transport
+
+	// pairProfiles is replaced, never modified, by WithPairProfile
+	pairProfiles map[pairKey]callSettings
+	// callDefaults and presetDefaults are set by WithCallDefaults and the
+	// presets
+	callDefaults   callSettings
+	presetDefaults callSettings
+
+	// languages caches the target listing for TranslateForAcceptLanguage
+	languagesMu sync.Mutex
+	languages   []Language
+
+	batcherOnce sync.Once
+	batcher     *microBatcher
+
+	// clockSkew is the server clock minus the local clock in nanoseconds
+	clockSkew int64
+
+	// planAuto is set by WithPlanDefaults(PlanAuto) and retriesSet when
+	// WithRetries overrides the detected plan defaults
+	planAuto   bool
+	retriesSet bool
+	noEnv      bool
+
+	limitsOnce     sync.Once
+	limiter        *rateLimiter
+	semaphore      chan struct{}
+	semaphoreWaits waitStats
 }
 
-func New(rawBaseURL string, logger *log.Logger) (*Client, error) {
-	baseURL, err := url.Parse(rawBaseURL)
+// New creates a client of the API at rawBaseURL. A misconfiguration fails
+// with *ConfigErrors listing every problem found.
+func New(rawBaseURL string, logger *log.Logger, opts ...Option) (*Client, error) {
+	var errs ConfigErrors
+	baseURL, err := parseBaseURL(rawBaseURL)
 	if err != nil {
-		err := xerrors.Errorf("Failed to parse URL")
-		return nil, err
+		errs.add(ConfigInvalidBaseURL, err)
 	}
 
 	if logger == nil {
 		logger = log.New(os.Stderr, "[Log]", log.LstdFlags)
 	}
 
-	return &Client{
+	c := &Client{
 		BaseURL:    baseURL,
 		HTTPClient: http.DefaultClient,
 		Logger:     logger,
-	}, nil
-}
+	}
 
-type TranslateResponse struct {
-	Translations []translation `json:"translations"`
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			errs.add(ConfigInvalidOption, err)
+		}
+	}
+	if c.noEnv && !c.pseudoWithoutKey && c.pseudo == nil && !c.hasAPIKey() {
+		errs.add(ConfigMissingAPIKey, xerrors.New("Failed to create client: no API key given and WithNoEnv forbids reading DEEPL_API_KEY"))
+	}
+	if len(errs.Errors) > 0 {
+		return nil, &errs
+	}
+
+	if c.planAuto {
+		apiKey, _ := c.apiKey()
+		d := DetectPlan(apiKey).Defaults()
+		if c.retriesSet {
+			d.MaxRetries, d.RetryBackoff = c.MaxRetries, c.RetryBackoff
+		}
+		d.apply(c)
+	}
+	if c.pseudoWithoutKey && c.pseudo == nil && !c.hasAPIKey() {
+		c.pseudo = NewPseudoTranslator()
+	}
+	if c.warmupOnCreate {
+		go c.backgroundWarmup()
+	}
+	return c, nil
 }
 
-type translation struct {
-	DetectedSourceLanguage string `json:"detected_source_language"`
-	Text                   string `json:"text"`
+// parseBaseURL parses rawURL and rejects URLs that can't reach an API, such
+// as "htps://api.deepl.com" or "api.deepl.com" without a scheme.
+func parseBaseURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to parse URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, xerrors.Errorf("Failed to parse URL: scheme of %q must be http or https", rawURL)
+	}
+	if u.Host == "" {
+		return nil, xerrors.Errorf("Failed to parse URL: missing host in %q", rawURL)
+	}
+	return u, nil
 }
 
 type ErrorResponse struct {
@@ -55,6 +271,25 @@ type AccountStatus struct {
 	CharacterLimit int `json:"character_limit"`
 }
 
+// apiKey returns c.APIKey or, when it is empty, DEEPL_API_KEY.
+func (c *Client) apiKey() (string, error) {
+	if c.APIKey != "" {
+		return c.APIKey, nil
+	}
+	if c.APIKeyProvider != nil {
+		return c.APIKeyProvider()
+	}
+	return c.envAPIKey()
+}
+
+// envAPIKey returns DEEPL_API_KEY unless WithNoEnv forbids reading it.
+func (c *Client) envAPIKey() (string, error) {
+	if c.noEnv {
+		return "", xerrors.New("Not set API key, WithNoEnv forbids reading DEEPL_API_KEY")
+	}
+	return getAPIKey()
+}
+
 func getAPIKey() (string, error) {
 	// need to parepare setting API key in env
 	val, ok := os.LookupEnv("DEEPL_API_KEY")
@@ -66,154 +301,496 @@ func getAPIKey() (string, error) {
 		return "", err
 	}
 
-	apiKey := os.Getenv("DEEPL_API_KEY")
-	return apiKey, nil
+	return val, nil
 }
 
-func decodeBody(bodyBytes []byte, outStruct interface{}) error {
-	if err := json.Unmarshal(bodyBytes, outStruct); err != nil {
+func decodeBody(codec JSONCodec, bodyBytes []byte, outStruct interface{}) error {
+	if err := codec.Unmarshal(bodyBytes, outStruct); err != nil {
 		return err
 	}
 	return nil
 }
 
-func responseParse(resp *http.Response, outStruct interface{}) error {
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		err := xerrors.Errorf("Failed to read response: %w", err)
-		return err
+// rawCapture decodes into out and keeps a copy of the body in raw.
+type rawCapture struct {
+	out interface{}
+	raw *json.RawMessage
+}
+
+func (r *rawCapture) store(body []byte) {
+	if len(body) > maxRawResponse {
+		*r.raw = nil
+		return
+	}
+	*r.raw = append(json.RawMessage(nil), body...)
+}
+
+// responseParse decodes resp into outStruct. A successful response whose
+// body ends before its Content-Length fails with *TruncatedResponseError.
+func responseParse(resp *http.Response, outStruct interface{}, codec JSONCodec) error {
+	if resp.StatusCode != http.StatusOK || resp.Body == nil || resp.ContentLength <= 0 {
+		return parseBody(resp, resp.Body, outStruct, codec)
+	}
+	counter := &countingReader{r: resp.Body}
+	err := parseBody(resp, counter, outStruct, codec)
+	if err != nil && counter.n < resp.ContentLength {
+		return &TruncatedResponseError{ContentLength: resp.ContentLength, Read: counter.n, Err: err}
+	}
+	return err
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func parseBody(resp *http.Response, body io.Reader, outStruct interface{}, codec JSONCodec) error {
+	if _, std := codec.(stdJSON); std && resp.StatusCode == http.StatusOK && body != nil {
+		// raw responses need the whole body anyway
+		if _, raw := outStruct.(*rawCapture); !raw {
+			return decodeStream(body, outStruct)
+		}
+	}
+
+	var bodyBytes []byte
+	if body != nil {
+		// size the buffer up front when the length is known to avoid
+		// repeated growth on large batch responses
+		var buf bytes.Buffer
+		if resp.ContentLength > 0 && resp.ContentLength <= maxPreallocatedBody {
+			buf.Grow(int(resp.ContentLength) + bytes.MinRead)
+		}
+		if _, err := buf.ReadFrom(body); err != nil {
+			err := xerrors.Errorf("Failed to read response: %w", err)
+			return err
+		}
+		bodyBytes = buf.Bytes()
 	}
 
 	// http response failed and received to error message in json
 	var errResp ErrorResponse
 	var errMessage string
 
+	// proxies may answer with non-JSON error pages, so the message is
+	// best-effort and the status code mapping below still applies
 	if resp.StatusCode != http.StatusOK && len(bodyBytes) != 0 {
-		err := decodeBody(bodyBytes, &errResp)
-		if err != nil {
-			return xerrors.Errorf("Failed to decode error response: %w", err)
+		if err := decodeBody(codec, bodyBytes, &errResp); err == nil {
+			errMessage = errResp.ErrMessage
 		}
-		errMessage = errResp.ErrMessage
 	}
 
 	switch resp.StatusCode {
 	case http.StatusOK:
-		err := decodeBody(bodyBytes, &outStruct)
-		if err != nil {
+		trimmed := bytes.TrimSpace(bodyBytes)
+		if len(trimmed) == 0 || string(trimmed) == "null" {
+			return xerrors.New("Failed to parse Json: empty response body")
+		}
+		if capture, ok := outStruct.(*rawCapture); ok {
+			capture.store(bodyBytes)
+			outStruct = capture.out
+		}
+		if outStruct == nil {
+			return nil
+		}
+		if err := checkDestination(outStruct); err != nil {
+			return err
+		}
+		if err := decodeBody(codec, bodyBytes, outStruct); err != nil {
 			return xerrors.Errorf("Failed to parse Json: %w", err)
 		}
-		return nil
-	case http.StatusBadRequest:
-		return xerrors.Errorf("Bad request. Please check error message and your parameters. Error message is %s", errMessage)
-	case http.StatusForbidden:
-		return xerrors.New("Authorization failed. Please supply a valid auth_key parameter.")
-	case http.StatusNotFound:
-		return xerrors.New("The requested resource clould not be found.")
-	case http.StatusRequestEntityTooLarge:
-		return xerrors.New("The request size exceeds the limit.")
-	case http.StatusTooManyRequests:
-		return xerrors.New("Too many requests. Please wait and resend your request.")
-	case 456:
-		return xerrors.New("Quota exceeded. The character limit has been reached.")
-	case http.StatusServiceUnavailable:
-		return xerrors.New("Resource currently unavailable. Try again later.")
+		return validateDestination(outStruct)
 	default:
-		// Response status code 5** is internal error but error code "503" is http.StatusServiceUnavailable
-		if resp.StatusCode >= 500 {
-			return xerrors.New("Internal error")
+		return &APIError{StatusCode: resp.StatusCode, Message: errMessage}
+	}
+}
+
+func checkDestination(outStruct interface{}) error {
+	if v := reflect.ValueOf(outStruct); v.Kind() != reflect.Ptr || v.IsNil() {
+		return xerrors.Errorf("Failed to parse Json: destination must be a non-nil pointer, got %T", outStruct)
+	}
+	return nil
+}
+
+// resetDestination clears what a failed parse decoded into outStruct, so
+// that it can be decoded again. It reports false when translations were
+// passed on already and can't be taken back.
+func resetDestination(outStruct interface{}) bool {
+	switch out := outStruct.(type) {
+	case nil:
+		return true
+	case *translationStream:
+		if out.each != nil && len(out.result.Translations) > 0 {
+			return false
 		}
-		return xerrors.New("Unexpected error")
+		*out.result = TranslateResult{}
+		return true
+	case *rawCapture:
+		*out.raw = nil
+		return resetDestination(out.out)
 	}
+	if v := reflect.ValueOf(outStruct); v.Kind() == reflect.Ptr && !v.IsNil() {
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	}
+	return true
 }
 
-func (c *Client) GetAccountStatus(ctx context.Context) (*AccountStatus, error) {
-	var accountStatusResp AccountStatus
+func validateDestination(outStruct interface{}) error {
+	normalizeDestination(outStruct)
+	if v, ok := outStruct.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return unexpectedResponse(err)
+		}
+	}
+	return nil
+}
 
-	reqURL := *c.BaseURL
+// normalizeDestination replaces decoded nil translations with an empty
+// slice.
+func normalizeDestination(outStruct interface{}) {
+	switch out := outStruct.(type) {
+	case *TranslateResponse:
+		if out.Translations == nil {
+			out.Translations = []Translation{}
+		}
+	case *TranslateResult:
+		if out.Translations == nil {
+			out.Translations = []Translation{}
+		}
+	case *translationStream:
+		normalizeDestination(out.result)
+	case *rawCapture:
+		normalizeDestination(out.out)
+	}
+}
 
-	// Set path
-	reqURL.Path = path.Join(reqURL.Path, "v2", "usage")
+// Do sends a request to apiPath relative to BaseURL and decodes a successful
+// JSON response into out, a pointer, which is then validated if it has a
+// Validate() error method. out may be nil to discard the response. body may
+// be nil; otherwise it declares the request
+// encoding (FormBody, JSONBody or MultipartBody). Do applies the same
+// authentication, headers, logging, retries and status-code error mapping as
+// the built-in methods, so it can be used as an escape hatch for endpoints
+// this package doesn't cover.
+func (c *Client) Do(ctx context.Context, method, apiPath string, body RequestBody, out interface{}) error {
+	_, err := c.do(ctx, method, apiPath, body, out)
+	return err
+}
 
-	q := reqURL.Query()
+// apiRequest is an encoded request that can be sent to any base URL.
+type apiRequest struct {
+	method      string
+	apiPath     string
+	query       url.Values
+	contentType string
+	// authorization is sent as the Authorization header when set
+	authorization string
+	body          []byte
+	// idempotencyKey is sent as IdempotencyKeyHeader when set
+	idempotencyKey string
+	// header is added to every attempt
+	header http.Header
+	// attempts counts the requests sent
+	attempts int
+}
 
-	apiKey, err := getAPIKey()
-	if err != nil {
-		return nil, err
+// newAPIRequest encodes a request authenticated with apiKey.
+func (c *Client) newAPIRequest(method, apiPath string, body RequestBody, apiKey string) (*apiRequest, error) {
+	r := &apiRequest{method: method, apiPath: apiPath, query: make(url.Values, 2)}
+	if c.LegacyAuthInQuery {
+		r.query.Add("auth_key", apiKey)
+	} else {
+		r.authorization = "DeepL-Auth-Key " + apiKey
 	}
 
-	q.Add("auth_key", apiKey)
-	reqURL.RawQuery = q.Encode()
+	if form, ok := body.(FormBody); ok && method == http.MethodGet {
+		for key, values := range form {
+			for _, v := range values {
+				r.query.Add(key, v)
+			}
+		}
+	} else if body != nil {
+		var err error
+		r.contentType, r.body, err = c.encodeBody(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
 
-	// make new request
-	req, err := http.NewRequest(http.MethodPost, reqURL.String(), nil)
+// httpRequest returns r as an HTTP request to ep.
+func (r *apiRequest) httpRequest(ctx context.Context, ep endpoint) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, r.method, ep.requestURL(r.query), bytes.NewReader(r.body))
 	if err != nil {
-		err := xerrors.Errorf("Failed to create request: %w", err)
-		return nil, err
+		return nil, xerrors.Errorf("Failed to create request: %w", err)
 	}
 
-	// set header
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
 	req.Header.Set("User-Agent", "Deepl-Go-Client")
+	if r.authorization != "" {
+		req.Header.Set("Authorization", r.authorization)
+	}
+	if r.contentType != "" {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+	if r.idempotencyKey != "" {
+		req.Header.Set(IdempotencyKeyHeader, r.idempotencyKey)
+	}
+	return req, nil
+}
+
+func (c *Client) do(ctx context.Context, method, apiPath string, body RequestBody, out interface{}) (Metadata, error) {
+	ctx, id := withRequestID(ctx)
+	meta, err := c.observeCall(ctx, id, method, apiPath, body, out)
+	meta.RequestID = id
+	if target, ok := reportFrom(ctx); ok && meta.Attempts > 0 {
+		target.report.addCall(meta.Attempts)
+	}
+	return meta, withRequestIDError(err, id)
+}
+
+// observeCall sends a request, notifying the CallObserver if there is one.
+func (c *Client) observeCall(ctx context.Context, id, method, apiPath string, body RequestBody, out interface{}) (Metadata, error) {
+	if c.CallObserver == nil {
+		return c.doCall(ctx, method, apiPath, body, out, nil)
+	}
+	call := CallInfo{Method: method, Path: apiPath, Header: make(http.Header), CostTags: CostTags(ctx), RequestID: id}
+	ctx, end := c.CallObserver.StartCall(ctx, call)
+	start := c.clock().Now()
+	var res CallResult
+	meta, err := c.doCall(ctx, method, apiPath, body, out, &callTrace{header: call.Header, result: &res})
+	res.Duration = c.clock().Now().Sub(start)
+	res.Err = err
+	if err == nil {
+		res.ModelTypeUsed = modelTypeUsed(out)
+	}
+	end(res)
+	return meta, err
+}
+
+// callTrace connects a call to its CallObserver.
+type callTrace struct {
+	header http.Header
+	result *CallResult
+}
 
-	// set context
-	req = req.WithContext(ctx)
+// doCall sends a request. trace, when set, receives the attempts and the
+// status of the last response.
+func (c *Client) doCall(ctx context.Context, method, apiPath string, body RequestBody, out interface{}, trace *callTrace) (Metadata, error) {
+	var meta Metadata
 
-	resp, err := c.HTTPClient.Do(req)
+	apiKey, err := c.apiKey()
 	if err != nil {
-		err := xerrors.Errorf("Failed to send http request: %w", err)
-		return nil, err
+		return meta, err
 	}
-	defer resp.Body.Close()
 
-	if err := responseParse(resp, &accountStatusResp); err != nil {
-		return nil, err
+	if c.LegacyAuthInQuery {
+		c.warnDeprecated(ctx, DeprecatedAuthInQuery, "LegacyAuthInQuery is deprecated, the API key belongs in the Authorization header")
+	}
+	r, err := c.newAPIRequest(method, apiPath, body, apiKey)
+	if err != nil {
+		return meta, err
+	}
+	r.idempotencyKey, err = c.idempotencyKey(ctx)
+	if err != nil {
+		return meta, err
+	}
+	meta.IdempotencyKey = r.idempotencyKey
+	if trace != nil {
+		r.header = trace.header
+		defer func() { trace.result.Attempts = r.attempts }()
+	}
+
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return meta, err
+	}
+	defer release()
+
+	meta.Endpoint = c.BaseURL.String()
+	ep := c.endpoint(apiPath)
+	resp, err := c.send(ctx, r, ep, c.MaxRetries)
+	if c.FallbackBaseURL != nil && shouldFallback(ctx, resp, err) {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		c.logf(ctx, "Falling back to %s for %s %s", c.FallbackBaseURL.Host, method, apiPath)
+		meta.Endpoint = c.FallbackBaseURL.String()
+		meta.FellBack = true
+		ep = resolveEndpoint(c.FallbackBaseURL, apiPath)
+		resp, err = c.send(ctx, r, ep, 0)
+	}
+	for {
+		meta.Attempts = r.attempts
+		if err != nil {
+			return meta, c.localize(err)
+		}
+		if trace != nil {
+			trace.result.StatusCode = resp.StatusCode
+		}
+		now := c.clock().Now()
+		meta.ClockSkew, _ = serverSkew(resp, now)
+
+		err = responseParse(resp, out, c.codec())
+		resp.Body.Close()
+		if err == nil {
+			return meta, nil
+		}
+		// truncated responses count towards MaxRetries like failed sends
+		retries := c.MaxRetries - r.attempts
+		if xerrors.Is(err, ErrTruncatedResponse) && retries >= 0 && ctx.Err() == nil && resetDestination(out) {
+			c.logf(ctx, "Retrying %s %s after truncated response", method, apiPath)
+			if err = c.waitRetry(ctx, c.backoff(r.attempts-1)); err == nil {
+				resp, err = c.send(ctx, r, ep, retries)
+			}
+			continue
+		}
+
+		var apiErr *APIError
+		if xerrors.As(err, &apiErr) {
+			apiErr.RetryAfter, _ = retryAfter(resp, now)
+		}
+		c.logf(ctx, "Request %s %s failed: %v", method, apiPath, err)
+		return meta, c.localize(err)
 	}
-	return &accountStatusResp, nil
 }
 
-func (c *Client) TranslateSentence(ctx context.Context, text string, sourceLang string, targetLang string) (*TranslateResponse, error) {
-	var transResp TranslateResponse
+// send sends r to ep, retrying transport errors and retryable statuses up to
+// maxRetries times. The last response is returned unparsed.
+func (c *Client) send(ctx context.Context, r *apiRequest, ep endpoint, maxRetries int) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.waitRate(ctx); err != nil {
+			return nil, err
+		}
 
-	reqURL := *c.BaseURL
+		req, err := r.httpRequest(ctx, ep)
+		if err != nil {
+			return nil, err
+		}
 
-	// Set path
-	reqURL.Path = path.Join(reqURL.Path, "v2", "translate")
+		r.attempts++
+		resp, err := c.HTTPClient.Do(req)
+		now := c.clock().Now()
+		if err != nil {
+			if dnsErr := dnsError(err); dnsErr != nil {
+				err = &EndpointUnreachableError{Host: req.URL.Hostname(), Err: dnsErr}
+			}
+			if attempt < maxRetries && ctx.Err() == nil && IsRetryable(err) {
+				c.logf(ctx, "Retrying %s %s after error: %v", r.method, ep.path, err)
+				if err := c.waitRetry(ctx, c.backoff(attempt)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if _, ok := err.(*EndpointUnreachableError); ok {
+				return nil, err
+			}
+			err := xerrors.Errorf("Failed to send http request: %w", err)
+			return nil, err
+		}
 
-	q := reqURL.Query()
+		c.recordClock(resp, now)
+
+		if resp.StatusCode != http.StatusOK && attempt < maxRetries && IsRetryable(&APIError{StatusCode: resp.StatusCode}) {
+			resp.Body.Close()
+			c.logf(ctx, "Retrying %s %s after status %d", r.method, ep.path, resp.StatusCode)
+			delay, ok := retryAfter(resp, now)
+			if !ok {
+				delay = c.backoff(attempt)
+			}
+			if err := c.waitRetry(ctx, delay); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		return resp, nil
+	}
+}
 
-	apiKey, err := getAPIKey()
+// shouldFallback reports whether a failed primary request may be repeated
+// against the fallback base URL. 4xx responses would just fail again.
+func shouldFallback(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
 	if err != nil {
-		return nil, err
+		return true
 	}
+	return resp.StatusCode >= 500
+}
 
-	q.Add("auth_key", apiKey)
-	q.Add("text", text)
-	q.Add("target_lang", targetLang)
-	q.Add("source_lang", sourceLang)
-	reqURL.RawQuery = q.Encode()
+// backoff returns the exponential backoff before retry attempt+1.
+func (c *Client) backoff(attempt int) time.Duration {
+	return exponentialBackoff(c.RetryBackoff, attempt)
+}
 
-	// make new request
-	req, err := http.NewRequest(http.MethodPost, reqURL.String(), nil)
-	if err != nil {
-		err := xerrors.Errorf("Failed to create request: %w", err)
-		return nil, err
+// exponentialBackoff doubles base attempt times, up to maxRetryBackoff. A
+// larger base is returned as is.
+func exponentialBackoff(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		base = defaultRetryBackoff
+	}
+	delay := base
+	for i := 0; i < attempt && delay < maxRetryBackoff; i++ {
+		delay <<= 1
+	}
+	if delay > maxRetryBackoff && delay > base {
+		return maxRetryBackoff
 	}
+	return delay
+}
 
-	// set header
-	req.Header.Set("User-Agent", "Deepl-Go-Client")
+// waitRetry sleeps for delay before the next attempt.
+func (c *Client) waitRetry(ctx context.Context, delay time.Duration) error {
+	timer := c.clock().NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return xerrors.Errorf("Failed to send http request: %w", ctx.Err())
+	case <-timer.C():
+		return nil
+	}
+}
 
-	// set context
-	req = req.WithContext(ctx)
+type loggerKey struct{}
 
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		err := xerrors.Errorf("Failed to send http request: %w", err)
-		return nil, err
+// ContextWithLogger returns a copy of ctx carrying logger. Calls made with
+// the returned context, including their retries, log to logger instead of
+// Client.Logger.
+func ContextWithLogger(ctx context.Context, logger *log.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+func (c *Client) logf(ctx context.Context, format string, v ...interface{}) {
+	logger := c.Logger
+	if l, ok := ctx.Value(loggerKey{}).(*log.Logger); ok && l != nil {
+		logger = l
 	}
-	defer resp.Body.Close()
+	if logger == nil {
+		return
+	}
+	if id, ok := RequestIDFromContext(ctx); ok {
+		format = "[" + id + "] " + format
+	}
+	logger.Printf(format, v...)
+}
 
-	if err := responseParse(resp, &transResp); err != nil {
+func (c *Client) GetAccountStatus(ctx context.Context) (*AccountStatus, error) {
+	var accountStatusResp AccountStatus
+
+	// an empty form, like the parameters of TranslateSentence
+	if err := c.Do(ctx, http.MethodPost, "/v2/usage", FormBody{}, &accountStatusResp); err != nil {
 		return nil, err
 	}
-
-	return &transResp, nil
+	return &accountStatusResp, nil
 }
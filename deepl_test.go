@@ -1,22 +1,30 @@
 package deepl
 
 import (
-	"fmt"
+	"bytes"
 	"io/ioutil"
+	"log"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
-	"os"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
 )
 
+// testAPIKey is the key of the test clients, so that no test depends on the
+// ambient DEEPL_API_KEY.
+const testAPIKey = "0123-test-key"
+
 func createTranslateResponse(detectLang string, text string) *TranslateResponse {
 	var r = &TranslateResponse{
-		[]translation{
+		[]Translation{
 			{
 				DetectedSourceLanguage: detectLang,
 				Text:                   text,
@@ -26,7 +34,7 @@ func createTranslateResponse(detectLang string, text string) *TranslateResponse
 	return r
 }
 
-func initTestServer(t *testing.T, mockResponseHeaderFile, mockResponseBodyFile string, expectedMethod, expectedRequestPath, expectedRawQuery string) (*Client, func()) {
+func initTestServer(t *testing.T, mockResponseHeaderFile, mockResponseBodyFile string, expectedMethod, expectedRequestPath, expectedRawQuery, expectedBody string) (*Client, func()) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		if req.Method != expectedMethod {
 			t.Fatalf("request method wrong. want=%s, got=%s", expectedMethod, req.Method)
@@ -37,6 +45,16 @@ func initTestServer(t *testing.T, mockResponseHeaderFile, mockResponseBodyFile s
 		if req.URL.RawQuery != expectedRawQuery {
 			t.Fatalf("request query wrong. want=%s, got=%s", expectedRawQuery, req.URL.RawQuery)
 		}
+		if got := req.Header.Get("Authorization"); got != "DeepL-Auth-Key "+testAPIKey {
+			t.Fatalf("request Authorization wrong. want=DeepL-Auth-Key %s, got=%s", testAPIKey, got)
+		}
+		reqBody, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Fatalf("failed to read request body: %s", err.Error())
+		}
+		if string(reqBody) != expectedBody {
+			t.Fatalf("request body wrong. want=%s, got=%s", expectedBody, reqBody)
+		}
 
 		headerBytes, err := ioutil.ReadFile(mockResponseHeaderFile)
 		if err != nil {
@@ -65,6 +83,7 @@ func initTestServer(t *testing.T, mockResponseHeaderFile, mockResponseBodyFile s
 		BaseURL:    serverURL,
 		HTTPClient: server.Client(),
 		Logger:     nil,
+		APIKey:     testAPIKey,
 	}
 	teardown := func() {
 		server.Close()
@@ -73,6 +92,9 @@ func initTestServer(t *testing.T, mockResponseHeaderFile, mockResponseBodyFile s
 }
 
 func TestClient_TranslateSentence(t *testing.T) {
+	// longer than the URL limits of common proxies once encoded
+	longText := strings.Repeat("Hello, world & friends? 100% sure. ", 300)
+
 	tt := []struct {
 		name string
 
@@ -86,6 +108,7 @@ func TestClient_TranslateSentence(t *testing.T) {
 		expectedMethod      string
 		expectedRequestPath string
 		expectedRawQuery    string
+		expectedBody        string
 		expectedResponse    *TranslateResponse
 		expectedErrMessage  string
 	}{
@@ -101,7 +124,24 @@ func TestClient_TranslateSentence(t *testing.T) {
 
 			expectedMethod:      http.MethodPost,
 			expectedRequestPath: "/v2/translate",
-			expectedRawQuery:    fmt.Sprintf("auth_key=%s&source_lang=EN&target_lang=JA&text=hello", os.Getenv("DEEPL_API_KEY")),
+			expectedRawQuery:    "",
+			expectedBody:        "source_lang=EN&target_lang=JA&text=hello",
+			expectedResponse:    createTranslateResponse("EN", "こんにちわ"),
+		},
+		{
+			name: "long text",
+
+			inputText:       longText,
+			inputSourceLang: "EN",
+			inputTargetLang: "JA",
+
+			mockResponseHeaderFile: "testdata/TranslateText/success-header",
+			mockResponseBodyFile:   "testdata/TranslateText/success-body",
+
+			expectedMethod:      http.MethodPost,
+			expectedRequestPath: "/v2/translate",
+			expectedRawQuery:    "",
+			expectedBody:        url.Values{"source_lang": {"EN"}, "target_lang": {"JA"}, "text": {longText}}.Encode(),
 			expectedResponse:    createTranslateResponse("EN", "こんにちわ"),
 		},
 		{
@@ -116,7 +156,8 @@ func TestClient_TranslateSentence(t *testing.T) {
 
 			expectedMethod:      http.MethodPost,
 			expectedRequestPath: "/v2/translate",
-			expectedRawQuery:    fmt.Sprintf("auth_key=%s&source_lang=EN&target_lang=&text=hello", os.Getenv("DEEPL_API_KEY")),
+			expectedRawQuery:    "",
+			expectedBody:        "source_lang=EN&target_lang=&text=hello",
 			expectedErrMessage:  "Bad request.",
 		},
 		{
@@ -131,7 +172,8 @@ func TestClient_TranslateSentence(t *testing.T) {
 
 			expectedMethod:      http.MethodPost,
 			expectedRequestPath: "/v2/translate",
-			expectedRawQuery:    fmt.Sprintf("auth_key=%s&source_lang=EN&target_lang=AA&text=hello", os.Getenv("DEEPL_API_KEY")),
+			expectedRawQuery:    "",
+			expectedBody:        "source_lang=EN&target_lang=AA&text=hello",
 			expectedErrMessage:  "Bad request.",
 		},
 		{
@@ -146,14 +188,15 @@ func TestClient_TranslateSentence(t *testing.T) {
 
 			expectedMethod:      http.MethodPost,
 			expectedRequestPath: "/v2/translate",
-			expectedRawQuery:    fmt.Sprintf("auth_key=%s&source_lang=EN&target_lang=JA&text=hello", os.Getenv("DEEPL_API_KEY")),
+			expectedRawQuery:    "",
+			expectedBody:        "source_lang=EN&target_lang=JA&text=hello",
 			expectedErrMessage:  "Authorization failed.",
 		},
 	}
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
-			cli, teardown := initTestServer(t, tc.mockResponseHeaderFile, tc.mockResponseBodyFile, tc.expectedMethod, tc.expectedRequestPath, tc.expectedRawQuery)
+			cli, teardown := initTestServer(t, tc.mockResponseHeaderFile, tc.mockResponseBodyFile, tc.expectedMethod, tc.expectedRequestPath, tc.expectedRawQuery, tc.expectedBody)
 			defer teardown()
 
 			correctResponse, err := cli.TranslateSentence(context.Background(), tc.inputText, tc.inputSourceLang, tc.inputTargetLang)
@@ -189,6 +232,7 @@ func TestClient_GetAccountStatus(t *testing.T) {
 		expectedMethod      string
 		expectedRequestPath string
 		expectedRawQuery    string
+		expectedBody        string
 		expectedResponse    *AccountStatus
 		expectedErrMessage  string
 	}{
@@ -200,14 +244,14 @@ func TestClient_GetAccountStatus(t *testing.T) {
 
 			expectedMethod:      http.MethodPost,
 			expectedRequestPath: "/v2/usage",
-			expectedRawQuery:    fmt.Sprintf("auth_key=%s", os.Getenv("DEEPL_API_KEY")),
+			expectedRawQuery:    "",
 			expectedResponse:    &AccountStatus{CharacterCount: 30315, CharacterLimit: 1000000},
 		},
 	}
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T){
-			cli, teardown := initTestServer(t, tc.mockResponseHeaderFile, tc.mockResponseBodyFile, tc.expectedMethod, tc.expectedRequestPath, tc.expectedRawQuery)
+			cli, teardown := initTestServer(t, tc.mockResponseHeaderFile, tc.mockResponseBodyFile, tc.expectedMethod, tc.expectedRequestPath, tc.expectedRawQuery, tc.expectedBody)
 			defer teardown()
 
 			correctResponse, err := cli.GetAccountStatus(context.Background())
@@ -225,4 +269,717 @@ func TestClient_GetAccountStatus(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestClient_FormRequests(t *testing.T) {
+	longText := strings.Repeat("Grüße aus Köln = 5€ + ", 500)
+	tt := []struct {
+		name string
+
+		call func(cli *Client) error
+
+		expectedRequestPath string
+		expectedForm        url.Values
+	}{
+		{
+			name: "TranslateSentence",
+
+			call: func(cli *Client) error {
+				_, err := cli.TranslateSentence(context.Background(), longText, "DE", "EN")
+				return err
+			},
+
+			expectedRequestPath: "/v2/translate",
+			expectedForm:        url.Values{"source_lang": {"DE"}, "target_lang": {"EN"}, "text": {longText}},
+		},
+		{
+			name: "GetAccountStatus",
+
+			call: func(cli *Client) error {
+				_, err := cli.GetAccountStatus(context.Background())
+				return err
+			},
+
+			expectedRequestPath: "/v2/usage",
+			expectedForm:        url.Values{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.Method != http.MethodPost || req.URL.Path != tc.expectedRequestPath {
+					t.Fatalf("request wrong. want=POST %s, got=%s %s", tc.expectedRequestPath, req.Method, req.URL.Path)
+				}
+				if req.URL.RawQuery != "" {
+					t.Fatalf("request query should be empty. got=%s", req.URL.RawQuery)
+				}
+				if got := req.Header.Get("Content-Type"); got != "application/x-www-form-urlencoded" {
+					t.Fatalf("content type wrong. want=application/x-www-form-urlencoded, got=%s", got)
+				}
+				if err := req.ParseForm(); err != nil {
+					t.Fatalf("failed to parse request body: %s", err.Error())
+				}
+				if !reflect.DeepEqual(req.PostForm, tc.expectedForm) {
+					t.Fatalf("request form wrong. want=%v, got=%v", tc.expectedForm, req.PostForm)
+				}
+				if tc.expectedRequestPath == "/v2/translate" {
+					w.Write([]byte(`{"translations":[{"detected_source_language":"DE","text":"Greetings"}]}`))
+					return
+				}
+				w.Write([]byte(`{"character_count":1,"character_limit":2}`))
+			}))
+			defer server.Close()
+
+			cli, err := New(server.URL, nil, WithAPIKey(testAPIKey))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tc.call(cli); err != nil {
+				t.Fatalf("response error should be nil. got=%s", err.Error())
+			}
+		})
+	}
+}
+
+func TestClient_Do(t *testing.T) {
+	type madeUpResponse struct {
+		Name string `json:"name"`
+		Size int    `json:"size"`
+	}
+
+	tt := []struct {
+		name string
+
+		inputBody RequestBody
+
+		mockResponseHeaderFile string
+		mockResponseBodyFile   string
+
+		expectedMethod      string
+		expectedRequestPath string
+		expectedRawQuery    string
+		expectedBody        string
+		expectedResponse    *madeUpResponse
+		expectedErrMessage  string
+	}{
+		{
+			name: "success",
+
+			inputBody: FormBody{"foo": {"bar"}},
+
+			mockResponseHeaderFile: "testdata/Do/success-header",
+			mockResponseBodyFile:   "testdata/Do/success-body",
+
+			expectedMethod:      http.MethodPost,
+			expectedRequestPath: "/v2/made-up",
+			expectedRawQuery:    "",
+			expectedBody:        "foo=bar",
+			expectedResponse:    &madeUpResponse{Name: "made-up", Size: 3},
+		},
+		{
+			name: "not found",
+
+			mockResponseHeaderFile: "testdata/Do/not-found-header",
+			mockResponseBodyFile:   "testdata/Do/not-found-body",
+
+			expectedMethod:      http.MethodPost,
+			expectedRequestPath: "/v2/made-up",
+			expectedRawQuery:    "",
+			expectedErrMessage:  "The requested resource",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, teardown := initTestServer(t, tc.mockResponseHeaderFile, tc.mockResponseBodyFile, tc.expectedMethod, tc.expectedRequestPath, tc.expectedRawQuery, tc.expectedBody)
+			defer teardown()
+
+			var out madeUpResponse
+			err := cli.Do(context.Background(), http.MethodPost, "/v2/made-up", tc.inputBody, &out)
+			if tc.expectedErrMessage == "" {
+				if err != nil {
+					t.Fatalf("response error should be nil. got=%s", err.Error())
+				}
+				if out != *tc.expectedResponse {
+					t.Fatalf("response items wrong. want=%+v, got=%+v", tc.expectedResponse, out)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("response error should not be non-nil. got=nil")
+				}
+				if !strings.Contains(err.Error(), tc.expectedErrMessage) {
+					t.Fatalf("reponse error message wrong. '%s' is expected to contain '%s'", err.Error(), tc.expectedErrMessage)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_APIKeyEncoding(t *testing.T) {
+	key := "a&b=c d+e/f%?#:fx"
+	tt := []struct {
+		name string
+
+		legacyAuthInQuery bool
+
+		expectedAuthorization string
+		expectedAuthKey       string
+	}{
+		{
+			name: "authorization header",
+
+			expectedAuthorization: "DeepL-Auth-Key " + key,
+		},
+		{
+			name: "legacy query parameter",
+
+			legacyAuthInQuery: true,
+
+			expectedAuthKey: key,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var paths []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				paths = append(paths, req.URL.Path)
+				expected := url.Values{}
+				if tc.expectedAuthKey != "" {
+					expected.Set("auth_key", tc.expectedAuthKey)
+				}
+				if req.Method == http.MethodGet {
+					expected.Set("type", "target")
+				}
+				if req.URL.RawQuery != expected.Encode() {
+					t.Errorf("request query wrong. want=%s, got=%s", expected.Encode(), req.URL.RawQuery)
+				}
+				if got := req.URL.Query().Get("auth_key"); got != tc.expectedAuthKey {
+					t.Errorf("auth_key wrong. want=%s, got=%s", tc.expectedAuthKey, got)
+				}
+				if got := req.Header.Get("Authorization"); got != tc.expectedAuthorization {
+					t.Errorf("Authorization wrong. want=%s, got=%s", tc.expectedAuthorization, got)
+				}
+				switch req.URL.Path {
+				case "/v2/languages":
+					w.Write([]byte(`[]`))
+				case "/v2/translate":
+					w.Write([]byte(`{"translations":[{"detected_source_language":"EN","text":"Hallo"}]}`))
+				default:
+					w.Write([]byte(`{"character_count":1,"character_limit":2}`))
+				}
+			}))
+			defer server.Close()
+
+			cli, err := New(server.URL, nil, WithAPIKey(key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			cli.LegacyAuthInQuery = tc.legacyAuthInQuery
+			if _, err := cli.TranslateSentence(context.Background(), "Hello", "EN", "DE"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := cli.GetAccountStatus(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := cli.GetLanguages(context.Background(), LanguageTypeTarget); err != nil {
+				t.Fatal(err)
+			}
+			if expected := []string{"/v2/translate", "/v2/usage", "/v2/languages"}; !reflect.DeepEqual(paths, expected) {
+				t.Fatalf("requests wrong. want=%v, got=%v", expected, paths)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	tt := []struct {
+		name string
+
+		base    time.Duration
+		attempt int
+
+		expected time.Duration
+	}{
+		{name: "first attempt", base: time.Second, attempt: 0, expected: time.Second},
+		{name: "doubled", base: time.Second, attempt: 3, expected: 8 * time.Second},
+		{name: "default base", base: 0, attempt: 1, expected: 2 * defaultRetryBackoff},
+		{name: "capped", base: time.Second, attempt: 9, expected: maxRetryBackoff},
+		{name: "shift would overflow", base: time.Second, attempt: 63, expected: maxRetryBackoff},
+		{name: "huge attempt", base: time.Nanosecond, attempt: math.MaxInt32, expected: maxRetryBackoff},
+		{name: "base above cap", base: time.Hour, attempt: 5, expected: time.Hour},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := exponentialBackoff(tc.base, tc.attempt); got != tc.expected {
+				t.Fatalf("backoff wrong. want=%v, got=%v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestClient_DoRetry(t *testing.T) {
+	tt := []struct {
+		name string
+
+		maxRetries    int
+		failures      int
+		failureStatus int
+
+		expectedRequests   int
+		expectedErrMessage string
+	}{
+		{
+			name: "recovers after 503",
+
+			maxRetries:    2,
+			failures:      2,
+			failureStatus: http.StatusServiceUnavailable,
+
+			expectedRequests: 3,
+		},
+		{
+			name: "gives up after max retries",
+
+			maxRetries:    1,
+			failures:      2,
+			failureStatus: http.StatusTooManyRequests,
+
+			expectedRequests:   2,
+			expectedErrMessage: "Too many requests.",
+		},
+		{
+			name: "does not retry 4xx",
+
+			maxRetries:    2,
+			failures:      1,
+			failureStatus: http.StatusForbidden,
+
+			expectedRequests:   1,
+			expectedErrMessage: "Authorization failed.",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				requests++
+				if requests <= tc.failures {
+					w.WriteHeader(tc.failureStatus)
+					return
+				}
+				w.Write([]byte(`{"character_count":1,"character_limit":2}`))
+			}))
+			defer server.Close()
+
+			serverURL, err := url.Parse(server.URL)
+			if err != nil {
+				t.Fatalf("failed to get mock server URL: %s", err.Error())
+			}
+			cli := &Client{
+				BaseURL:      serverURL,
+				HTTPClient:   server.Client(),
+				MaxRetries:   tc.maxRetries,
+				RetryBackoff: time.Millisecond,
+				APIKey:       testAPIKey,
+			}
+
+			_, err = cli.GetAccountStatus(context.Background())
+			if requests != tc.expectedRequests {
+				t.Fatalf("request count wrong. want=%d, got=%d", tc.expectedRequests, requests)
+			}
+			if tc.expectedErrMessage == "" {
+				if err != nil {
+					t.Fatalf("response error should be nil. got=%s", err.Error())
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("response error should not be non-nil. got=nil")
+				}
+				if !strings.Contains(err.Error(), tc.expectedErrMessage) {
+					t.Fatalf("reponse error message wrong. '%s' is expected to contain '%s'", err.Error(), tc.expectedErrMessage)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_DoRetryTruncated(t *testing.T) {
+	const body = `{"translations":[{"detected_source_language":"EN","text":"eins"},{"detected_source_language":"EN","text":"zwei"}]}`
+	tt := []struct {
+		name string
+
+		maxRetries int
+		truncated  int
+		handler    bool
+
+		expectedRequests  int
+		expectedTruncated bool
+	}{
+		{
+			name: "recovers after truncated response",
+
+			maxRetries: 2,
+			truncated:  2,
+
+			expectedRequests: 3,
+		},
+		{
+			name: "gives up after max retries",
+
+			maxRetries: 1,
+			truncated:  2,
+
+			expectedRequests:  2,
+			expectedTruncated: true,
+		},
+		{
+			name: "does not retry delivered translations",
+
+			maxRetries: 2,
+			truncated:  1,
+			handler:    true,
+
+			expectedRequests:  1,
+			expectedTruncated: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, ok := defaultJSONCodec.(stdJSON); tc.handler && !ok {
+				t.Skip("other codecs decode buffered responses")
+			}
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				requests++
+				if requests <= tc.truncated {
+					// the connection is closed short of the advertised length
+					w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+					w.Write([]byte(body[:strings.Index(body, "},")+2]))
+					return
+				}
+				w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			serverURL, err := url.Parse(server.URL)
+			if err != nil {
+				t.Fatalf("failed to get mock server URL: %s", err.Error())
+			}
+			cli := &Client{
+				BaseURL:      serverURL,
+				HTTPClient:   server.Client(),
+				MaxRetries:   tc.maxRetries,
+				RetryBackoff: time.Millisecond,
+				APIKey:       testAPIKey,
+			}
+
+			var opts []TranslateOption
+			if tc.handler {
+				opts = append(opts, WithTranslationHandler(func(int, Translation) {}))
+			}
+			res, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"one", "two"}, TargetLang: "DE"}, opts...)
+			if requests != tc.expectedRequests {
+				t.Fatalf("request count wrong. want=%d, got=%d", tc.expectedRequests, requests)
+			}
+			if !tc.expectedTruncated {
+				if err != nil {
+					t.Fatalf("response error should be nil. got=%s", err.Error())
+				}
+				if strings.Join(res.Texts(), ",") != "eins,zwei" || res.Metadata.Attempts != tc.expectedRequests {
+					t.Fatalf("unexpected result %+v", res)
+				}
+				return
+			}
+			var truncErr *TruncatedResponseError
+			if !xerrors.Is(err, ErrTruncatedResponse) || !xerrors.As(err, &truncErr) || !IsRetryable(err) {
+				t.Fatalf("expected a retryable truncated response, got %v", err)
+			}
+			if truncErr.ContentLength != int64(len(body)) || truncErr.Read >= truncErr.ContentLength {
+				t.Fatalf("unexpected lengths %+v", truncErr)
+			}
+		})
+	}
+}
+
+func TestClient_ContextLogger(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		if requests%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"character_count":1,"character_limit":2}`))
+	}))
+	defer server.Close()
+
+	var clientLog, requestLog bytes.Buffer
+	cli, err := New(server.URL, log.New(&clientLog, "", 0), WithAPIKey(testAPIKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.MaxRetries = 1
+	cli.RetryBackoff = time.Millisecond
+
+	ctx := ContextWithLogger(context.Background(), log.New(&requestLog, "request-id=42 ", 0))
+	ctx = ContextWithRequestID(ctx, "r1")
+	if _, err := cli.GetAccountStatus(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if clientLog.Len() != 0 || !strings.HasPrefix(requestLog.String(), "request-id=42 [r1] Retrying POST /v2/usage") {
+		t.Fatalf("retry not logged to the context logger. client=%q, request=%q", clientLog.String(), requestLog.String())
+	}
+
+	if _, err := cli.GetAccountStatus(ContextWithRequestID(context.Background(), "r2")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(clientLog.String(), "[r2] Retrying POST /v2/usage") {
+		t.Fatalf("retry not logged to the client logger. client=%q", clientLog.String())
+	}
+}
+
+func FuzzResponseParse(f *testing.F) {
+	statusCodes := []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusRequestEntityTooLarge, http.StatusTooManyRequests, 456, http.StatusInternalServerError, http.StatusServiceUnavailable}
+	bodies := []string{
+		"",
+		"null",
+		`{"translations":null}`,
+		`{"translations":[null]}`,
+		`{"translations":[{"text":1}]}`,
+		`{"translations":[{"detected_source_language":"EN","text":"こんにちわ"}`,
+		`{"message":42}`,
+		`{"character_count":"many"}`,
+		"<html>Bad Gateway</html>",
+		strings.Repeat("[", 20000),
+	}
+	for _, file := range []string{"testdata/TranslateText/success-body", "testdata/TranslateText/missing-target_lang-body", "testdata/GetAccountStatus/success-body"} {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			f.Fatalf("failed to read body '%s': %s", file, err.Error())
+		}
+		bodies = append(bodies, string(b))
+	}
+	for _, statusCode := range statusCodes {
+		for _, body := range bodies {
+			f.Add(statusCode, []byte(body))
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, statusCode int, body []byte) {
+		for _, out := range []interface{}{&TranslateResult{}, &AccountStatus{}, &ErrorResponse{}} {
+			resp := &http.Response{StatusCode: statusCode, Body: ioutil.NopCloser(bytes.NewReader(body))}
+			err := responseParse(resp, out, stdJSON{})
+			if err == nil && statusCode != http.StatusOK {
+				t.Fatalf("response error should not be non-nil for status %d. got=nil", statusCode)
+			}
+		}
+	})
+}
+
+func FuzzDecodeBody(f *testing.F) {
+	f.Add([]byte(`{"translations":[{"detected_source_language":"EN","text":"こんにちわ"}]}`))
+	f.Add([]byte(`{"translations":[{"text":`))
+	f.Add([]byte(`{"message":"Value for 'target_lang' not supported."}`))
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		var result TranslateResult
+		if err := decodeBody(stdJSON{}, body, &result); err != nil {
+			return
+		}
+		for _, v := range result.Translations {
+			_ = v.Text
+		}
+	})
+}
+
+func TestResponseParse_NilBody(t *testing.T) {
+	tt := []struct {
+		name string
+
+		statusCode int
+
+		expectedErrMessage string
+	}{
+		{name: "success without body", statusCode: http.StatusOK, expectedErrMessage: "empty response body"},
+		{name: "error without body", statusCode: http.StatusForbidden, expectedErrMessage: "Authorization failed."},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var result TranslateResult
+			err := responseParse(&http.Response{StatusCode: tc.statusCode}, &result, stdJSON{})
+			if err == nil {
+				t.Fatalf("response error should not be non-nil. got=nil")
+			}
+			if !strings.Contains(err.Error(), tc.expectedErrMessage) {
+				t.Fatalf("reponse error message wrong. '%s' is expected to contain '%s'", err.Error(), tc.expectedErrMessage)
+			}
+		})
+	}
+}
+
+func TestResponseParse_Schema(t *testing.T) {
+	var nilResult *TranslateResult
+	tt := []struct {
+		name string
+
+		body string
+		out  interface{}
+
+		expectedErrMessage string
+		expectedUnexpected bool
+	}{
+		{name: "wrong field type", body: `{"translations":"Hallo"}`, out: &TranslateResult{}, expectedErrMessage: "Failed to parse Json"},
+		{name: "missing translations", body: `{"message":"ok"}`, out: &TranslateResponse{}, expectedErrMessage: "response has no translations", expectedUnexpected: true},
+		{name: "non-pointer destination", body: `{"translations":[]}`, out: TranslateResult{}, expectedErrMessage: "must be a non-nil pointer"},
+		{name: "nil pointer destination", body: `{"translations":[]}`, out: nilResult, expectedErrMessage: "must be a non-nil pointer"},
+		{name: "discarded", body: `{"translations":[]}`, out: nil},
+		{name: "valid", body: `{"translations":[{"text":"Hallo"}]}`, out: &TranslateResponse{}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(tc.body))}
+			err := responseParse(resp, tc.out, stdJSON{})
+			if tc.expectedErrMessage == "" {
+				if err != nil {
+					t.Fatalf("response error should be nil. got=%s", err.Error())
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErrMessage) {
+				t.Fatalf("expected error containing %q, got %v", tc.expectedErrMessage, err)
+			}
+			if got := xerrors.Is(err, ErrUnexpectedResponse); got != tc.expectedUnexpected {
+				t.Fatalf("xerrors.Is(err, ErrUnexpectedResponse) = %v, expected %v", got, tc.expectedUnexpected)
+			}
+		})
+	}
+}
+
+func TestResponseParse_NilTranslations(t *testing.T) {
+	for _, body := range []string{`{"translations":null}`, `{}`} {
+		var result TranslateResult
+		resp := &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}
+		if err := responseParse(resp, &result, stdJSON{}); err != nil {
+			t.Fatal(err)
+		}
+		if result.Translations == nil || len(result.Translations) != 0 {
+			t.Fatalf("%s: expected empty translations, got %#v", body, result.Translations)
+		}
+	}
+}
+
+func TestClient_Fallback(t *testing.T) {
+	tt := []struct {
+		name string
+
+		primaryStatus int
+		primaryDown   bool
+
+		expectedFallbackRequests int
+		expectedFellBack         bool
+		expectedErrMessage       string
+	}{
+		{
+			name: "primary ok",
+
+			primaryStatus: http.StatusOK,
+
+			expectedFallbackRequests: 0,
+			expectedFellBack:         false,
+		},
+		{
+			name: "primary 5xx",
+
+			primaryStatus: http.StatusBadGateway,
+
+			expectedFallbackRequests: 1,
+			expectedFellBack:         true,
+		},
+		{
+			name: "primary down",
+
+			primaryDown: true,
+
+			expectedFallbackRequests: 1,
+			expectedFellBack:         true,
+		},
+		{
+			name: "primary 4xx is not repeated",
+
+			primaryStatus: http.StatusBadRequest,
+
+			expectedFallbackRequests: 0,
+			expectedErrMessage:       "Bad request.",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			successBody, err := ioutil.ReadFile("testdata/TranslateText/success-body")
+			if err != nil {
+				t.Fatalf("failed to read body: %s", err.Error())
+			}
+
+			primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(tc.primaryStatus)
+				if tc.primaryStatus == http.StatusOK {
+					w.Write(successBody)
+				}
+			}))
+			defer primary.Close()
+			if tc.primaryDown {
+				primary.Close()
+			}
+
+			fallbackRequests := 0
+			fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				fallbackRequests++
+				if got := req.Header.Get("Authorization"); got != "DeepL-Auth-Key "+testAPIKey {
+					t.Fatalf("fallback Authorization wrong. want=DeepL-Auth-Key %s, got=%s", testAPIKey, got)
+				}
+				w.Write(successBody)
+			}))
+			defer fallback.Close()
+
+			cli, err := New(primary.URL, nil, WithAPIKey(testAPIKey), WithFallbackBaseURL(fallback.URL))
+			if err != nil {
+				t.Fatalf("new error should be nil. got=%s", err.Error())
+			}
+			cli.MaxRetries = 0
+
+			result, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"hello"}, TargetLang: "JA"})
+			if fallbackRequests != tc.expectedFallbackRequests {
+				t.Fatalf("fallback request count wrong. want=%d, got=%d", tc.expectedFallbackRequests, fallbackRequests)
+			}
+			if tc.expectedErrMessage == "" {
+				if err != nil {
+					t.Fatalf("response error should be nil. got=%s", err.Error())
+				}
+				if result.Metadata.FellBack != tc.expectedFellBack {
+					t.Fatalf("metadata fell back wrong. want=%t, got=%t", tc.expectedFellBack, result.Metadata.FellBack)
+				}
+				expectedEndpoint := primary.URL
+				if tc.expectedFellBack {
+					expectedEndpoint = fallback.URL
+				}
+				if result.Metadata.Endpoint != expectedEndpoint {
+					t.Fatalf("metadata endpoint wrong. want=%s, got=%s", expectedEndpoint, result.Metadata.Endpoint)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("response error should not be non-nil. got=nil")
+				}
+				if !strings.Contains(err.Error(), tc.expectedErrMessage) {
+					t.Fatalf("reponse error message wrong. '%s' is expected to contain '%s'", err.Error(), tc.expectedErrMessage)
+				}
+			}
+		})
+	}
+}
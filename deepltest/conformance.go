@@ -0,0 +1,131 @@
+package deepltest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	deepl "github.com/DaikiYamakawa/deepl-go"
+	"golang.org/x/xerrors"
+)
+
+// RunTranslatorConformance checks that the translators made by
+// newTranslator keep the contract of deepl.Translator, for fakes, stubs and
+// adapters of other translation services alike:
+//
+//   - a result has one translation per text, in request order, and the
+//     request is left untouched
+//   - concurrent calls are safe
+//   - a request without texts yields no translations or fails with an error
+//     matching deepl.IsInvalidRequest, like DeepL itself
+//   - a canceled or expired context fails with an error matching its
+//     context error that isn't deepl.IsRetryable, and no result
+//
+// Translations must be deterministic within a test: translating a text on
+// its own yields the same text as translating it together with others.
+// newTranslator is called once per subtest.
+func RunTranslatorConformance(t *testing.T, newTranslator func(t *testing.T) deepl.Translator) {
+	t.Run("order", func(t *testing.T) {
+		tr := newTranslator(t)
+		texts := []string{"one", "", "Two words", "three <b>bold</b>", "four", "Five.", "six\nlines", "seven"}
+		req := deepl.TranslateRequest{Text: append([]string(nil), texts...), SourceLang: "EN", TargetLang: "DE"}
+		batch := translateTexts(t, tr, req)
+		if !reflect.DeepEqual(req.Text, texts) {
+			t.Fatalf("request texts changed to %q", req.Text)
+		}
+		for i, text := range texts {
+			single := translateTexts(t, tr, deepl.TranslateRequest{Text: []string{text}, SourceLang: "EN", TargetLang: "DE"})
+			if batch[i] != single[0] {
+				t.Fatalf("translation %d of the batch is %q, of text %q alone %q", i, batch[i], text, single[0])
+			}
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		tr := newTranslator(t)
+		const n = 8
+		got := make([][]string, n)
+		errs := make([]error, n)
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				res, err := tr.Translate(context.Background(), deepl.TranslateRequest{Text: []string{fmt.Sprintf("text %d", i)}, TargetLang: "DE"})
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				got[i] = res.Texts()
+			}(i)
+		}
+		wg.Wait()
+		for i := 0; i < n; i++ {
+			if errs[i] != nil {
+				t.Fatal(errs[i])
+			}
+			expected := translateTexts(t, tr, deepl.TranslateRequest{Text: []string{fmt.Sprintf("text %d", i)}, TargetLang: "DE"})
+			if !reflect.DeepEqual(got[i], expected) {
+				t.Fatalf("concurrent call %d got %q, expected %q", i, got[i], expected)
+			}
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		tr := newTranslator(t)
+		for _, texts := range [][]string{nil, {}} {
+			res, err := tr.Translate(context.Background(), deepl.TranslateRequest{Text: texts, TargetLang: "DE"})
+			switch {
+			case err != nil && !deepl.IsInvalidRequest(err):
+				t.Fatalf("request without texts failed with %v, expected an invalid request", err)
+			case err == nil && (res == nil || len(res.Translations) != 0):
+				t.Fatalf("request without texts got %+v", res)
+			}
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		tr := newTranslator(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		checkContextError(t, tr, ctx, context.Canceled)
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		tr := newTranslator(t)
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		checkContextError(t, tr, ctx, context.DeadlineExceeded)
+	})
+}
+
+// translateTexts translates req with tr and checks the number of
+// translations.
+func translateTexts(t *testing.T, tr deepl.Translator, req deepl.TranslateRequest) []string {
+	t.Helper()
+	res, err := tr.Translate(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res == nil || len(res.Translations) != len(req.Text) {
+		t.Fatalf("expected %d translations, got %+v", len(req.Text), res)
+	}
+	return res.Texts()
+}
+
+func checkContextError(t *testing.T, tr deepl.Translator, ctx context.Context, expected error) {
+	t.Helper()
+	res, err := tr.Translate(ctx, deepl.TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"})
+	if !xerrors.Is(err, expected) {
+		t.Fatalf("expected %v, got %v", expected, err)
+	}
+	if deepl.IsRetryable(err) {
+		t.Fatalf("%v is retryable", err)
+	}
+	if res != nil {
+		t.Fatalf("unexpected result %+v with error", res)
+	}
+}
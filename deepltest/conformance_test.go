@@ -0,0 +1,46 @@
+package deepltest_test
+
+import (
+	"testing"
+
+	deepl "github.com/DaikiYamakawa/deepl-go"
+	"github.com/DaikiYamakawa/deepl-go/deepltest"
+)
+
+func TestTranslatorConformance(t *testing.T) {
+	tt := []struct {
+		name          string
+		newTranslator func(t *testing.T) deepl.Translator
+	}{
+		{
+			name: "client with Server",
+			newTranslator: func(t *testing.T) deepl.Translator {
+				s := deepltest.NewServer()
+				t.Cleanup(s.Close)
+				return newClient(t, s)
+			},
+		},
+		{
+			name: "pseudo",
+			newTranslator: func(t *testing.T) deepl.Translator {
+				return deepl.NewPseudoTranslator()
+			},
+		},
+		{
+			name: "client with pseudo",
+			newTranslator: func(t *testing.T) deepl.Translator {
+				cli, err := deepl.New("http://127.0.0.1:1", nil, deepl.WithNoEnv(), deepl.WithPseudoTranslation(deepl.NewPseudoTranslator()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return cli
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			deepltest.RunTranslatorConformance(t, tc.newTranslator)
+		})
+	}
+}
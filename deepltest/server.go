@@ -0,0 +1,311 @@
+// Package deepltest provides a fake DeepL API for testing code that uses
+// the deepl package, including its behavior when the API misbehaves, and a
+// conformance suite for implementations of deepl.Translator.
+package deepltest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// statusQuotaExceeded is DeepL's status for an exhausted character quota.
+const statusQuotaExceeded = 456
+
+// Server is a fake DeepL API. It translates by prefixing each text with
+// its target language, e.g. "DE:Hello", unless TranslateWith is used.
+// Failures are configured with the fluent methods, which are safe to call
+// while requests are served:
+//
+//	s := deepltest.NewServer().FailWith429(2).Delay(10 * time.Millisecond)
+//	defer s.Close()
+//	cli, err := deepl.New(s.URL, nil, deepl.WithAPIKey("test"))
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	translate func(text, sourceLang, targetLang string) string
+	delay     time.Duration
+	fail429   int
+	drop      int
+	malformed int
+	// limit is the character limit, or 0 for none
+	limit  int
+	counts Counts
+	billed []int
+}
+
+// Counts are the requests a Server received.
+type Counts struct {
+	// Requests counts all requests, Translations the successful translate
+	// requests.
+	Requests     int
+	Translations int
+	// Characters is the number of characters translated successfully.
+	Characters int
+	// Injected counts requests answered with a configured failure.
+	Injected int
+}
+
+// NewServer starts a fake DeepL API. Close it when done.
+func NewServer() *Server {
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// TranslateWith replaces the default translation of texts.
+func (s *Server) TranslateWith(translate func(text, sourceLang, targetLang string) string) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.translate = translate
+	return s
+}
+
+// FailWith429 answers the next n requests with 429 Too Many Requests.
+func (s *Server) FailWith429(n int) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail429 = n
+	return s
+}
+
+// Delay holds every response for d.
+func (s *Server) Delay(d time.Duration) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = d
+	return s
+}
+
+// DropConnection closes the connection of the next n requests after half of
+// the response body was sent.
+func (s *Server) DropConnection(n int) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drop = n
+	return s
+}
+
+// MalformedJSON answers the next n requests with a truncated JSON body and
+// status 200.
+func (s *Server) MalformedJSON(n int) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.malformed = n
+	return s
+}
+
+// CharacterLimit sets the character quota reported by /v2/usage. A translate
+// request that would take the count of characters above limit is answered
+// with 456, so the count can reach the limit exactly. Zero means no limit.
+func (s *Server) CharacterLimit(limit int) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limit = limit
+	return s
+}
+
+// Counts returns the requests received so far.
+func (s *Server) Counts() Counts {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counts
+}
+
+// Billed returns the characters billed for each successful translate
+// request, in order.
+func (s *Server) Billed() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.billed...)
+}
+
+// Reset clears the counts and billed characters, for example between tests
+// sharing a server. Configured failures and limits are kept.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts = Counts{}
+	s.billed = nil
+}
+
+type translateRequest struct {
+	Text                 []string `json:"text"`
+	SourceLang           string   `json:"source_lang"`
+	TargetLang           string   `json:"target_lang"`
+	ShowBilledCharacters bool     `json:"show_billed_characters"`
+}
+
+type translation struct {
+	DetectedSourceLanguage string `json:"detected_source_language"`
+	Text                   string `json:"text"`
+	BilledCharacters       int    `json:"billed_characters,omitempty"`
+}
+
+// failure is a configured failure taken by a request.
+type failure int
+
+const (
+	failNone failure = iota
+	fail429
+	failDrop
+	failMalformed
+)
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.counts.Requests++
+	delay := s.delay
+	f := failNone
+	switch {
+	case s.fail429 > 0:
+		s.fail429--
+		f = fail429
+	case s.drop > 0:
+		s.drop--
+		f = failDrop
+	case s.malformed > 0:
+		s.malformed--
+		f = failMalformed
+	}
+	if f != failNone {
+		s.counts.Injected++
+	}
+	s.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch f {
+	case fail429:
+		writeError(w, http.StatusTooManyRequests, "Too many requests")
+		return
+	case failDrop:
+		dropConnection(w)
+		return
+	case failMalformed:
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"translations":[{"text":`))
+		return
+	}
+
+	if r.URL.Query().Get("auth_key") == "" && r.Header.Get("Authorization") == "" {
+		writeError(w, http.StatusForbidden, "Missing authentication")
+		return
+	}
+	switch r.URL.Path {
+	case "/v2/translate":
+		s.serveTranslate(w, r)
+	case "/v2/usage":
+		s.mu.Lock()
+		usage := struct {
+			CharacterCount int `json:"character_count"`
+			CharacterLimit int `json:"character_limit"`
+		}{s.counts.Characters, s.limit}
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, usage)
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func (s *Server) serveTranslate(w http.ResponseWriter, r *http.Request) {
+	var req translateRequest
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid form")
+			return
+		}
+		req = translateRequest{
+			Text:                 r.PostForm["text"],
+			SourceLang:           r.PostForm.Get("source_lang"),
+			TargetLang:           r.PostForm.Get("target_lang"),
+			ShowBilledCharacters: r.PostForm.Get("show_billed_characters") == "1",
+		}
+	}
+	if len(req.Text) == 0 || req.TargetLang == "" {
+		writeError(w, http.StatusBadRequest, "Parameter 'text' and 'target_lang' are required")
+		return
+	}
+
+	billed := make([]int, len(req.Text))
+	characters := 0
+	for i, text := range req.Text {
+		billed[i] = utf8.RuneCountInString(text)
+		characters += billed[i]
+	}
+
+	s.mu.Lock()
+	if s.limit > 0 && s.counts.Characters+characters > s.limit {
+		s.mu.Unlock()
+		writeError(w, statusQuotaExceeded, "Quota exceeded")
+		return
+	}
+	s.counts.Translations++
+	s.counts.Characters += characters
+	s.billed = append(s.billed, characters)
+	translate := s.translate
+	s.mu.Unlock()
+
+	source := strings.ToUpper(req.SourceLang)
+	if source == "" {
+		source = "EN"
+	}
+	resp := struct {
+		Translations []translation `json:"translations"`
+	}{Translations: make([]translation, len(req.Text))}
+	for i, text := range req.Text {
+		translated := strings.ToUpper(req.TargetLang) + ":" + text
+		if translate != nil {
+			translated = translate(text, req.SourceLang, req.TargetLang)
+		}
+		resp.Translations[i] = translation{DetectedSourceLanguage: source, Text: translated}
+		if req.ShowBilledCharacters {
+			resp.Translations[i].BilledCharacters = billed[i]
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, struct {
+		Message string `json:"message"`
+	}{message})
+}
+
+// dropConnection announces a body and closes the connection halfway.
+func dropConnection(w http.ResponseWriter) {
+	body := `{"translations":[{"detected_source_language":"EN","text":"dropped"}]}`
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(body[:len(body)/2]))
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	// ErrAbortHandler makes the server close the connection without
+	// logging a stack trace
+	panic(http.ErrAbortHandler)
+}
@@ -0,0 +1,150 @@
+package deepltest_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	deepl "github.com/DaikiYamakawa/deepl-go"
+	"github.com/DaikiYamakawa/deepl-go/deepltest"
+	"golang.org/x/net/context"
+)
+
+func newClient(t *testing.T, s *deepltest.Server) *deepl.Client {
+	cli, err := deepl.New(s.URL, nil, deepl.WithAPIKey("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.RetryBackoff = time.Millisecond
+	return cli
+}
+
+func translate(cli *deepl.Client, texts ...string) (*deepl.TranslateResult, error) {
+	return cli.Translate(context.Background(), deepl.TranslateRequest{Text: texts, TargetLang: "DE"})
+}
+
+func TestServer_Translate(t *testing.T) {
+	s := deepltest.NewServer()
+	defer s.Close()
+	cli := newClient(t, s)
+
+	res, err := translate(cli, "Hello", "World")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(res.Texts(), "|"); got != "DE:Hello|DE:World" {
+		t.Fatalf("unexpected translations %q", got)
+	}
+	if _, err := cli.TranslateSentence(context.Background(), "Hello", "EN", "FR"); err != nil {
+		t.Fatalf("form request failed: %v", err)
+	}
+
+	s.TranslateWith(func(text, sourceLang, targetLang string) string { return strings.ToUpper(text) })
+	res, err = translate(cli, "Hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Translations[0].Text != "HELLO" {
+		t.Fatalf("custom translation not used: %q", res.Translations[0].Text)
+	}
+	if counts := s.Counts(); counts.Requests != 3 || counts.Translations != 3 || counts.Characters != 20 {
+		t.Fatalf("unexpected counts %+v", counts)
+	}
+}
+
+func TestServer_FailWith429(t *testing.T) {
+	s := deepltest.NewServer().FailWith429(2)
+	defer s.Close()
+	cli := newClient(t, s)
+	cli.MaxRetries = 2
+
+	if _, err := translate(cli, "Hello"); err != nil {
+		t.Fatalf("retries did not recover: %v", err)
+	}
+	if counts := s.Counts(); counts.Requests != 3 || counts.Injected != 2 {
+		t.Fatalf("unexpected counts %+v", counts)
+	}
+
+	s.FailWith429(1)
+	cli.MaxRetries = 0
+	cli.SoftFail = true
+	res, err := translate(cli, "Hello")
+	if err != nil || !res.Metadata.Failed || res.Translations[0].Text != "Hello" {
+		t.Fatalf("expected soft-failed result, got %+v, %v", res, err)
+	}
+}
+
+func TestServer_Delay(t *testing.T) {
+	s := deepltest.NewServer().Delay(50 * time.Millisecond)
+	defer s.Close()
+	cli := newClient(t, s)
+	cli.MaxRetries = 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := cli.Translate(ctx, deepl.TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"}); err == nil {
+		t.Fatal("expected timeout")
+	}
+}
+
+func TestServer_BrokenResponses(t *testing.T) {
+	s := deepltest.NewServer().DropConnection(1).MalformedJSON(1)
+	defer s.Close()
+	cli := newClient(t, s)
+	cli.MaxRetries = 0
+
+	if _, err := translate(cli, "Hello"); err == nil || !strings.Contains(err.Error(), "Failed to read response") {
+		t.Fatalf("expected read error for dropped connection, got %v", err)
+	}
+	if _, err := translate(cli, "Hello"); err == nil || !strings.Contains(err.Error(), "Failed to parse Json") {
+		t.Fatalf("expected parse error for malformed JSON, got %v", err)
+	}
+	if _, err := translate(cli, "Hello"); err != nil {
+		t.Fatal(err)
+	}
+	if counts := s.Counts(); counts.Injected != 2 || counts.Translations != 1 {
+		t.Fatalf("unexpected counts %+v", counts)
+	}
+}
+
+func TestServer_CharacterLimit(t *testing.T) {
+	s := deepltest.NewServer().CharacterLimit(8)
+	defer s.Close()
+	cli := newClient(t, s)
+
+	if _, err := translate(cli, "Hello"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := translate(cli, "World"); !deepl.IsQuotaError(err) {
+		t.Fatalf("expected quota error, got %v", err)
+	}
+	res, err := cli.Translate(context.Background(), deepl.TranslateRequest{Text: []string{"Bye"}, TargetLang: "DE", ShowBilledCharacters: true})
+	if err != nil {
+		t.Fatalf("texts reaching the limit exactly should pass: %v", err)
+	}
+	if res.Translations[0].BilledCharacters != 3 {
+		t.Fatalf("billed characters wrong: %+v", res.Translations[0])
+	}
+	if _, err := translate(cli, "!"); !deepl.IsQuotaError(err) {
+		t.Fatalf("expected quota error at the limit, got %v", err)
+	}
+
+	status, err := cli.GetAccountStatus(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.CharacterCount != 8 || status.CharacterLimit != 8 {
+		t.Fatalf("unexpected usage %+v", status)
+	}
+	if billed := s.Billed(); len(billed) != 2 || billed[0] != 5 || billed[1] != 3 {
+		t.Fatalf("unexpected billed characters %v", billed)
+	}
+
+	s.Reset()
+	if _, err := translate(cli, "Hello"); err != nil {
+		t.Fatalf("reset did not restore the quota: %v", err)
+	}
+	if counts := s.Counts(); counts.Requests != 1 || counts.Characters != 5 {
+		t.Fatalf("unexpected counts after reset %+v", counts)
+	}
+}
@@ -0,0 +1,54 @@
+package deepl
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Codes of deprecation warnings. A code keeps its meaning across releases.
+const (
+	// DeprecatedTranslateSentence: TranslateSentence, use Translate or
+	// TranslateText.
+	DeprecatedTranslateSentence = "DEPL001"
+	// DeprecatedBareTarget: the target languages EN and PT, use a regional
+	// variant.
+	DeprecatedBareTarget = "DEPL002"
+	// DeprecatedAuthInQuery: Client.LegacyAuthInQuery, send the API key in
+	// the Authorization header.
+	DeprecatedAuthInQuery = "DEPL003"
+)
+
+// deprecationsWarned holds the codes already logged by this process.
+var deprecationsWarned sync.Map
+
+// WithoutDeprecationWarnings sets Client.SilenceDeprecations.
+func WithoutDeprecationWarnings() Option {
+	return func(c *Client) error {
+		c.SilenceDeprecations = true
+		return nil
+	}
+}
+
+// warnDeprecated logs the warning of code unless it was logged before by any
+// client of the process.
+func (c *Client) warnDeprecated(ctx context.Context, code, message string) {
+	if c.SilenceDeprecations {
+		return
+	}
+	if _, warned := deprecationsWarned.LoadOrStore(code, true); warned {
+		return
+	}
+	c.logf(ctx, "Deprecated (%s): %s", code, message)
+}
+
+// warnBareTarget warns about target languages DeepL deprecated in favor of
+// their regional variants.
+func (c *Client) warnBareTarget(ctx context.Context, targetLang string) {
+	switch strings.ToUpper(strings.TrimSpace(targetLang)) {
+	case "EN":
+		c.warnDeprecated(ctx, DeprecatedBareTarget, "target language EN is deprecated, use EN-GB or EN-US")
+	case "PT":
+		c.warnDeprecated(ctx, DeprecatedBareTarget, "target language PT is deprecated, use PT-BR or PT-PT")
+	}
+}
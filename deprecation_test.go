@@ -0,0 +1,115 @@
+package deepl
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func resetDeprecations() {
+	deprecationsWarned.Range(func(code, _ interface{}) bool {
+		deprecationsWarned.Delete(code)
+		return true
+	})
+}
+
+func TestClient_DeprecationWarnedOnce(t *testing.T) {
+	resetDeprecations()
+	defer resetDeprecations()
+
+	var logs syncBuffer
+	cli := &Client{Logger: log.New(&logs, "", 0)}
+	other := &Client{Logger: cli.Logger}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cli.warnBareTarget(context.Background(), "en")
+			other.warnBareTarget(context.Background(), "PT")
+		}()
+	}
+	wg.Wait()
+
+	if n := strings.Count(logs.String(), DeprecatedBareTarget); n != 1 {
+		t.Fatalf("expected one warning, got %d in %q", n, logs.String())
+	}
+	cli.warnBareTarget(context.Background(), "EN-GB")
+	if strings.Count(logs.String(), "\n") != 1 {
+		t.Fatalf("unexpected warnings %q", logs.String())
+	}
+}
+
+func TestClient_DeprecatedAuthInQuery(t *testing.T) {
+	resetDeprecations()
+	defer resetDeprecations()
+
+	cli, _, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	var logs syncBuffer
+	cli.Logger = log.New(&logs, "", 0)
+
+	req := TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"}
+	if _, err := cli.Translate(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(logs.String(), DeprecatedAuthInQuery) {
+		t.Fatalf("unexpected warning %q", logs.String())
+	}
+	cli.LegacyAuthInQuery = true
+	for i := 0; i < 2; i++ {
+		if _, err := cli.Translate(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := strings.Count(logs.String(), DeprecatedAuthInQuery); n != 1 {
+		t.Fatalf("expected one warning, got %d in %q", n, logs.String())
+	}
+	if !cli.Config().LegacyAuthInQuery || cli.WireFeatures().Auth != "query" {
+		t.Fatalf("legacy auth not reflected: %+v", cli.Config())
+	}
+}
+
+func TestClient_DeprecationWarningsSilenced(t *testing.T) {
+	resetDeprecations()
+	defer resetDeprecations()
+
+	var logs bytes.Buffer
+	cli, err := New("https://api.deepl.com", log.New(&logs, "", 0), WithoutDeprecationWarnings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.warnDeprecated(context.Background(), DeprecatedTranslateSentence, "TranslateSentence is deprecated")
+	if logs.Len() != 0 {
+		t.Fatalf("unexpected warning %q", logs.String())
+	}
+
+	cli.SilenceDeprecations = false
+	cli.warnDeprecated(context.Background(), DeprecatedTranslateSentence, "TranslateSentence is deprecated")
+	if got := logs.String(); got != "Deprecated (DEPL001): TranslateSentence is deprecated\n" {
+		t.Fatalf("unexpected warning %q", got)
+	}
+}
@@ -0,0 +1,106 @@
+package deepl
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const defaultDuplicateEntries = 1024
+
+// DuplicateDetection configures WithDuplicateDetection.
+type DuplicateDetection struct {
+	// Window is how long a request is remembered after it was first seen.
+	Window time.Duration
+	// MinCount is the number of identical requests within Window from
+	// which each one is reported, 2 by default.
+	MinCount int
+	// MaxEntries bounds the remembered requests, 1024 by default. The
+	// oldest are forgotten first.
+	MaxEntries int
+	// OnDuplicate is called with the CanonicalRequestHash of the request
+	// and its count instead of logging a warning. It must be safe for
+	// concurrent use.
+	OnDuplicate func(ctx context.Context, hash string, count int)
+}
+
+// WithDuplicateDetection warns when Translate is called with an identical
+// request within the window of d, which usually means a code path
+// translates the same content twice. Calls are never failed. Only hashes of
+// the requests are kept.
+func WithDuplicateDetection(d DuplicateDetection) Option {
+	return func(c *Client) error {
+		if d.Window <= 0 || d.MinCount < 0 || d.MaxEntries < 0 {
+			return xerrors.Errorf("Failed to configure duplicate detection: invalid window %v, count %d or size %d", d.Window, d.MinCount, d.MaxEntries)
+		}
+		if d.MinCount < 2 {
+			d.MinCount = 2
+		}
+		if d.MaxEntries == 0 {
+			d.MaxEntries = defaultDuplicateEntries
+		}
+		c.duplicates = &duplicateDetector{DuplicateDetection: d, seen: make(map[string]*seenRequest)}
+		return nil
+	}
+}
+
+type duplicateDetector struct {
+	DuplicateDetection
+
+	mu   sync.Mutex
+	seen map[string]*seenRequest
+	// order holds the requests in the order they were first seen, including
+	// forgotten ones that weren't removed yet
+	order []*seenRequest
+}
+
+type seenRequest struct {
+	hash  string
+	first time.Time
+	count int
+}
+
+// record counts a request with hash at now and returns its count within the
+// window.
+func (d *duplicateDetector) record(hash string, now time.Time) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for len(d.order) > 0 {
+		oldest := d.order[0]
+		forgotten := d.seen[oldest.hash] != oldest
+		if !forgotten && now.Sub(oldest.first) < d.Window && len(d.seen) < d.MaxEntries {
+			break
+		}
+		if !forgotten {
+			delete(d.seen, oldest.hash)
+		}
+		d.order[0] = nil
+		d.order = d.order[1:]
+	}
+
+	s, ok := d.seen[hash]
+	if !ok {
+		s = &seenRequest{hash: hash, first: now}
+		d.seen[hash] = s
+		d.order = append(d.order, s)
+	}
+	s.count++
+	return s.count
+}
+
+// checkDuplicate reports req if it was seen often enough within the window.
+func (c *Client) checkDuplicate(ctx context.Context, hash string) {
+	d := c.duplicates
+	count := d.record(hash, c.clock().Now())
+	if count < d.MinCount {
+		return
+	}
+	if d.OnDuplicate != nil {
+		d.OnDuplicate(ctx, hash, count)
+		return
+	}
+	c.logf(ctx, "Duplicate translate request %s: sent %d times within %v", hash, count, d.Window)
+}
@@ -0,0 +1,137 @@
+package deepl
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestClient_DuplicateDetection(t *testing.T) {
+	cli, received, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	clock := newFakeClock()
+	cli.Clock = clock
+	var logs bytes.Buffer
+	cli.Logger = log.New(&logs, "", 0)
+	if err := WithDuplicateDetection(DuplicateDetection{Window: time.Minute})(cli); err != nil {
+		t.Fatal(err)
+	}
+
+	article := TranslateRequest{Text: []string{"A long article"}, TargetLang: "DE"}
+	translate := func(req TranslateRequest) {
+		t.Helper()
+		if _, err := cli.Translate(ContextWithRequestID(context.Background(), "r1"), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	translate(article)
+	translate(TranslateRequest{Text: []string{"A long article"}, TargetLang: "FR"})
+	if logs.Len() != 0 {
+		t.Fatalf("unexpected warning %q", logs.String())
+	}
+	clock.Advance(30 * time.Second)
+	translate(article)
+	translate(article)
+	hash := CanonicalRequestHash(article)
+	expected := "[r1] Duplicate translate request " + hash + ": sent 2 times within 1m0s\n" +
+		"[r1] Duplicate translate request " + hash + ": sent 3 times within 1m0s\n"
+	if logs.String() != expected {
+		t.Fatalf("got %q, expected %q", logs.String(), expected)
+	}
+	if strings.Contains(logs.String(), "article") {
+		t.Fatal("warning contains the text")
+	}
+	// duplicates are still translated
+	if len(*received) != 4 {
+		t.Fatalf("sent %d requests, expected 4", len(*received))
+	}
+
+	logs.Reset()
+	clock.Advance(30 * time.Second)
+	translate(article)
+	if logs.Len() != 0 {
+		t.Fatalf("request remembered after the window: %q", logs.String())
+	}
+}
+
+func TestDuplicateDetector(t *testing.T) {
+	tt := []struct {
+		name string
+
+		detection DuplicateDetection
+		hashes    string
+
+		expected []int
+	}{
+		{
+			name: "counts",
+
+			detection: DuplicateDetection{Window: time.Minute},
+			hashes:    "aabab",
+
+			expected: []int{1, 2, 1, 3, 2},
+		},
+		{
+			name: "oldest forgotten when full",
+
+			detection: DuplicateDetection{Window: time.Minute, MaxEntries: 2},
+			hashes:    "abcab",
+
+			expected: []int{1, 1, 1, 1, 1},
+		},
+		{
+			name: "recent kept when full",
+
+			detection: DuplicateDetection{Window: time.Minute, MaxEntries: 2},
+			hashes:    "abcc",
+
+			expected: []int{1, 1, 1, 2},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := &Client{}
+			if err := WithDuplicateDetection(tc.detection)(cli); err != nil {
+				t.Fatal(err)
+			}
+			now := time.Now()
+			for i, hash := range tc.hashes {
+				if got := cli.duplicates.record(string(hash), now); got != tc.expected[i] {
+					t.Fatalf("count %d of %c = %d, expected %d", i, hash, got, tc.expected[i])
+				}
+			}
+			if len(cli.duplicates.seen) > cli.duplicates.MaxEntries {
+				t.Fatalf("%d entries kept", len(cli.duplicates.seen))
+			}
+		})
+	}
+}
+
+func TestClient_DuplicateDetectionHook(t *testing.T) {
+	cli, _, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	var counts []int
+	err := WithDuplicateDetection(DuplicateDetection{Window: time.Hour, MinCount: 3, OnDuplicate: func(ctx context.Context, hash string, count int) {
+		counts = append(counts, count)
+	}})(cli)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(counts) != 2 || counts[0] != 3 || counts[1] != 4 {
+		t.Fatalf("unexpected reports %v", counts)
+	}
+
+	if err := WithDuplicateDetection(DuplicateDetection{})(cli); err == nil {
+		t.Fatal("expected error for a zero window")
+	}
+}
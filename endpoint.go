@@ -0,0 +1,60 @@
+package deepl
+
+import (
+	"net/url"
+	"path"
+)
+
+// knownEndpoints are resolved against BaseURL once per client.
+var knownEndpoints = []string{
+	"/v2/translate",
+	"/v2/usage",
+	"/v2/languages",
+}
+
+// endpoint is an API path resolved against BaseURL, with the base query kept
+// separate so per-request parameters can be appended without re-parsing.
+type endpoint struct {
+	url   string
+	path  string
+	query url.Values
+}
+
+func resolveEndpoint(base *url.URL, apiPath string) endpoint {
+	u := *base
+	u.Path = path.Join(u.Path, apiPath)
+	u.RawPath = ""
+	query := u.Query()
+	u.RawQuery = ""
+	u.ForceQuery = false
+	u.Fragment = ""
+	return endpoint{url: u.String(), path: u.Path, query: query}
+}
+
+func (c *Client) endpoint(apiPath string) endpoint {
+	c.endpointsOnce.Do(func() {
+		c.endpoints = make(map[string]endpoint, len(knownEndpoints))
+		for _, p := range knownEndpoints {
+			c.endpoints[p] = resolveEndpoint(c.BaseURL, p)
+		}
+	})
+	if ep, ok := c.endpoints[apiPath]; ok {
+		return ep
+	}
+	return resolveEndpoint(c.BaseURL, apiPath)
+}
+
+// requestURL returns the endpoint URL with the base query and params.
+func (ep endpoint) requestURL(params url.Values) string {
+	q := make(url.Values, len(ep.query)+len(params))
+	for key, values := range ep.query {
+		q[key] = append([]string(nil), values...)
+	}
+	for key, values := range params {
+		q[key] = append(q[key], values...)
+	}
+	if len(q) == 0 {
+		return ep.url
+	}
+	return ep.url + "?" + q.Encode()
+}
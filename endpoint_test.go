@@ -0,0 +1,111 @@
+package deepl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestResolveEndpoint(t *testing.T) {
+	tt := []struct {
+		name string
+
+		inputBaseURL string
+		inputPath    string
+		inputParams  url.Values
+
+		expectedURL string
+	}{
+		{
+			name: "host only",
+
+			inputBaseURL: "https://api.deepl.com",
+			inputPath:    "/v2/translate",
+			inputParams:  url.Values{"auth_key": {"k"}},
+
+			expectedURL: "https://api.deepl.com/v2/translate?auth_key=k",
+		},
+		{
+			name: "base path with trailing slash",
+
+			inputBaseURL: "https://proxy.example.com/deepl/",
+			inputPath:    "/v2/usage",
+			inputParams:  url.Values{"auth_key": {"k"}},
+
+			expectedURL: "https://proxy.example.com/deepl/v2/usage?auth_key=k",
+		},
+		{
+			name: "base path and relative api path",
+
+			inputBaseURL: "https://proxy.example.com/deepl",
+			inputPath:    "v2/usage",
+			inputParams:  url.Values{"auth_key": {"k"}},
+
+			expectedURL: "https://proxy.example.com/deepl/v2/usage?auth_key=k",
+		},
+		{
+			name: "base query is kept",
+
+			inputBaseURL: "https://proxy.example.com/deepl?tenant=a",
+			inputPath:    "/v2/translate",
+			inputParams:  url.Values{"auth_key": {"k"}},
+
+			expectedURL: "https://proxy.example.com/deepl/v2/translate?auth_key=k&tenant=a",
+		},
+		{
+			name: "no query",
+
+			inputBaseURL: "https://api.deepl.com",
+			inputPath:    "/v2/translate",
+
+			expectedURL: "https://api.deepl.com/v2/translate",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			baseURL, err := url.Parse(tc.inputBaseURL)
+			if err != nil {
+				t.Fatalf("failed to parse base URL: %s", err.Error())
+			}
+			cli := &Client{BaseURL: baseURL}
+
+			for i := 0; i < 2; i++ {
+				if got := cli.endpoint(tc.inputPath).requestURL(tc.inputParams); got != tc.expectedURL {
+					t.Fatalf("request URL wrong. want=%s, got=%s", tc.expectedURL, got)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_CustomBaseURLPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/deepl/v2/usage" {
+			t.Fatalf("request path wrong. want=%s, got=%s", "/deepl/v2/usage", req.URL.Path)
+		}
+		if got := req.URL.Query().Get("tenant"); got != "a" {
+			t.Fatalf("request query wrong. want=%s, got=%s", "a", got)
+		}
+		if got := req.Header.Get("Authorization"); got != "DeepL-Auth-Key "+testAPIKey {
+			t.Fatalf("request Authorization wrong. want=DeepL-Auth-Key %s, got=%s", testAPIKey, got)
+		}
+		w.Write([]byte(`{"character_count":1,"character_limit":2}`))
+	}))
+	defer server.Close()
+
+	baseURL, err := url.Parse(server.URL + "/deepl/?tenant=a")
+	if err != nil {
+		t.Fatalf("failed to get mock server URL: %s", err.Error())
+	}
+	cli := &Client{BaseURL: baseURL, HTTPClient: server.Client(), APIKey: testAPIKey}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cli.GetAccountStatus(context.Background()); err != nil {
+			t.Fatalf("response error should be nil. got=%s", err.Error())
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package deepl
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// HTMLEntityHandling selects how HTML entities in plain-text translations
+// are treated. Translations requested with a TagHandling are never touched.
+type HTMLEntityHandling int
+
+const (
+	// HTMLEntitiesKeep returns translations as DeepL sent them.
+	HTMLEntitiesKeep HTMLEntityHandling = iota
+	// HTMLEntitiesDecode unescapes entities that were not in the source text.
+	HTMLEntitiesDecode
+	// HTMLEntitiesStrict fails the call if a translation contains an entity
+	// that was not in the source text.
+	HTMLEntitiesStrict
+)
+
+var entityPattern = regexp.MustCompile(`&(?:#[0-9]+|#[xX][0-9a-fA-F]+|[a-zA-Z][a-zA-Z0-9]*);`)
+
+// WithDecodeHTMLEntities unescapes HTML entities that DeepL adds to
+// plain-text translations.
+func WithDecodeHTMLEntities() Option {
+	return func(c *Client) error {
+		c.HTMLEntities = HTMLEntitiesDecode
+		return nil
+	}
+}
+
+// WithStrictHTMLEntities makes plain-text translations containing
+// unexpected HTML entities an error.
+func WithStrictHTMLEntities() Option {
+	return func(c *Client) error {
+		c.HTMLEntities = HTMLEntitiesStrict
+		return nil
+	}
+}
+
+// handleEntities applies c.HTMLEntities to translation i of source.
+func (c *Client) handleEntities(i int, source, translated string) (string, error) {
+	var err error
+	decoded := entityPattern.ReplaceAllStringFunc(translated, func(entity string) string {
+		// entities typed by the user are kept
+		if strings.Contains(source, entity) {
+			return entity
+		}
+		if c.HTMLEntities == HTMLEntitiesStrict {
+			if err == nil {
+				err = xerrors.Errorf("Unexpected HTML entity %s in translation %d", errorText(entity, c.PrivateErrors), i)
+			}
+			return entity
+		}
+		return html.UnescapeString(entity)
+	})
+	if err != nil {
+		return "", err
+	}
+	return decoded, nil
+}
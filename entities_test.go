@@ -0,0 +1,115 @@
+package deepl
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestClient_HTMLEntities(t *testing.T) {
+	tt := []struct {
+		name string
+
+		handling    HTMLEntityHandling
+		text        string
+		translated  string
+		tagHandling TagHandling
+
+		expected           string
+		expectedErrMessage string
+	}{
+		{
+			name: "keep by default",
+
+			handling:   HTMLEntitiesKeep,
+			text:       `Tom & "Jerry"`,
+			translated: `Tom &amp; &quot;Jerry&quot;`,
+
+			expected: `Tom &amp; &quot;Jerry&quot;`,
+		},
+		{
+			name: "decode named entities",
+
+			handling:   HTMLEntitiesDecode,
+			text:       `Tom & "Jerry"`,
+			translated: `Tom &amp; &quot;Jerry&quot;`,
+
+			expected: `Tom & "Jerry"`,
+		},
+		{
+			name: "decode numeric entities",
+
+			handling:   HTMLEntitiesDecode,
+			text:       `it's <ok>`,
+			translated: `it&#39;s &#x3C;ok&#X3e;`,
+
+			expected: `it's <ok>`,
+		},
+		{
+			name: "entities in the source are kept",
+
+			handling:   HTMLEntitiesDecode,
+			text:       `write &amp; for &`,
+			translated: `écrire &amp; pour &amp;`,
+
+			expected: `écrire &amp; pour &amp;`,
+		},
+		{
+			name: "tag handling output is untouched",
+
+			handling:    HTMLEntitiesDecode,
+			text:        `<p>Tom & Jerry</p>`,
+			translated:  `<p>Tom &amp; Jerry</p>`,
+			tagHandling: TagHandlingHTML,
+
+			expected: `<p>Tom &amp; Jerry</p>`,
+		},
+		{
+			name: "strict accepts clean output",
+
+			handling:   HTMLEntitiesStrict,
+			text:       `Tom and Jerry`,
+			translated: `Tom und Jerry`,
+
+			expected: `Tom und Jerry`,
+		},
+		{
+			name: "strict rejects unexpected entities",
+
+			handling:   HTMLEntitiesStrict,
+			text:       `Tom & Jerry`,
+			translated: `Tom &amp; Jerry`,
+
+			expectedErrMessage: "Unexpected HTML entity &amp; in translation 0",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, _, teardown := initTranslateServer(t, func(req TranslateRequest) []Translation {
+				return []Translation{{DetectedSourceLanguage: "EN", Text: tc.translated}}
+			})
+			defer teardown()
+			cli.HTMLEntities = tc.handling
+
+			res, err := cli.Translate(context.Background(), TranslateRequest{
+				Text:        []string{tc.text},
+				TargetLang:  "DE",
+				TagHandling: tc.tagHandling,
+			})
+			if tc.expectedErrMessage != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErrMessage) {
+					t.Fatalf("expected error containing %q, got %v", tc.expectedErrMessage, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Translations[0].Text != tc.expected {
+				t.Fatalf("got %q, expected %q", res.Translations[0].Text, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package deepl
+
+import (
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Formalities returns all Formality values in documentation order.
+func Formalities() []Formality {
+	return []Formality{FormalityDefault, FormalityMore, FormalityLess, FormalityPreferMore, FormalityPreferLess}
+}
+
+// SplitSentencesValues returns all SplitSentences values.
+func SplitSentencesValues() []SplitSentences {
+	return []SplitSentences{SplitSentencesOff, SplitSentencesOn, SplitSentencesNoNewlines}
+}
+
+// TagHandlings returns all TagHandling values.
+func TagHandlings() []TagHandling {
+	return []TagHandling{TagHandlingXML, TagHandlingHTML}
+}
+
+// ModelTypes returns all ModelType values.
+func ModelTypes() []ModelType {
+	return []ModelType{ModelTypeQualityOptimized, ModelTypePreferQualityOptimized, ModelTypeLatencyOptimized}
+}
+
+// ParseFormality parses s case-insensitively.
+func ParseFormality(s string) (Formality, error) {
+	return parseEnum("formality", s, Formalities())
+}
+
+// ParseSplitSentences parses s case-insensitively.
+func ParseSplitSentences(s string) (SplitSentences, error) {
+	return parseEnum("split_sentences", s, SplitSentencesValues())
+}
+
+// ParseTagHandling parses s case-insensitively.
+func ParseTagHandling(s string) (TagHandling, error) {
+	return parseEnum("tag_handling", s, TagHandlings())
+}
+
+// ParseModelType parses s case-insensitively.
+func ParseModelType(s string) (ModelType, error) {
+	return parseEnum("model_type", s, ModelTypes())
+}
+
+// normalizeEnum is the spelling of v sent to DeepL.
+func normalizeEnum[T ~string](v T) T {
+	return T(strings.ToLower(strings.TrimSpace(string(v))))
+}
+
+func parseEnum[T ~string](name, s string, all []T) (T, error) {
+	v := normalizeEnum(T(s))
+	for _, valid := range all {
+		if v == valid {
+			return v, nil
+		}
+	}
+	return "", xerrors.Errorf("Invalid %s %q", name, s)
+}
+
+// validateEnums rejects enum parameters of r the parsers don't accept, so
+// that they are never sent.
+func (r *TranslateRequest) validateEnums() error {
+	for _, err := range []error{
+		checkEnum("formality", r.Formality, Formalities()),
+		checkEnum("split_sentences", r.SplitSentences, SplitSentencesValues()),
+		checkEnum("tag_handling", r.TagHandling, TagHandlings()),
+		checkEnum("model_type", r.ModelType, ModelTypes()),
+	} {
+		if err != nil {
+			return xerrors.Errorf("Failed to validate request: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkEnum parses v unless it is empty, which means "not set".
+func checkEnum[T ~string](name string, v T, all []T) error {
+	if v == "" {
+		return nil
+	}
+	_, err := parseEnum(name, string(v), all)
+	return err
+}
+
+// the enums are encoded as their wire value and rejected on decoding when
+// unknown; the empty value means "not set"
+
+func (f Formality) MarshalText() ([]byte, error) { return []byte(f), nil }
+
+func (f *Formality) UnmarshalText(b []byte) error {
+	return unmarshalEnum(f, b, ParseFormality)
+}
+
+func (s SplitSentences) MarshalText() ([]byte, error) { return []byte(s), nil }
+
+func (s *SplitSentences) UnmarshalText(b []byte) error {
+	return unmarshalEnum(s, b, ParseSplitSentences)
+}
+
+func (t TagHandling) MarshalText() ([]byte, error) { return []byte(t), nil }
+
+func (t *TagHandling) UnmarshalText(b []byte) error {
+	return unmarshalEnum(t, b, ParseTagHandling)
+}
+
+func (m ModelType) MarshalText() ([]byte, error) { return []byte(m), nil }
+
+func (m *ModelType) UnmarshalText(b []byte) error {
+	return unmarshalEnum(m, b, ParseModelType)
+}
+
+func unmarshalEnum[T ~string](v *T, b []byte, parse func(string) (T, error)) error {
+	if len(b) == 0 {
+		*v = ""
+		return nil
+	}
+	parsed, err := parse(string(b))
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
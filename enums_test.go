@@ -0,0 +1,123 @@
+package deepl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+func TestParseEnums(t *testing.T) {
+	for _, f := range Formalities() {
+		if got, err := ParseFormality(" " + string(f) + " "); err != nil || got != f {
+			t.Errorf("ParseFormality(%q) = %q, %v", f, got, err)
+		}
+	}
+	for _, s := range SplitSentencesValues() {
+		if got, err := ParseSplitSentences(string(s)); err != nil || got != s {
+			t.Errorf("ParseSplitSentences(%q) = %q, %v", s, got, err)
+		}
+	}
+	for _, th := range TagHandlings() {
+		if got, err := ParseTagHandling(string(th)); err != nil || got != th {
+			t.Errorf("ParseTagHandling(%q) = %q, %v", th, got, err)
+		}
+	}
+	for _, m := range ModelTypes() {
+		if got, err := ParseModelType(string(m)); err != nil || got != m {
+			t.Errorf("ParseModelType(%q) = %q, %v", m, got, err)
+		}
+	}
+
+	if got, err := ParseFormality("PREFER_MORE"); err != nil || got != FormalityPreferMore {
+		t.Errorf("ParseFormality is case-sensitive: %q, %v", got, err)
+	}
+	if _, err := ParseFormality("polite"); err == nil {
+		t.Error("expected error for unknown formality")
+	}
+	if _, err := ParseModelType(""); err == nil {
+		t.Error("expected error for empty model type")
+	}
+}
+
+func TestEnumJSON(t *testing.T) {
+	req := TranslateRequest{TargetLang: "DE", Formality: FormalityLess, TagHandling: TagHandlingHTML}
+	b, err := json.Marshal(&req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"text":null,"target_lang":"DE","formality":"less","tag_handling":"html"}`
+	if string(b) != expected {
+		t.Fatalf("got %s, expected %s", b, expected)
+	}
+
+	var decoded TranslateRequest
+	if err := json.Unmarshal([]byte(`{"formality":"More","model_type":"latency_optimized","split_sentences":""}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Formality != FormalityMore || decoded.ModelType != ModelTypeLatencyOptimized || decoded.SplitSentences != "" {
+		t.Fatalf("unexpected decoded request %+v", decoded)
+	}
+	if err := json.Unmarshal([]byte(`{"formality":"polite"}`), &decoded); err == nil {
+		t.Fatal("expected error for unknown formality")
+	}
+}
+
+func TestClient_InvalidEnumsNotSent(t *testing.T) {
+	cli, received, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+
+	tt := []struct {
+		name string
+
+		req  TranslateRequest
+		opts []TranslateOption
+	}{
+		{
+			name: "formality option",
+
+			req:  TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"},
+			opts: []TranslateOption{WithFormality(Formality("bogus"))},
+		},
+		{
+			name: "model type option",
+
+			req:  TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"},
+			opts: []TranslateOption{WithModelType(ModelType("fastest"))},
+		},
+		{
+			name: "split sentences field",
+
+			req: TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE", SplitSentences: SplitSentences("2")},
+		},
+		{
+			name: "tag handling field",
+
+			req: TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE", TagHandling: TagHandling("markdown")},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := cli.Translate(context.Background(), tc.req, tc.opts...)
+			var configErr *ConfigError
+			if !xerrors.As(err, &configErr) || configErr.Code != ConfigInvalidOption || !xerrors.Is(err, ErrInvalidOption) {
+				t.Fatalf("expected a ConfigInvalidOption error, got %v", err)
+			}
+			if _, err := BuildTranslateRequest(tc.req, cli.Config()); len(tc.opts) == 0 && err == nil {
+				t.Fatal("expected BuildTranslateRequest to fail")
+			}
+		})
+	}
+	if len(*received) != 0 {
+		t.Fatalf("invalid requests were sent: %+v", *received)
+	}
+
+	if _, err := New("https://api.deepl.com", nil, WithAPIKey(testAPIKey), WithCallDefaults(WithFormality(Formality("bogus")))); !xerrors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption for call defaults, got %v", err)
+	}
+	if _, err := New("https://api.deepl.com", nil, WithAPIKey(testAPIKey), WithPairProfile("EN", "DE", WithTagHandling(TagHandling("md")))); !xerrors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption for pair profile, got %v", err)
+	}
+}
@@ -0,0 +1,394 @@
+package deepl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// StatusQuotaExceeded is the non-standard status DeepL answers with when
+// the character limit has been reached.
+const StatusQuotaExceeded = 456
+
+// APIError is returned when DeepL answers with a status other than 200.
+type APIError struct {
+	StatusCode int
+	// Message is the message from the response body, if there was one.
+	Message string
+	// RetryAfter is the delay of the Retry-After header, zero without one.
+	RetryAfter time.Duration
+
+	userMessage
+}
+
+func (e *APIError) Error() string {
+	switch e.StatusCode {
+	case http.StatusBadRequest:
+		return "Bad request. Please check error message and your parameters. Error message is " + e.Message
+	case http.StatusForbidden:
+		return "Authorization failed. Please supply a valid auth_key parameter."
+	case http.StatusNotFound:
+		return "The requested resource clould not be found."
+	case http.StatusRequestEntityTooLarge:
+		return "The request size exceeds the limit."
+	case http.StatusTooManyRequests:
+		return "Too many requests. Please wait and resend your request."
+	case StatusQuotaExceeded:
+		return "Quota exceeded. The character limit has been reached."
+	case http.StatusServiceUnavailable:
+		return "Resource currently unavailable. Try again later."
+	}
+	// Response status code 5** is internal error but error code "503" is http.StatusServiceUnavailable
+	if e.StatusCode >= 500 {
+		return "Internal error"
+	}
+	return "Unexpected error"
+}
+
+// ErrRequestTooLarge matches errors for requests larger than the request
+// size limit, with xerrors.Is or errors.Is.
+var ErrRequestTooLarge = xerrors.New("Request too large")
+
+// RequestTooLargeError is returned when DeepL answers a translate request
+// with 413, wrapping the *APIError, or when the client rejects a request
+// before sending it. Bytes is the size of the encoded body, or of the text
+// for client-side checks, and Largest is the index of the largest text.
+type RequestTooLargeError struct {
+	Bytes int
+	// Limit is the limit checked by the client, zero for 413 responses.
+	Limit        int
+	Texts        int
+	Largest      int
+	LargestBytes int
+	Err          error
+}
+
+// newRequestTooLargeError describes texts sent in a body of size bytes.
+func newRequestTooLargeError(texts []string, size int, err error) *RequestTooLargeError {
+	e := &RequestTooLargeError{Bytes: size, Texts: len(texts), Err: err}
+	for i, text := range texts {
+		if len(text) > e.LargestBytes {
+			e.Largest, e.LargestBytes = i, len(text)
+		}
+	}
+	return e
+}
+
+func (e *RequestTooLargeError) Error() string {
+	limit := "exceeds the limit"
+	if e.Limit > 0 {
+		limit = fmt.Sprintf("exceeds the limit of %d bytes", e.Limit)
+	}
+	return fmt.Sprintf("%s: %d bytes with %d texts %s, the largest is text %d with %d bytes",
+		ErrRequestTooLarge.Error(), e.Bytes, e.Texts, limit, e.Largest, e.LargestBytes)
+}
+
+func (e *RequestTooLargeError) Is(target error) bool {
+	return target == ErrRequestTooLarge
+}
+
+func (e *RequestTooLargeError) Unwrap() error {
+	return e.Err
+}
+
+// ErrEndpointUnreachable matches errors for base URLs whose host can't be
+// resolved, with xerrors.Is or errors.Is.
+var ErrEndpointUnreachable = xerrors.New("DeepL endpoint unreachable")
+
+// EndpointUnreachableError is returned when the host of the base URL can't
+// be resolved.
+type EndpointUnreachableError struct {
+	Host string
+	Err  *net.DNSError
+
+	userMessage
+}
+
+func (e *EndpointUnreachableError) Error() string {
+	return "Failed to resolve DeepL host " + e.Host + ": " + e.Err.Err +
+		". Free API keys (ending in :fx) use https://api-free.deepl.com, Pro keys use https://api.deepl.com."
+}
+
+func (e *EndpointUnreachableError) Is(target error) bool {
+	return target == ErrEndpointUnreachable
+}
+
+func (e *EndpointUnreachableError) Unwrap() error {
+	return e.Err
+}
+
+// ErrUnexpectedResponse matches errors for successful responses whose JSON
+// doesn't have the expected shape, such as a translate response without
+// translations.
+var ErrUnexpectedResponse = xerrors.New("Unexpected response")
+
+// TranslationCountError is returned when a response has a different number
+// of translations than texts were sent. It matches ErrUnexpectedResponse.
+type TranslationCountError struct {
+	Expected int
+	Got      int
+}
+
+func (e *TranslationCountError) Error() string {
+	return fmt.Sprintf("Failed to parse Json: expected %d translations, got %d", e.Expected, e.Got)
+}
+
+func (e *TranslationCountError) Is(target error) bool {
+	return target == ErrUnexpectedResponse
+}
+
+type unexpectedResponseError struct {
+	err error
+}
+
+// unexpectedResponse wraps err, a failed check of a decoded response, so
+// that it matches ErrUnexpectedResponse.
+func unexpectedResponse(err error) error {
+	if xerrors.Is(err, ErrUnexpectedResponse) {
+		return err
+	}
+	return &unexpectedResponseError{err: err}
+}
+
+func (e *unexpectedResponseError) Error() string {
+	return "Failed to parse Json: " + e.err.Error()
+}
+
+func (e *unexpectedResponseError) Is(target error) bool {
+	return target == ErrUnexpectedResponse
+}
+
+func (e *unexpectedResponseError) Unwrap() error {
+	return e.err
+}
+
+func dnsError(err error) *net.DNSError {
+	var dnsErr *net.DNSError
+	if xerrors.As(err, &dnsErr) {
+		return dnsErr
+	}
+	return nil
+}
+
+func statusOf(err error) (int, bool) {
+	var apiErr *APIError
+	if xerrors.As(err, &apiErr) {
+		return apiErr.StatusCode, true
+	}
+	return 0, false
+}
+
+// ErrTruncatedResponse matches successful responses whose body ended
+// before their Content-Length, as cut off by some proxies and CDNs, with
+// xerrors.Is or errors.Is. They are retried like transport errors.
+var ErrTruncatedResponse = xerrors.New("Truncated response")
+
+// TruncatedResponseError is returned when the body of a successful
+// response ended after Read of its ContentLength bytes. Err is the read or
+// decode error it caused.
+type TruncatedResponseError struct {
+	ContentLength int64
+	Read          int64
+	Err           error
+}
+
+func (e *TruncatedResponseError) Error() string {
+	return fmt.Sprintf("%s: read %d of %d bytes: %s", ErrTruncatedResponse.Error(), e.Read, e.ContentLength, e.Err.Error())
+}
+
+func (e *TruncatedResponseError) Is(target error) bool {
+	return target == ErrTruncatedResponse
+}
+
+func (e *TruncatedResponseError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable reports whether the request that failed with err may succeed
+// when sent again: rate limiting, server errors, transport errors and
+// truncated responses. Canceled requests are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil || xerrors.Is(err, context.Canceled) || xerrors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if xerrors.Is(err, ErrTruncatedResponse) {
+		return true
+	}
+	if dnsErr := dnsError(err); dnsErr != nil {
+		// a host that doesn't exist won't appear on the next attempt
+		return !dnsErr.IsNotFound
+	}
+	status, ok := statusOf(err)
+	if !ok {
+		// only failures to get a response at all are worth another try,
+		// not configuration or decoding errors
+		var netErr net.Error
+		return xerrors.As(err, &netErr)
+	}
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// SuggestedDelay returns how long to wait before sending a request that
+// failed with err again, for callers that queue their own retries:
+// attempt counts the retries before this one, from zero. It is the delay
+// the client waits between its own retries, the Retry-After of the
+// response if there was one and the exponential backoff of the default
+// RetryBackoff otherwise, or the RetryAfter of a *CharacterRateError. It
+// reports false when err is not worth retrying.
+func SuggestedDelay(err error, attempt int) (time.Duration, bool) {
+	return suggestedDelay(err, attempt, defaultRetryBackoff)
+}
+
+// SuggestedDelay is the package function SuggestedDelay with the backoff
+// of c.RetryBackoff.
+func (c *Client) SuggestedDelay(err error, attempt int) (time.Duration, bool) {
+	return suggestedDelay(err, attempt, c.RetryBackoff)
+}
+
+func suggestedDelay(err error, attempt int, backoff time.Duration) (time.Duration, bool) {
+	var rateErr *CharacterRateError
+	if xerrors.As(err, &rateErr) {
+		return rateErr.RetryAfter, rateErr.RetryAfter > 0
+	}
+	if !IsRetryable(err) {
+		return 0, false
+	}
+	var apiErr *APIError
+	if xerrors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter, true
+	}
+	if attempt < 0 {
+		attempt = 0
+	}
+	return exponentialBackoff(backoff, attempt), true
+}
+
+// IsAuthError reports whether err was caused by a missing or invalid API key.
+func IsAuthError(err error) bool {
+	status, ok := statusOf(err)
+	return ok && (status == http.StatusUnauthorized || status == http.StatusForbidden)
+}
+
+// IsQuotaError reports whether err was caused by an exhausted character
+// quota.
+func IsQuotaError(err error) bool {
+	status, ok := statusOf(err)
+	return ok && status == StatusQuotaExceeded
+}
+
+// IsInvalidRequest reports whether DeepL rejected the request itself, for
+// example because of bad parameters or an oversized body.
+func IsInvalidRequest(err error) bool {
+	status, ok := statusOf(err)
+	if !ok {
+		return false
+	}
+	switch status {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusMethodNotAllowed,
+		http.StatusRequestEntityTooLarge, http.StatusRequestURITooLong, http.StatusUnsupportedMediaType:
+		return true
+	}
+	return false
+}
+
+// Codes of ConfigError. A code keeps its meaning across releases.
+const (
+	// ConfigInvalidBaseURL: the base URL of New can't be parsed or reach an
+	// API.
+	ConfigInvalidBaseURL = "CFG001"
+	// ConfigInvalidOption: an option was given an invalid value or
+	// conflicts with another option.
+	ConfigInvalidOption = "CFG002"
+	// ConfigMissingAPIKey: WithNoEnv is set but no API key is given.
+	ConfigMissingAPIKey = "CFG003"
+)
+
+// Errors matching the ConfigErrors of each code, with xerrors.Is or
+// errors.Is.
+var (
+	ErrInvalidBaseURL = xerrors.New("Invalid base URL")
+	ErrInvalidOption  = xerrors.New("Invalid option")
+	ErrMissingAPIKey  = xerrors.New("Missing API key")
+)
+
+var configErrors = map[string]error{
+	ConfigInvalidBaseURL: ErrInvalidBaseURL,
+	ConfigInvalidOption:  ErrInvalidOption,
+	ConfigMissingAPIKey:  ErrMissingAPIKey,
+}
+
+// ConfigError is a misconfiguration found by New, or an invalid option of a
+// translate call.
+type ConfigError struct {
+	Code string
+	Err  error
+}
+
+func (e *ConfigError) Error() string {
+	return e.Err.Error() + " (" + e.Code + ")"
+}
+
+func (e *ConfigError) Is(target error) bool {
+	return target == configErrors[e.Code]
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigErrors is returned by New with every misconfiguration it found, so
+// that they can be fixed at once. Like the errors of errors.Join, each of
+// them matches with errors.Is and errors.As, as well as with xerrors.Is and
+// xerrors.As.
+type ConfigErrors struct {
+	Errors []*ConfigError
+}
+
+func (e *ConfigErrors) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e *ConfigErrors) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Is and As are for xerrors, which doesn't unwrap several errors.
+func (e *ConfigErrors) Is(target error) bool {
+	for _, err := range e.Errors {
+		if xerrors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *ConfigErrors) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if xerrors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// add records err under code unless it is a *ConfigError already.
+func (e *ConfigErrors) add(code string, err error) {
+	var configErr *ConfigError
+	if !xerrors.As(err, &configErr) {
+		configErr = &ConfigError{Code: code, Err: err}
+	}
+	e.Errors = append(e.Errors, configErr)
+}
@@ -0,0 +1,257 @@
+package deepl
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+func TestErrorClassification(t *testing.T) {
+	tt := []struct {
+		statusCode int
+
+		retryable      bool
+		authError      bool
+		quotaError     bool
+		invalidRequest bool
+	}{
+		{statusCode: http.StatusBadRequest, invalidRequest: true},
+		{statusCode: http.StatusUnauthorized, authError: true},
+		{statusCode: http.StatusForbidden, authError: true},
+		{statusCode: http.StatusNotFound, invalidRequest: true},
+		{statusCode: http.StatusMethodNotAllowed, invalidRequest: true},
+		{statusCode: http.StatusRequestEntityTooLarge, invalidRequest: true},
+		{statusCode: http.StatusRequestURITooLong, invalidRequest: true},
+		{statusCode: http.StatusUnsupportedMediaType, invalidRequest: true},
+		{statusCode: http.StatusTooManyRequests, retryable: true},
+		{statusCode: StatusQuotaExceeded, quotaError: true},
+		{statusCode: http.StatusInternalServerError, retryable: true},
+		{statusCode: http.StatusBadGateway, retryable: true},
+		{statusCode: http.StatusServiceUnavailable, retryable: true},
+		{statusCode: http.StatusGatewayTimeout, retryable: true},
+		{statusCode: 529, retryable: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(http.StatusText(tc.statusCode), func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.statusCode)
+				w.Write([]byte(`{"message":"failed"}`))
+			}))
+			defer ts.Close()
+
+			cli, err := New(ts.URL, nil, WithAPIKey(testAPIKey))
+			if err != nil {
+				t.Fatal(err)
+			}
+			cli.MaxRetries = 0
+
+			err = cli.Do(context.Background(), http.MethodPost, "/v2/translate", nil, nil)
+			var apiErr *APIError
+			if !xerrors.As(err, &apiErr) || apiErr.StatusCode != tc.statusCode {
+				t.Fatalf("expected *APIError with status %d, got %v", tc.statusCode, err)
+			}
+			if got := IsRetryable(err); got != tc.retryable {
+				t.Errorf("IsRetryable = %v, expected %v", got, tc.retryable)
+			}
+			if got := IsAuthError(err); got != tc.authError {
+				t.Errorf("IsAuthError = %v, expected %v", got, tc.authError)
+			}
+			if got := IsQuotaError(err); got != tc.quotaError {
+				t.Errorf("IsQuotaError = %v, expected %v", got, tc.quotaError)
+			}
+			if got := IsInvalidRequest(err); got != tc.invalidRequest {
+				t.Errorf("IsInvalidRequest = %v, expected %v", got, tc.invalidRequest)
+			}
+		})
+	}
+}
+
+func TestErrorClassification_NoResponse(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close()
+
+	cli, err := New(ts.URL, nil, WithAPIKey(testAPIKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.MaxRetries = 0
+
+	err = cli.Do(context.Background(), http.MethodPost, "/v2/translate", nil, nil)
+	if err == nil || !IsRetryable(err) {
+		t.Fatalf("expected retryable transport error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = cli.Do(ctx, http.MethodPost, "/v2/translate", nil, nil)
+	if err == nil || IsRetryable(err) {
+		t.Fatalf("expected canceled request not to be retryable, got %v", err)
+	}
+	if IsRetryable(nil) || IsAuthError(nil) || IsQuotaError(nil) || IsInvalidRequest(nil) {
+		t.Fatal("nil error must not be classified")
+	}
+}
+
+func TestSuggestedDelay(t *testing.T) {
+	cli := &Client{RetryBackoff: 10 * time.Millisecond}
+	apiError := func(status int, retryAfter string) error {
+		t.Helper()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+		}))
+		defer server.Close()
+		failing, err := New(server.URL, nil, WithAPIKey(testAPIKey), WithRetries(0, time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = failing.GetAccountStatus(context.Background()); err == nil {
+			t.Fatal("expected error")
+		}
+		return err
+	}
+	tt := []struct {
+		name    string
+		err     error
+		attempt int
+
+		expectedDelay  time.Duration
+		expectedClient time.Duration
+		expectedOK     bool
+	}{
+		{name: "429 with Retry-After", err: apiError(http.StatusTooManyRequests, "7"), attempt: 3, expectedDelay: 7 * time.Second, expectedClient: 7 * time.Second, expectedOK: true},
+		{name: "503 without Retry-After", err: apiError(http.StatusServiceUnavailable, ""), attempt: 2, expectedDelay: 4 * defaultRetryBackoff, expectedClient: 40 * time.Millisecond, expectedOK: true},
+		{name: "attempt 40", err: apiError(http.StatusTooManyRequests, ""), attempt: 40, expectedDelay: maxRetryBackoff, expectedClient: maxRetryBackoff, expectedOK: true},
+		{name: "attempt 63", err: apiError(http.StatusTooManyRequests, ""), attempt: 63, expectedDelay: maxRetryBackoff, expectedClient: maxRetryBackoff, expectedOK: true},
+		{name: "attempt 1000", err: apiError(http.StatusServiceUnavailable, ""), attempt: 1000, expectedDelay: maxRetryBackoff, expectedClient: maxRetryBackoff, expectedOK: true},
+		{name: "400", err: apiError(http.StatusBadRequest, "7")},
+		{name: "quota", err: apiError(StatusQuotaExceeded, "")},
+		{name: "character rate", err: &CharacterRateError{Characters: 10, RetryAfter: 3 * time.Second}, expectedDelay: 3 * time.Second, expectedClient: 3 * time.Second, expectedOK: true},
+		{name: "character rate without retry", err: &CharacterRateError{Characters: 10}},
+		{name: "canceled", err: context.Canceled},
+		{name: "nil", err: nil},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if delay, ok := SuggestedDelay(tc.err, tc.attempt); delay != tc.expectedDelay || ok != tc.expectedOK {
+				t.Errorf("SuggestedDelay = %v, %v, expected %v, %v", delay, ok, tc.expectedDelay, tc.expectedOK)
+			}
+			if delay, ok := cli.SuggestedDelay(tc.err, tc.attempt); delay != tc.expectedClient || ok != tc.expectedOK {
+				t.Errorf("Client.SuggestedDelay = %v, %v, expected %v, %v", delay, ok, tc.expectedClient, tc.expectedOK)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidBaseURL(t *testing.T) {
+	for _, rawURL := range []string{"htps://api.deepl.com", "api.deepl.com", "https://", "://api.deepl.com"} {
+		if _, err := New(rawURL, nil); err == nil {
+			t.Errorf("expected New to fail for %q", rawURL)
+		}
+	}
+	if _, err := New("https://api.deepl.com", nil, WithFallbackBaseURL("api-free.deepl.com")); err == nil {
+		t.Error("expected WithFallbackBaseURL to fail for a URL without scheme")
+	}
+}
+
+func TestNew_ConfigErrors(t *testing.T) {
+	_, err := New("htps://api.deepl.com", nil, WithNoEnv(), WithMicroBatching(0, 10))
+	var configErrs *ConfigErrors
+	if !xerrors.As(err, &configErrs) || len(configErrs.Errors) != 3 {
+		t.Fatalf("expected 3 *ConfigErrors, got %v", err)
+	}
+	tt := []struct {
+		code     string
+		expected error
+	}{
+		{code: ConfigInvalidBaseURL, expected: ErrInvalidBaseURL},
+		{code: ConfigInvalidOption, expected: ErrInvalidOption},
+		{code: ConfigMissingAPIKey, expected: ErrMissingAPIKey},
+	}
+	for i, tc := range tt {
+		if got := configErrs.Errors[i]; got.Code != tc.code || !strings.HasSuffix(got.Error(), " ("+tc.code+")") {
+			t.Errorf("error %d = %v, expected code %s", i, got, tc.code)
+		}
+		if !errors.Is(err, tc.expected) || !xerrors.Is(err, tc.expected) {
+			t.Errorf("%v doesn't match %v", err, tc.expected)
+		}
+	}
+	if lines := strings.Split(err.Error(), "\n"); len(lines) != 3 {
+		t.Errorf("expected one line per error, got %q", err.Error())
+	}
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || configErr.Code != ConfigInvalidBaseURL {
+		t.Errorf("expected the first *ConfigError, got %v", configErr)
+	}
+
+	_, err = New("https://api.deepl.com", nil, WithAPIKey(testAPIKey), WithNoEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New("https://api.deepl.com", nil, WithAPIKey(testAPIKey), WithMicroBatching(0, 10)); xerrors.Is(err, ErrInvalidBaseURL) || !xerrors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected only ErrInvalidOption, got %v", err)
+	}
+}
+
+func TestClient_EndpointUnreachable(t *testing.T) {
+	var dials int
+	cli, err := New("https://api.deepl.con", nil, WithAPIKey(testAPIKey), WithDialer(DialerFunc(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials++
+		return nil, &net.DNSError{Err: "no such host", Name: "api.deepl.con", IsNotFound: true}
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cli.GetAccountStatus(context.Background())
+	if !xerrors.Is(err, ErrEndpointUnreachable) {
+		t.Fatalf("expected ErrEndpointUnreachable, got %v", err)
+	}
+	var unreachable *EndpointUnreachableError
+	if !xerrors.As(err, &unreachable) || unreachable.Host != "api.deepl.con" {
+		t.Fatalf("expected host api.deepl.con, got %v", err)
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "api-free.deepl.com") || strings.Contains(msg, testAPIKey) {
+		t.Fatalf("unexpected error message %q", msg)
+	}
+	if IsRetryable(err) || dials != 1 {
+		t.Fatalf("NXDOMAIN must not be retried, dialed %d times", dials)
+	}
+}
+
+func TestClient_RequestTooLarge(t *testing.T) {
+	var size int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		size = r.ContentLength
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	}))
+	defer ts.Close()
+	cli, err := New(ts.URL, nil, WithAPIKey("test"), WithRequestEncoding(RequestEncodingJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts := []string{"short", strings.Repeat("long ", 20), "medium text"}
+	_, err = cli.Translate(context.Background(), TranslateRequest{Text: texts, TargetLang: "DE"})
+	var tooLarge *RequestTooLargeError
+	if !xerrors.As(err, &tooLarge) || !xerrors.Is(err, ErrRequestTooLarge) {
+		t.Fatalf("expected *RequestTooLargeError, got %v", err)
+	}
+	if int64(tooLarge.Bytes) != size || tooLarge.Texts != 3 || tooLarge.Largest != 1 || tooLarge.LargestBytes != 100 {
+		t.Fatalf("unexpected error %+v for a body of %d bytes", tooLarge, size)
+	}
+	if status, ok := statusOf(err); !ok || status != http.StatusRequestEntityTooLarge || !IsInvalidRequest(err) {
+		t.Fatalf("413 not kept in %v", err)
+	}
+}
@@ -1,8 +1,9 @@
 module github.com/DaikiYamakawa/deepl-go
 
-go 1.14
+go 1.18
 
 require (
 	golang.org/x/net v0.0.0-20200625001655-4c5254603344
+	golang.org/x/text v0.3.0
 	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543
 )
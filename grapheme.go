@@ -0,0 +1,210 @@
+package deepl
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// graphemeClass is the Grapheme_Cluster_Break property of a rune, as far as
+// the rules of UAX #29 below need it. Prepend is treated as Other.
+type graphemeClass int
+
+const (
+	gcOther graphemeClass = iota
+	gcCR
+	gcLF
+	gcControl
+	gcExtend
+	gcZWJ
+	gcRegionalIndicator
+	gcSpacingMark
+	gcL
+	gcV
+	gcT
+	gcLV
+	gcLVT
+	gcPictographic
+)
+
+// extendedPictographic approximates the Extended_Pictographic property of
+// Unicode emoji data by the blocks emoji are allocated from.
+var extendedPictographic = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0xA9, Hi: 0xA9, Stride: 1},
+		{Lo: 0xAE, Hi: 0xAE, Stride: 1},
+		{Lo: 0x203C, Hi: 0x203C, Stride: 1},
+		{Lo: 0x2049, Hi: 0x2049, Stride: 1},
+		{Lo: 0x2122, Hi: 0x2122, Stride: 1},
+		{Lo: 0x2139, Hi: 0x2139, Stride: 1},
+		{Lo: 0x2194, Hi: 0x2199, Stride: 1},
+		{Lo: 0x21A9, Hi: 0x21AA, Stride: 1},
+		{Lo: 0x231A, Hi: 0x231B, Stride: 1},
+		{Lo: 0x2328, Hi: 0x2328, Stride: 1},
+		{Lo: 0x23CF, Hi: 0x23CF, Stride: 1},
+		{Lo: 0x23E9, Hi: 0x23F3, Stride: 1},
+		{Lo: 0x23F8, Hi: 0x23FA, Stride: 1},
+		{Lo: 0x24C2, Hi: 0x24C2, Stride: 1},
+		{Lo: 0x25AA, Hi: 0x25AB, Stride: 1},
+		{Lo: 0x25B6, Hi: 0x25B6, Stride: 1},
+		{Lo: 0x25C0, Hi: 0x25C0, Stride: 1},
+		{Lo: 0x25FB, Hi: 0x25FE, Stride: 1},
+		{Lo: 0x2600, Hi: 0x27BF, Stride: 1},
+		{Lo: 0x2934, Hi: 0x2935, Stride: 1},
+		{Lo: 0x2B05, Hi: 0x2B07, Stride: 1},
+		{Lo: 0x2B1B, Hi: 0x2B1C, Stride: 1},
+		{Lo: 0x2B50, Hi: 0x2B50, Stride: 1},
+		{Lo: 0x2B55, Hi: 0x2B55, Stride: 1},
+		{Lo: 0x3030, Hi: 0x3030, Stride: 1},
+		{Lo: 0x303D, Hi: 0x303D, Stride: 1},
+		{Lo: 0x3297, Hi: 0x3297, Stride: 1},
+		{Lo: 0x3299, Hi: 0x3299, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1F000, Hi: 0x1F0FF, Stride: 1},
+		{Lo: 0x1F10D, Hi: 0x1F10F, Stride: 1},
+		{Lo: 0x1F12F, Hi: 0x1F12F, Stride: 1},
+		{Lo: 0x1F16C, Hi: 0x1F171, Stride: 1},
+		{Lo: 0x1F17E, Hi: 0x1F17F, Stride: 1},
+		{Lo: 0x1F18E, Hi: 0x1F18E, Stride: 1},
+		{Lo: 0x1F191, Hi: 0x1F19A, Stride: 1},
+		{Lo: 0x1F1AD, Hi: 0x1F1E5, Stride: 1},
+		{Lo: 0x1F201, Hi: 0x1F3FA, Stride: 1},
+		{Lo: 0x1F400, Hi: 0x1F53D, Stride: 1},
+		{Lo: 0x1F546, Hi: 0x1F64F, Stride: 1},
+		{Lo: 0x1F680, Hi: 0x1F6FF, Stride: 1},
+		{Lo: 0x1F774, Hi: 0x1F77F, Stride: 1},
+		{Lo: 0x1F7D5, Hi: 0x1F7FF, Stride: 1},
+		{Lo: 0x1F80C, Hi: 0x1F80F, Stride: 1},
+		{Lo: 0x1F848, Hi: 0x1F84F, Stride: 1},
+		{Lo: 0x1F85A, Hi: 0x1F85F, Stride: 1},
+		{Lo: 0x1F888, Hi: 0x1F88F, Stride: 1},
+		{Lo: 0x1F8AE, Hi: 0x1F8FF, Stride: 1},
+		{Lo: 0x1F90C, Hi: 0x1F93A, Stride: 1},
+		{Lo: 0x1F93C, Hi: 0x1F945, Stride: 1},
+		{Lo: 0x1F947, Hi: 0x1FAFF, Stride: 1},
+		{Lo: 0x1FC00, Hi: 0x1FFFD, Stride: 1},
+	},
+}
+
+func classifyGrapheme(r rune) graphemeClass {
+	switch {
+	case r == '\r':
+		return gcCR
+	case r == '\n':
+		return gcLF
+	case r == 0x200D:
+		return gcZWJ
+	case r == 0x200C, r >= 0x1F3FB && r <= 0x1F3FF, r >= 0xE0020 && r <= 0xE007F, r == 0xFF9E, r == 0xFF9F:
+		// ZWNJ, emoji modifiers, tag characters and halfwidth sound marks
+		return gcExtend
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		return gcRegionalIndicator
+	case r >= 0x1100 && r <= 0x115F, r >= 0xA960 && r <= 0xA97C:
+		return gcL
+	case r >= 0x1160 && r <= 0x11A7, r >= 0xD7B0 && r <= 0xD7C6:
+		return gcV
+	case r >= 0x11A8 && r <= 0x11FF, r >= 0xD7CB && r <= 0xD7FB:
+		return gcT
+	case r >= 0xAC00 && r <= 0xD7A3:
+		if (r-0xAC00)%28 == 0 {
+			return gcLV
+		}
+		return gcLVT
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return gcExtend
+	case unicode.Is(unicode.Mc, r):
+		return gcSpacingMark
+	case unicode.In(r, unicode.Cc, unicode.Cf, unicode.Zl, unicode.Zp):
+		return gcControl
+	case unicode.Is(extendedPictographic, r):
+		return gcPictographic
+	}
+	return gcOther
+}
+
+// graphemeLen returns the length in bytes of the extended grapheme cluster
+// at the start of s, following the rules of UAX #29.
+func graphemeLen(s string) int {
+	if s == "" {
+		return 0
+	}
+	r, i := utf8.DecodeRuneInString(s)
+	prev := classifyGrapheme(r)
+	// regional indicators ending at prev, for the pairing of flags
+	ri := 0
+	if prev == gcRegionalIndicator {
+		ri = 1
+	}
+	// pict is set when an emoji followed by Extend* ends at prev and
+	// pictZWJ when a ZWJ follows such a sequence
+	pict := prev == gcPictographic
+	pictZWJ := false
+
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		cur := classifyGrapheme(r)
+		if !graphemeJoins(prev, cur, ri, pictZWJ) {
+			break
+		}
+		if cur == gcRegionalIndicator {
+			ri++
+		} else {
+			ri = 0
+		}
+		pictZWJ = pict && cur == gcZWJ
+		pict = cur == gcPictographic || (pict && cur == gcExtend)
+		prev = cur
+		i += size
+	}
+	return i
+}
+
+// graphemeJoins reports whether there is no cluster boundary between prev
+// and cur.
+func graphemeJoins(prev, cur graphemeClass, ri int, pictZWJ bool) bool {
+	switch {
+	case prev == gcCR && cur == gcLF:
+		return true
+	case prev == gcCR, prev == gcLF, prev == gcControl, cur == gcCR, cur == gcLF, cur == gcControl:
+		return false
+	case prev == gcL && (cur == gcL || cur == gcV || cur == gcLV || cur == gcLVT):
+		return true
+	case (prev == gcLV || prev == gcV) && (cur == gcV || cur == gcT):
+		return true
+	case (prev == gcLVT || prev == gcT) && cur == gcT:
+		return true
+	case cur == gcExtend, cur == gcZWJ, cur == gcSpacingMark:
+		return true
+	case prev == gcZWJ && cur == gcPictographic:
+		return pictZWJ
+	case prev == gcRegionalIndicator && cur == gcRegionalIndicator:
+		return ri%2 == 1
+	}
+	return false
+}
+
+// TruncateGraphemes returns the first n user-perceived characters of s, so
+// that emoji sequences, flags, Hangul syllables and letters with combining
+// marks are never split.
+func TruncateGraphemes(s string, n int) string {
+	i := 0
+	for ; n > 0 && i < len(s); n-- {
+		i += graphemeLen(s[i:])
+	}
+	return s[:i]
+}
+
+// truncateGraphemes returns the longest prefix of s made of whole grapheme
+// clusters with at most maxRunes runes.
+func truncateGraphemes(s string, maxRunes int) string {
+	i, runes := 0, 0
+	for i < len(s) {
+		n := graphemeLen(s[i:])
+		runes += utf8.RuneCountInString(s[i : i+n])
+		if runes > maxRunes {
+			break
+		}
+		i += n
+	}
+	return s[:i]
+}
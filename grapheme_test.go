@@ -0,0 +1,58 @@
+package deepl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncateGraphemes(t *testing.T) {
+	family := "\U0001F468‍\U0001F469‍\U0001F467‍\U0001F466"
+	tt := []struct {
+		name string
+
+		s string
+		n int
+
+		expected string
+	}{
+		{name: "ascii", s: "Hello", n: 3, expected: "Hel"},
+		{name: "family emoji", s: family + family, n: 1, expected: family},
+		{name: "skin tone", s: "\U0001F44D\U0001F3FD!", n: 1, expected: "\U0001F44D\U0001F3FD"},
+		{name: "flags", s: "\U0001F1E9\U0001F1EA\U0001F1EB\U0001F1F7\U0001F1EF", n: 2, expected: "\U0001F1E9\U0001F1EA\U0001F1EB\U0001F1F7"},
+		{name: "subdivision flag", s: "\U0001F3F4\U000E0067\U000E0062\U000E0065\U000E006E\U000E0067\U000E007Fx", n: 1, expected: "\U0001F3F4\U000E0067\U000E0062\U000E0065\U000E006E\U000E0067\U000E007F"},
+		{name: "hangul jamo", s: "한글", n: 1, expected: "한"},
+		{name: "hangul syllable with trailing jamo", s: "한ᆫ글", n: 1, expected: "한ᆫ"},
+		{name: "combining diacritics", s: "ẹ́lève", n: 2, expected: "ẹ́l"},
+		{name: "devanagari spacing mark", s: "कित", n: 1, expected: "कि"},
+		{name: "crlf", s: "a\r\nb", n: 2, expected: "a\r\n"},
+		{name: "zwj without emoji", s: "a‍\U0001F466", n: 1, expected: "a‍"},
+		{name: "keycap", s: "1️⃣2", n: 1, expected: "1️⃣"},
+		{name: "more than s", s: "ab", n: 5, expected: "ab"},
+		{name: "zero", s: "ab", n: 0, expected: ""},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := TruncateGraphemes(tc.s, tc.n); got != tc.expected {
+				t.Fatalf("got %+q, expected %+q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestTruncationSitesKeepGraphemes(t *testing.T) {
+	family := "\U0001F468‍\U0001F469‍\U0001F467"
+	// the family needs five runes but only three are left before the
+	// ellipsis
+	text := strings.Repeat("a", maxErrorTextRunes-4) + family + "tail"
+	if got := errorText(text, false); got != strings.Repeat("a", maxErrorTextRunes-4)+ellipsis {
+		t.Fatalf("unexpected error text %+q", got)
+	}
+	if got := truncateWithEllipsis("ab"+family+"cd", 5); got != "ab"+ellipsis {
+		t.Fatalf("unexpected truncation %+q", got)
+	}
+	// e and the combining acute are kept together
+	if got := truncateWithEllipsis("Cafe\u0301 ok", 5); got != "Caf"+ellipsis {
+		t.Fatalf("unexpected truncation %+q", got)
+	}
+}
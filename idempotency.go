@@ -0,0 +1,69 @@
+package deepl
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+
+	"golang.org/x/xerrors"
+)
+
+// IdempotencyKeyHeader carries the idempotency key of a request.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WithIdempotencyKeys sends a random Idempotency-Key header with every
+// call. The key stays the same across retries and the fallback URL, so a
+// caching proxy can drop duplicates, and is returned in
+// Metadata.IdempotencyKey.
+func WithIdempotencyKeys() Option {
+	return func(c *Client) error {
+		c.IdempotencyKeys = true
+		return nil
+	}
+}
+
+// WithIdempotencyKey sends key as the Idempotency-Key of one translate call,
+// with or without WithIdempotencyKeys. The resend of WithFormalityRetry
+// carries key+"-formality".
+func WithIdempotencyKey(key string) TranslateOption {
+	return func(c *translateCall) { c.idempotencyKey = key }
+}
+
+type idempotencyKeyCtx struct{}
+
+func contextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+// withResendKey derives the per-call key of ctx, if any, for a resend with a
+// different body, so that the server doesn't replay the first response.
+// Keys generated by the client differ per request anyway.
+func withResendKey(ctx context.Context, suffix string) context.Context {
+	if key, ok := ctx.Value(idempotencyKeyCtx{}).(string); ok && key != "" {
+		return contextWithIdempotencyKey(ctx, key+"-"+suffix)
+	}
+	return ctx
+}
+
+// idempotencyKey returns the key of the call made with ctx: the per-call
+// key, a new key if the client sends them, or "".
+func (c *Client) idempotencyKey(ctx context.Context) (string, error) {
+	if key, ok := ctx.Value(idempotencyKeyCtx{}).(string); ok && key != "" {
+		return key, nil
+	}
+	if !c.IdempotencyKeys {
+		return "", nil
+	}
+	return newIdempotencyKey()
+}
+
+// newIdempotencyKey returns a random version 4 UUID.
+func newIdempotencyKey() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", xerrors.Errorf("Failed to generate idempotency key: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,114 @@
+package deepl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestClient_IdempotencyKeys(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		keys = append(keys, req.Header.Get(IdempotencyKeyHeader))
+		// every first attempt fails so that retries are covered
+		if len(keys)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(TranslateResult{Translations: []Translation{{Text: "Hallo"}}})
+	}))
+	defer server.Close()
+
+	cli, err := New(server.URL, nil, WithAPIKey(testAPIKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.MaxRetries = 1
+	cli.RetryBackoff = time.Millisecond
+	req := TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"}
+
+	res, err := cli.Translate(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys[0] != "" || keys[1] != "" || res.Metadata.IdempotencyKey != "" {
+		t.Fatalf("idempotency key sent without the option: %q", keys)
+	}
+
+	if err := WithIdempotencyKeys()(cli); err != nil {
+		t.Fatal(err)
+	}
+	first, err := cli.Translate(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := cli.Translate(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !uuidPattern.MatchString(keys[2]) || keys[2] != keys[3] || keys[4] != keys[5] {
+		t.Fatalf("key not stable across retries: %q", keys)
+	}
+	if keys[2] == keys[4] {
+		t.Fatalf("key reused across calls: %q", keys)
+	}
+	if first.Metadata.IdempotencyKey != keys[2] || second.Metadata.IdempotencyKey != keys[4] {
+		t.Fatalf("metadata keys %q and %q don't match sent keys %q", first.Metadata.IdempotencyKey, second.Metadata.IdempotencyKey, keys)
+	}
+
+	res, err = cli.Translate(context.Background(), req, WithIdempotencyKey("order-42"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys[6] != "order-42" || keys[7] != "order-42" || res.Metadata.IdempotencyKey != "order-42" {
+		t.Fatalf("per-call key not used: %q", keys)
+	}
+}
+
+func TestClient_IdempotencyKeyFormalityRetry(t *testing.T) {
+	formalityErr, err := ioutil.ReadFile("testdata/Translate/unsupported-formality-body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		keys = append(keys, req.Header.Get(IdempotencyKeyHeader))
+		if len(keys)%2 == 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(formalityErr)
+			return
+		}
+		json.NewEncoder(w).Encode(TranslateResult{Translations: []Translation{{Text: "Hallo"}}})
+	}))
+	defer server.Close()
+
+	cli, err := New(server.URL, nil, WithAPIKey(testAPIKey), WithFormalityRetry(), WithIdempotencyKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := TranslateRequest{Text: []string{"Hello"}, TargetLang: "JA", Formality: FormalityMore}
+
+	res, err := cli.Translate(context.Background(), req, WithIdempotencyKey("order-42"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys[0] != "order-42" || keys[1] != "order-42-formality" || res.Metadata.IdempotencyKey != keys[1] {
+		t.Fatalf("resend with a different body reused the key: %q", keys)
+	}
+
+	if _, err := cli.Translate(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if !uuidPattern.MatchString(keys[2]) || !uuidPattern.MatchString(keys[3]) || keys[2] == keys[3] {
+		t.Fatalf("resend with a different body reused the key: %q", keys)
+	}
+}
@@ -0,0 +1,230 @@
+package deepl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// AuthKeyPlaceholder replaces the API key in requests built by
+// BuildTranslateRequest.
+const AuthKeyPlaceholder = "REDACTED"
+
+// ClientConfig holds client settings. BuildTranslateRequest uses BaseURL
+// and RequestEncoding; Client.Config fills in the rest.
+type ClientConfig struct {
+	BaseURL         string
+	FallbackBaseURL string
+	RequestEncoding RequestEncoding
+
+	// APIKey is redacted except for the ":fx" suffix of Free keys, with its
+	// source in parentheses, "provider" or "unset".
+	APIKey string
+	// LegacyAuthInQuery is set when the key is sent as a query parameter.
+	LegacyAuthInQuery bool
+	// Pseudo is set when translations come from a PseudoTranslator.
+	Pseudo bool
+
+	Timeout time.Duration
+	// RetryPolicy is "exponential" or "none".
+	RetryPolicy  string
+	MaxRetries   int
+	RetryBackoff time.Duration
+
+	RequestsPerSecond   float64
+	RateBurst           int
+	MaxConcurrency      int
+	CharacterRate       int
+	CharacterRateWindow time.Duration
+
+	BatchMaxWait  time.Duration
+	BatchMaxItems int
+
+	// TranslationCache and TranslationMemory are the type names of the
+	// configured implementations or empty.
+	TranslationCache  string
+	TranslationMemory string
+
+	// DefaultSourceLang and DefaultTargetLang are set by
+	// WithDefaultLanguages, CallDefaults by WithCallDefaults.
+	DefaultSourceLang string
+	DefaultTargetLang string
+	CallDefaults      ResolvedOptions
+
+	VariantPreference map[string]string
+	SoftFail          bool
+}
+
+// Config returns a snapshot of the effective settings of c. It is a copy,
+// so changing it doesn't affect c.
+func (c *Client) Config() ClientConfig {
+	// the default languages have fields of their own
+	defaults := c.callDefaults.req
+	defaults.SourceLang, defaults.TargetLang = "", ""
+	config := ClientConfig{
+		RequestEncoding:   c.RequestEncoding,
+		APIKey:            c.explainAPIKey(),
+		LegacyAuthInQuery: c.LegacyAuthInQuery,
+		Pseudo:            c.pseudo != nil,
+		RetryPolicy:       "none",
+		MaxRetries:        c.MaxRetries,
+		RetryBackoff:      c.backoff(0),
+		RequestsPerSecond: c.RequestsPerSecond,
+		RateBurst:         c.RateBurst,
+		MaxConcurrency:    c.MaxConcurrency,
+		TranslationCache:  typeName(c.TranslationCache),
+		TranslationMemory: typeName(c.TranslationMemory),
+		DefaultSourceLang: c.callDefaults.req.SourceLang,
+		DefaultTargetLang: c.callDefaults.req.TargetLang,
+		CallDefaults:      ResolvedOptions{Request: defaults, Timeout: c.callDefaults.timeout, SoftFail: c.callDefaults.softFail},
+		SoftFail:          c.SoftFail,
+	}
+	if c.BaseURL != nil {
+		config.BaseURL = c.BaseURL.String()
+	}
+	if c.FallbackBaseURL != nil {
+		config.FallbackBaseURL = c.FallbackBaseURL.String()
+	}
+	if c.HTTPClient != nil {
+		config.Timeout = c.HTTPClient.Timeout
+	}
+	if c.MaxRetries > 0 {
+		config.RetryPolicy = "exponential"
+	}
+	if w := c.characterRate; w != nil {
+		config.CharacterRate, config.CharacterRateWindow = w.limit, w.window
+	}
+	if c.BatchMaxWait > 0 && c.BatchMaxItems > 0 {
+		config.BatchMaxWait, config.BatchMaxItems = c.BatchMaxWait, c.BatchMaxItems
+	}
+	if len(c.VariantPreference) > 0 {
+		config.VariantPreference = make(map[string]string, len(c.VariantPreference))
+		for k, v := range c.VariantPreference {
+			config.VariantPreference[k] = v
+		}
+	}
+	return config
+}
+
+// typeName returns the type of v, or "" when v is nil or a nil pointer,
+// map, slice, func or channel.
+func typeName(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Interface, reflect.Chan:
+		if rv.IsNil() {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// BuildTranslateRequest returns the request Translate would send for req
+// with config, without sending it, so that tools can inspect its headers
+// and body. The API key is AuthKeyPlaceholder. Client-side
+// processing such as ProtectedPatterns or newline normalization is not
+// applied.
+func BuildTranslateRequest(req TranslateRequest, config ClientConfig) (*http.Request, error) {
+	baseURL, err := parseBaseURL(config.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.validateTags(); err != nil {
+		return nil, err
+	}
+	if err := req.validateEnums(); err != nil {
+		return nil, &ConfigError{Code: ConfigInvalidOption, Err: err}
+	}
+
+	c := &Client{BaseURL: baseURL, RequestEncoding: config.RequestEncoding, LegacyAuthInQuery: config.LegacyAuthInQuery}
+	r, err := c.newAPIRequest(http.MethodPost, "/v2/translate", c.translateBody(&req, false), AuthKeyPlaceholder)
+	if err != nil {
+		return nil, err
+	}
+	return r.httpRequest(context.Background(), resolveEndpoint(baseURL, "/v2/translate"))
+}
+
+// ExplainConfig returns the settings of Config, one "Name: value" per line,
+// for logging at startup.
+func (c *Client) ExplainConfig() string {
+	config := c.Config()
+	var b strings.Builder
+	line := func(name string, value interface{}) {
+		fmt.Fprintf(&b, "%s: %v\n", name, value)
+	}
+	optional := func(v string) string {
+		if v == "" {
+			return "none"
+		}
+		return v
+	}
+
+	line("BaseURL", config.BaseURL)
+	if config.FallbackBaseURL != "" {
+		line("FallbackBaseURL", config.FallbackBaseURL)
+	}
+	line("APIKey", config.APIKey)
+	if config.Pseudo {
+		line("Translator", "pseudo")
+	}
+	timeout := "none"
+	if config.Timeout > 0 {
+		timeout = config.Timeout.String()
+	}
+	line("Timeout", timeout)
+	line("MaxRetries", config.MaxRetries)
+	line("RetryBackoff", config.RetryBackoff)
+	line("RequestsPerSecond", config.RequestsPerSecond)
+	line("RateBurst", config.RateBurst)
+	line("MaxConcurrency", config.MaxConcurrency)
+	if config.CharacterRate > 0 {
+		line("CharacterRate", fmt.Sprintf("%d per %v", config.CharacterRate, config.CharacterRateWindow))
+	}
+	if config.BatchMaxItems > 0 {
+		line("MicroBatching", fmt.Sprintf("%d texts or %v", config.BatchMaxItems, config.BatchMaxWait))
+	}
+	encoding := fmt.Sprint(int(config.RequestEncoding))
+	if names := []string{"auto", "json", "form"}; config.RequestEncoding >= 0 && int(config.RequestEncoding) < len(names) {
+		encoding = names[config.RequestEncoding]
+	}
+	line("RequestEncoding", encoding)
+	line("Wire", c.WireFeatures())
+	line("TranslationCache", optional(config.TranslationCache))
+	line("TranslationMemory", optional(config.TranslationMemory))
+	if config.DefaultSourceLang != "" || config.DefaultTargetLang != "" {
+		line("DefaultLanguages", fmt.Sprintf("%s -> %s", optional(config.DefaultSourceLang), optional(config.DefaultTargetLang)))
+	}
+	defaults := config.CallDefaults.Request.values()
+	defaults.Del("text")
+	defaults.Del("target_lang")
+	if len(defaults) > 0 || config.CallDefaults.Timeout > 0 || config.CallDefaults.SoftFail {
+		line("CallDefaults", fmt.Sprintf("%s timeout=%v soft_fail=%t", defaults.Encode(), config.CallDefaults.Timeout, config.CallDefaults.SoftFail))
+	}
+	line("SoftFail", config.SoftFail)
+	return b.String()
+}
+
+func (c *Client) explainAPIKey() string {
+	var key, source string
+	switch {
+	case c.APIKey != "":
+		key, source = c.APIKey, "option"
+	case c.APIKeyProvider != nil:
+		return "provider"
+	default:
+		var err error
+		if key, err = c.envAPIKey(); err != nil {
+			return "unset"
+		}
+		source = "DEEPL_API_KEY"
+	}
+	if DetectPlan(key) == PlanFree {
+		return AuthKeyPlaceholder + ":fx (" + source + ")"
+	}
+	return AuthKeyPlaceholder + " (" + source + ")"
+}
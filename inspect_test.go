@@ -0,0 +1,184 @@
+package deepl
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestBuildTranslateRequest(t *testing.T) {
+	type captured struct {
+		method, url, contentType, userAgent, body string
+	}
+	capture := func(r *http.Request, host string) captured {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u := *r.URL
+		u.Scheme, u.Host = "http", host
+		return captured{r.Method, u.String(), r.Header.Get("Content-Type"), r.Header.Get("User-Agent"), string(body)}
+	}
+
+	var sent captured
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = capture(r, r.Host)
+		w.Write([]byte(`{"translations":[{"text":"Hallo"}]}`))
+	}))
+	defer server.Close()
+
+	req := TranslateRequest{Text: []string{"Hello & <b>bye</b>"}, TargetLang: "de", TagHandling: TagHandlingXML, IgnoreTags: []string{"code"}}
+	for _, encoding := range []RequestEncoding{RequestEncodingJSON, RequestEncodingForm} {
+		cli, err := New(server.URL+"/proxy", nil, WithAPIKey(AuthKeyPlaceholder), WithRequestEncoding(encoding))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cli.Translate(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+
+		built, err := BuildTranslateRequest(req, ClientConfig{BaseURL: server.URL + "/proxy", RequestEncoding: encoding})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := capture(built, built.URL.Host); got != sent {
+			t.Fatalf("built request differs from the sent one.\nbuilt=%+v\nsent =%+v", got, sent)
+		}
+		if got := built.Header.Get("Authorization"); got != "DeepL-Auth-Key "+AuthKeyPlaceholder {
+			t.Fatalf("auth key placeholder missing. got=%s", got)
+		}
+	}
+
+	legacy, err := BuildTranslateRequest(req, ClientConfig{BaseURL: server.URL, LegacyAuthInQuery: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if legacy.URL.Query().Get("auth_key") != AuthKeyPlaceholder || legacy.Header.Get("Authorization") != "" {
+		t.Fatalf("auth key not in the query. got=%s %v", legacy.URL, legacy.Header)
+	}
+
+	if _, err := BuildTranslateRequest(req, ClientConfig{BaseURL: "api.deepl.com"}); err == nil {
+		t.Fatal("expected error for a base URL without scheme")
+	}
+}
+
+func TestClient_Config(t *testing.T) {
+	cli, err := New("https://proxy.example.com/deepl", nil,
+		WithAPIKey("secret:fx"),
+		WithPlanDefaults(PlanAuto),
+		WithFallbackBaseURL("https://api-free.deepl.com"),
+		WithRequestEncoding(RequestEncodingForm),
+		WithTimeout(5*time.Second),
+		WithCharacterRate(1000, time.Hour),
+		WithTranslationMemory(NewMapTranslationMemory()),
+		WithVariantPreference(map[string]string{"en": "en-gb"}),
+		WithDefaultLanguages("de", "en"),
+		WithCallDefaults(WithFormality(FormalityLess), WithCallTimeout(time.Second)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := ClientConfig{
+		BaseURL:             "https://proxy.example.com/deepl",
+		FallbackBaseURL:     "https://api-free.deepl.com",
+		RequestEncoding:     RequestEncodingForm,
+		APIKey:              "REDACTED:fx (option)",
+		Timeout:             5 * time.Second,
+		RetryPolicy:         "exponential",
+		MaxRetries:          PlanFreeDefaults.MaxRetries,
+		RetryBackoff:        PlanFreeDefaults.RetryBackoff,
+		RequestsPerSecond:   PlanFreeDefaults.RequestsPerSecond,
+		RateBurst:           PlanFreeDefaults.RateBurst,
+		MaxConcurrency:      PlanFreeDefaults.MaxConcurrency,
+		CharacterRate:       1000,
+		CharacterRateWindow: time.Hour,
+		TranslationMemory:   "*deepl.MapTranslationMemory",
+		DefaultSourceLang:   "DE",
+		DefaultTargetLang:   "EN",
+		CallDefaults:        ResolvedOptions{Request: TranslateRequest{Formality: FormalityLess}, Timeout: time.Second},
+		VariantPreference:   map[string]string{"EN": "EN-GB"},
+	}
+	config := cli.Config()
+	if !reflect.DeepEqual(config, expected) {
+		t.Fatalf("got %+v, expected %+v", config, expected)
+	}
+
+	config.VariantPreference["EN"] = "EN-US"
+	if cli.VariantPreference["EN"] != "EN-GB" {
+		t.Fatal("changing the snapshot changed the client")
+	}
+	// the snapshot can be used to build requests
+	req, err := BuildTranslateRequest(TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.URL.Host+req.URL.Path != "proxy.example.com/deepl/v2/translate" || req.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+		t.Fatalf("unexpected request %s %v", req.URL, req.Header)
+	}
+}
+
+type structCache struct{}
+
+func (structCache) Get(key string) ([]Translation, bool)       { return nil, false }
+func (structCache) Set(key string, translations []Translation) {}
+
+type structMemory struct{}
+
+func (structMemory) Lookup(text, src, dst string) (string, bool) { return "", false }
+
+func TestClient_ConfigImplementations(t *testing.T) {
+	tt := []struct {
+		name string
+
+		cli *Client
+
+		expectedBaseURL           string
+		expectedTranslationCache  string
+		expectedTranslationMemory string
+	}{
+		{
+			name: "struct values",
+
+			cli: &Client{BaseURL: &url.URL{Scheme: "https", Host: "api.deepl.com"}, TranslationCache: structCache{}, TranslationMemory: structMemory{}},
+
+			expectedBaseURL:           "https://api.deepl.com",
+			expectedTranslationCache:  "deepl.structCache",
+			expectedTranslationMemory: "deepl.structMemory",
+		},
+		{
+			name: "nil pointers",
+
+			cli: &Client{BaseURL: &url.URL{Scheme: "https", Host: "api.deepl.com"}, TranslationCache: (*structCache)(nil), TranslationMemory: (*MapTranslationMemory)(nil)},
+
+			expectedBaseURL: "https://api.deepl.com",
+		},
+		{
+			name: "zero client",
+
+			cli: &Client{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			config := tc.cli.Config()
+			if config.BaseURL != tc.expectedBaseURL {
+				t.Fatalf("BaseURL wrong. want=%q, got=%q", tc.expectedBaseURL, config.BaseURL)
+			}
+			if config.TranslationCache != tc.expectedTranslationCache || config.TranslationMemory != tc.expectedTranslationMemory {
+				t.Fatalf("implementations wrong. want=%q %q, got=%q %q", tc.expectedTranslationCache, tc.expectedTranslationMemory, config.TranslationCache, config.TranslationMemory)
+			}
+			if explained := tc.cli.ExplainConfig(); !strings.Contains(explained, "BaseURL: "+tc.expectedBaseURL+"\n") {
+				t.Fatalf("BaseURL missing from config:\n%s", explained)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package deepl
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+)
+
+// JobReport describes a batch helper stopped by an exhausted quota. Texts
+// are identified by the helper, e.g. by SRT cue index or properties key, so
+// that the remaining ones can be translated once the quota resets. It is
+// meant to be stored as JSON.
+type JobReport struct {
+	Completed []string `json:"completed"`
+	Remaining []string `json:"remaining"`
+	// Characters is the number of characters sent for the completed texts.
+	Characters int `json:"characters"`
+}
+
+// QuotaExceededError is returned by TranslateSRT, TranslateProperties and
+// TranslateBatch, together with the partial result, when the quota runs out
+// midway. It matches IsQuotaError.
+type QuotaExceededError struct {
+	Report JobReport
+	Err    error
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("Quota exceeded after %d of %d texts: %s", len(e.Report.Completed), len(e.Report.Completed)+len(e.Report.Remaining), e.Err.Error())
+}
+
+func (e *QuotaExceededError) Unwrap() error {
+	return e.Err
+}
+
+// runJob translates texts with req and opts in request-sized chunks and
+// passes each result with the index of its first text to done. ids identify
+// the texts in the JobReport of a *QuotaExceededError.
+func (c *Client) runJob(ctx context.Context, req TranslateRequest, texts, ids []string, done func(start int, result *TranslateResult) error, opts ...TranslateOption) error {
+	if target, ok := reportFrom(ctx); ok {
+		start := c.clock().Now()
+		translated := 0
+		defer func() {
+			target.report.addRun(c.clock().Now().Sub(start), len(texts)-translated)
+		}()
+		next := done
+		done = func(start int, result *TranslateResult) error {
+			texts := texts[start : start+len(result.Translations)]
+			target.report.addResult(target.file, CostTags(ctx), texts, result)
+			if err := next(start, result); err != nil {
+				return err
+			}
+			if !result.Metadata.Failed {
+				translated += len(texts)
+			}
+			return nil
+		}
+	}
+
+	characters := 0
+	for _, ch := range c.chunks(texts) {
+		req.Text = texts[ch.start:ch.end]
+		result, err := c.Translate(ctx, req, opts...)
+		if err != nil {
+			if IsQuotaError(err) {
+				return &QuotaExceededError{
+					Report: JobReport{
+						Completed:  append([]string{}, ids[:ch.start]...),
+						Remaining:  append([]string{}, ids[ch.start:]...),
+						Characters: characters,
+					},
+					Err: err,
+				}
+			}
+			return err
+		}
+		if err := done(ch.start, result); err != nil {
+			return err
+		}
+		for _, text := range req.Text {
+			characters += utf8.RuneCountInString(text)
+		}
+	}
+	return nil
+}
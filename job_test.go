@@ -0,0 +1,87 @@
+package deepl
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+// initQuotaServer translates with prefixTranslations until quota texts have
+// been translated, then answers 456.
+func initQuotaServer(t *testing.T, quota int) (*Client, func()) {
+	translated := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var r TranslateRequest
+		if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+			t.Fatalf("failed to decode request body: %s", err.Error())
+		}
+		if translated+len(r.Text) > quota {
+			w.WriteHeader(StatusQuotaExceeded)
+			return
+		}
+		translated += len(r.Text)
+		json.NewEncoder(w).Encode(TranslateResult{Translations: prefixTranslations(r)})
+	}))
+
+	cli, err := New(server.URL, nil, WithAPIKey(testAPIKey), WithMaxTextsPerRequest(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cli, server.Close
+}
+
+func TestClient_TranslateSRTQuotaExceeded(t *testing.T) {
+	cli, teardown := initQuotaServer(t, 1)
+	defer teardown()
+
+	s, err := ParseSRT(strings.NewReader("1\n00:00:01,000 --> 00:00:02,000\nHello\n\n2\n00:00:03,000 --> 00:00:04,000\nBye\n\n3\n00:00:05,000 --> 00:00:06,000\nAgain\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := cli.TranslateSRT(context.Background(), s, TranslateRequest{TargetLang: "DE"})
+	quotaErr, ok := err.(*QuotaExceededError)
+	if !ok || !IsQuotaError(err) {
+		t.Fatalf("expected *QuotaExceededError, got %v", err)
+	}
+	if out == nil || out.Cues[0].Lines[0] != "DE:Hello" || out.Cues[1].Lines[0] != "Bye" {
+		t.Fatalf("unexpected partial result %+v", out)
+	}
+
+	expected := JobReport{Completed: []string{"1"}, Remaining: []string{"2", "3"}, Characters: 5}
+	if !reflect.DeepEqual(quotaErr.Report, expected) {
+		t.Fatalf("report wrong. want=%+v, got=%+v", expected, quotaErr.Report)
+	}
+	b, err := json.Marshal(quotaErr.Report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"completed":["1"],"remaining":["2","3"],"characters":5}` {
+		t.Fatalf("unexpected JSON %s", b)
+	}
+}
+
+func TestClient_TranslatePropertiesQuotaExceeded(t *testing.T) {
+	cli, teardown := initQuotaServer(t, 0)
+	defer teardown()
+
+	p, err := ParseProperties(strings.NewReader("greeting=Hello\nfarewell=Bye\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := cli.TranslateProperties(context.Background(), p, TranslateRequest{TargetLang: "DE"})
+	quotaErr, ok := err.(*QuotaExceededError)
+	if !ok {
+		t.Fatalf("expected *QuotaExceededError, got %v", err)
+	}
+	if v, _ := out.Get("greeting"); v != "Hello" {
+		t.Fatalf("untranslated entry changed to %q", v)
+	}
+	if len(quotaErr.Report.Completed) != 0 || !reflect.DeepEqual(quotaErr.Report.Remaining, []string{"greeting", "farewell"}) {
+		t.Fatalf("unexpected report %+v", quotaErr.Report)
+	}
+}
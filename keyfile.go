@@ -0,0 +1,88 @@
+package deepl
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// WithAPIKeyFile reads the API key from a file, such as a mounted Kubernetes
+// or Docker secret, and sets Client.APIKeyProvider. Surrounding whitespace is
+// trimmed. A missing or empty file fails New.
+func WithAPIKeyFile(path string) Option {
+	return WithAPIKeyFileWatch(path, 0)
+}
+
+// WithAPIKeyFileWatch is WithAPIKeyFile that checks the modification time
+// of the file at most every interval and rereads it when it changed, so
+// rotated keys are used without a restart. If the changed file can't be
+// read or is empty, the previous key is kept.
+func WithAPIKeyFileWatch(path string, interval time.Duration) Option {
+	return func(c *Client) error {
+		if interval < 0 {
+			return xerrors.Errorf("Failed to configure API key file: invalid interval %v", interval)
+		}
+		f := &keyFile{path: path, interval: interval, clock: c.clock}
+		var err error
+		f.key, f.modTime, err = readKeyFile(path)
+		if err != nil {
+			return err
+		}
+		f.checked = c.clock().Now()
+		c.APIKeyProvider = f.get
+		return nil
+	}
+}
+
+// keyFile is an API key file polled for changes.
+type keyFile struct {
+	path     string
+	interval time.Duration
+	clock    func() Clock
+
+	mu      sync.Mutex
+	key     string
+	modTime time.Time
+	checked time.Time
+}
+
+func (f *keyFile) get() (string, error) {
+	if f.interval == 0 {
+		return f.key, nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := f.clock().Now()
+	if now.Sub(f.checked) < f.interval {
+		return f.key, nil
+	}
+	f.checked = now
+	info, err := os.Stat(f.path)
+	if err != nil || info.ModTime().Equal(f.modTime) {
+		return f.key, nil
+	}
+	if key, modTime, err := readKeyFile(f.path); err == nil {
+		f.key, f.modTime = key, modTime
+	}
+	return f.key, nil
+}
+
+func readKeyFile(path string) (string, time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", time.Time{}, xerrors.Errorf("Failed to read API key file %s: %w", path, err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", time.Time{}, xerrors.Errorf("Failed to read API key file %s: %w", path, err)
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", time.Time{}, xerrors.Errorf("Failed to read API key file %s: file is empty", path)
+	}
+	return key, info.ModTime(), nil
+}
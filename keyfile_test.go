@@ -0,0 +1,75 @@
+package deepl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithAPIKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "deepl-key")
+	if err := ioutil.WriteFile(path, []byte("  secret:fx\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cli, err := New("https://api.deepl.com", nil, WithAPIKeyFile(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key, err := cli.apiKey(); err != nil || key != "secret:fx" {
+		t.Fatalf("got %q, %v", key, err)
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := ioutil.WriteFile(empty, []byte("\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{empty, filepath.Join(dir, "missing")} {
+		_, err := New("https://api.deepl.com", nil, WithAPIKeyFile(p))
+		if err == nil || !strings.Contains(err.Error(), p) {
+			t.Errorf("expected error naming %s, got %v", p, err)
+		}
+	}
+}
+
+func TestWithAPIKeyFileWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deepl-key")
+	write := func(key string, modTime time.Time) {
+		if err := ioutil.WriteFile(path, []byte(key), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := time.Now().Add(-time.Hour)
+	write("old", start)
+
+	cli, err := New("https://api.deepl.com", nil, WithAPIKeyFileWatch(path, time.Nanosecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectKey := func(expected string) {
+		t.Helper()
+		time.Sleep(time.Millisecond)
+		if key, err := cli.apiKey(); err != nil || key != expected {
+			t.Fatalf("got %q, %v, expected %q", key, err, expected)
+		}
+	}
+
+	write("rotated", start.Add(time.Minute))
+	expectKey("rotated")
+	// a file caught mid-write keeps the previous key
+	write("", start.Add(2*time.Minute))
+	expectKey("rotated")
+	os.Remove(path)
+	expectKey("rotated")
+
+	if _, err := New("https://api.deepl.com", nil, WithAPIKeyFileWatch(path, -time.Second)); err == nil {
+		t.Fatal("expected error for negative interval")
+	}
+}
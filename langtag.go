@@ -0,0 +1,112 @@
+package deepl
+
+import (
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// regionalCodes are the DeepL language codes with a region or script.
+var regionalCodes = map[string]bool{
+	"EN-GB":   true,
+	"EN-US":   true,
+	"ES-419":  true,
+	"PT-BR":   true,
+	"PT-PT":   true,
+	"ZH-HANS": true,
+	"ZH-HANT": true,
+}
+
+// traditionalChinese are the regions whose Chinese is written in
+// traditional script when a tag doesn't name the script.
+var traditionalChinese = map[string]bool{"TW": true, "HK": true, "MO": true}
+
+// LanguageTag is a BCP 47 language tag. language.Tag of golang.org/x/text
+// implements it, so tags can be passed without this package importing
+// x/text; the tests check the conversions with real language.Tag values.
+type LanguageTag interface {
+	String() string
+}
+
+// ToDeepLLang converts a BCP 47 language tag to a DeepL language. Regions and scripts DeepL
+// doesn't distinguish are dropped, so en-AU becomes EN and zh-CN becomes
+// ZH, while zh-TW and zh-Hant become ZH-HANT. Norwegian (no, nb) becomes NB.
+func ToDeepLLang(tag LanguageTag) (Language, error) {
+	subtags := strings.FieldsFunc(strings.ToUpper(tag.String()), func(r rune) bool { return r == '-' || r == '_' })
+	if len(subtags) == 0 || len(subtags[0]) < 2 || len(subtags[0]) > 3 || subtags[0] == "UND" {
+		return Language{}, xerrors.Errorf("Failed to convert language tag %q: no language", tag.String())
+	}
+
+	base := subtags[0]
+	if base == "NO" || base == "NB" {
+		return Language{Code: "NB"}, nil
+	}
+	var script, region string
+	for _, s := range subtags[1:] {
+		if len(s) == 1 {
+			// extensions and private use, such as -u-co-phonebk
+			break
+		}
+		switch {
+		case len(s) == 4 && script == "" && region == "":
+			script = s
+		case (len(s) == 2 || len(s) == 3) && region == "":
+			region = s
+		}
+	}
+
+	if base == "ZH" {
+		switch {
+		case script == "HANT" || (script == "" && traditionalChinese[region]):
+			return Language{Code: "ZH-HANT"}, nil
+		case script == "HANS":
+			return Language{Code: "ZH-HANS"}, nil
+		}
+		return Language{Code: "ZH"}, nil
+	}
+	if code := base + "-" + region; region != "" && regionalCodes[code] {
+		return Language{Code: code}, nil
+	}
+	return Language{Code: base}, nil
+}
+
+// FromDeepLLang returns the BCP 47 tag of a DeepL language, e.g. "en-GB"
+// for EN-GB or "nb" for NB. It returns a string rather than a language.Tag
+// of golang.org/x/text, so that x/text stays optional; language.Parse
+// accepts every result.
+func FromDeepLLang(l Language) string {
+	code := strings.ToUpper(strings.TrimSpace(l.Code))
+	if code == "NB" {
+		return "nb"
+	}
+	subtags := strings.Split(code, "-")
+	subtags[0] = strings.ToLower(subtags[0])
+	for i, s := range subtags[1:] {
+		if len(s) == 4 {
+			// scripts are title case
+			subtags[i+1] = s[:1] + strings.ToLower(s[1:])
+		}
+	}
+	return strings.Join(subtags, "-")
+}
+
+// SetSourceTag sets SourceLang from a BCP 47 tag, see ToDeepLLang.
+func (r *TranslateRequest) SetSourceTag(tag LanguageTag) error {
+	l, err := ToDeepLLang(tag)
+	if err != nil {
+		return err
+	}
+	// source languages have no variants
+	r.SourceLang = strings.SplitN(l.Code, "-", 2)[0]
+	return nil
+}
+
+// SetTargetTag sets TargetLang from a BCP 47 tag, see ToDeepLLang.
+func (r *TranslateRequest) SetTargetTag(tag LanguageTag) error {
+	l, err := ToDeepLLang(tag)
+	if err != nil {
+		return err
+	}
+	r.TargetLang = l.Code
+	return nil
+}
@@ -0,0 +1,152 @@
+package deepl
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+// bcp47 is a LanguageTag like language.Tag of golang.org/x/text.
+type bcp47 string
+
+func (t bcp47) String() string { return string(t) }
+
+// deeplCodes are the source and target languages supported by DeepL.
+var deeplCodes = []string{
+	"AR", "BG", "CS", "DA", "DE", "EL", "EN", "EN-GB", "EN-US", "ES", "ES-419",
+	"ET", "FI", "FR", "HE", "HU", "ID", "IT", "JA", "KO", "LT", "LV", "NB",
+	"NL", "PL", "PT", "PT-BR", "PT-PT", "RO", "RU", "SK", "SL", "SV", "TH",
+	"TR", "UK", "VI", "ZH", "ZH-HANS", "ZH-HANT",
+}
+
+func TestLanguageTagRoundTrip(t *testing.T) {
+	codes := append([]string(nil), deeplCodes...)
+	for _, l := range loadTargetLanguages(t) {
+		codes = append(codes, l.Code)
+	}
+	for _, code := range codes {
+		tag := FromDeepLLang(Language{Code: code})
+		got, err := ToDeepLLang(bcp47(tag))
+		if err != nil {
+			t.Fatalf("%s: %v", code, err)
+		}
+		if got.Code != code {
+			t.Errorf("%s: round trip through %q gave %s", code, tag, got.Code)
+		}
+	}
+}
+
+func TestToDeepLLang(t *testing.T) {
+	tt := []struct {
+		tag      string
+		expected string
+	}{
+		{"en-GB", "EN-GB"},
+		{"en_us", "EN-US"},
+		{"en-AU", "EN"},
+		{"de-CH-1996", "DE"},
+		{"no", "NB"},
+		{"nb-NO", "NB"},
+		{"zh", "ZH"},
+		{"zh-CN", "ZH"},
+		{"zh-Hans-HK", "ZH-HANS"},
+		{"zh-TW", "ZH-HANT"},
+		{"zh-Hant", "ZH-HANT"},
+		{"es-419", "ES-419"},
+		{"es-MX", "ES"},
+		{"sr-Latn-RS", "SR"},
+	}
+	for _, tc := range tt {
+		got, err := ToDeepLLang(bcp47(tc.tag))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.tag, err)
+		}
+		if got.Code != tc.expected {
+			t.Errorf("%s: got %s, expected %s", tc.tag, got.Code, tc.expected)
+		}
+	}
+
+	for _, tag := range []string{"", "und", "x-private"} {
+		if _, err := ToDeepLLang(bcp47(tag)); err == nil {
+			t.Errorf("%q: expected error", tag)
+		}
+	}
+}
+
+func TestFromDeepLLang(t *testing.T) {
+	for code, expected := range map[string]string{"EN-GB": "en-GB", "NB": "nb", "ZH-HANT": "zh-Hant", "de": "de", "ES-419": "es-419"} {
+		if got := FromDeepLLang(Language{Code: code}); got != expected {
+			t.Errorf("%s: got %q, expected %q", code, got, expected)
+		}
+	}
+}
+
+func TestTranslateRequest_SetTags(t *testing.T) {
+	var req TranslateRequest
+	if err := req.SetSourceTag(bcp47("pt-BR")); err != nil {
+		t.Fatal(err)
+	}
+	if err := req.SetTargetTag(bcp47("zh-TW")); err != nil {
+		t.Fatal(err)
+	}
+	if req.SourceLang != "PT" || req.TargetLang != "ZH-HANT" {
+		t.Fatalf("unexpected languages %s -> %s", req.SourceLang, req.TargetLang)
+	}
+	if err := req.SetTargetTag(bcp47("und")); err == nil || req.TargetLang != "ZH-HANT" {
+		t.Fatal("expected error leaving TargetLang unchanged")
+	}
+}
+
+func TestLanguageTag_XText(t *testing.T) {
+	tt := []struct {
+		tag      string
+		expected string
+	}{
+		{"zh-Hant-TW", "ZH-HANT"},
+		{"zh-TW", "ZH-HANT"},
+		{"zh-HK", "ZH-HANT"},
+		{"zh-Hans-CN", "ZH-HANS"},
+		{"zh-CN", "ZH"},
+		{"pt-BR", "PT-BR"},
+		{"pt-pt", "PT-PT"},
+		{"pt-AO", "PT"},
+		{"en-US-u-va-posix", "EN-US"},
+		{"de-u-co-phonebk", "DE"},
+		{"pt-x-br", "PT"},
+		{"en-GB-oxendict", "EN-GB"},
+		{"es-419", "ES-419"},
+		{"nb", "NB"},
+		{"no", "NB"},
+		{"iw", "HE"},
+	}
+	for _, tc := range tt {
+		tag, err := language.Parse(tc.tag)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.tag, err)
+		}
+		got, err := ToDeepLLang(tag)
+		if err != nil {
+			t.Fatalf("%s (%s): %v", tc.tag, tag, err)
+		}
+		if got.Code != tc.expected {
+			t.Errorf("%s (%s): got %s, expected %s", tc.tag, tag, got.Code, tc.expected)
+		}
+	}
+	if _, err := ToDeepLLang(language.Und); err == nil {
+		t.Error("expected error for the undetermined language")
+	}
+
+	for _, code := range deeplCodes {
+		tag, err := language.Parse(FromDeepLLang(Language{Code: code}))
+		if err != nil {
+			t.Fatalf("%s: %v", code, err)
+		}
+		got, err := ToDeepLLang(tag)
+		if err != nil {
+			t.Fatalf("%s: %v", code, err)
+		}
+		if got.Code != code {
+			t.Errorf("%s: round trip through %s gave %s", code, tag, got.Code)
+		}
+	}
+}
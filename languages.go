@@ -0,0 +1,269 @@
+package deepl
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Language is an entry of the /v2/languages listing.
+type Language struct {
+	Code              string `json:"language"`
+	Name              string `json:"name"`
+	SupportsFormality bool   `json:"supports_formality"`
+}
+
+// LanguageType selects the source or target listing of GetLanguages.
+type LanguageType string
+
+const (
+	LanguageTypeSource LanguageType = "source"
+	LanguageTypeTarget LanguageType = "target"
+)
+
+// ErrNoLanguageMatch is returned by NegotiateTargetLang when no preferred
+// language is supported.
+var ErrNoLanguageMatch = xerrors.New("No supported language matches")
+
+// GetLanguages returns the source or target languages supported by DeepL.
+func (c *Client) GetLanguages(ctx context.Context, languageType LanguageType) ([]Language, error) {
+	var languages []Language
+	params := FormBody{"type": {string(languageType)}}
+	if err := c.Do(ctx, http.MethodGet, "/v2/languages", params, &languages); err != nil {
+		return nil, err
+	}
+	return languages, nil
+}
+
+// targetLanguages returns the target listing, fetched once per client.
+// Failed fetches are not cached.
+func (c *Client) targetLanguages(ctx context.Context) ([]Language, error) {
+	c.languagesMu.Lock()
+	defer c.languagesMu.Unlock()
+	if c.languages != nil {
+		return c.languages, nil
+	}
+	languages, err := c.GetLanguages(ctx, LanguageTypeTarget)
+	if err != nil {
+		return nil, err
+	}
+	c.languages = languages
+	return languages, nil
+}
+
+// TranslateForAcceptLanguage translates req into the language of an HTTP
+// Accept-Language header that DeepL supports best. req.TargetLang is
+// ignored.
+func (c *Client) TranslateForAcceptLanguage(ctx context.Context, req TranslateRequest, acceptLanguage string, opts ...TranslateOption) (*TranslateResult, error) {
+	supported, err := c.targetLanguages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lang, err := negotiateTargetLang(acceptLanguage, supported, c.VariantPreference)
+	if err != nil {
+		return nil, err
+	}
+	req.TargetLang = lang.Code
+	return c.Translate(ctx, req, opts...)
+}
+
+type languageRange struct {
+	tag string
+	q   float64
+}
+
+// parseAcceptLanguage returns the ranges of header by descending weight,
+// keeping the header order for equal weights.
+func parseAcceptLanguage(header string) []languageRange {
+	var ranges []languageRange
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToUpper(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+		r := languageRange{tag: tag, q: 1}
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") || strings.HasPrefix(param, "Q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil && q >= 0 && q <= 1 {
+					r.q = q
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+	return ranges
+}
+
+// NegotiateTargetLang picks the supported language that best matches an
+// HTTP Accept-Language header using RFC 4647 lookup: each range is tried
+// with its subtags removed from the end, so fr-CA falls back to FR. A bare
+// language also matches its first supported regional variant, so en
+// matches EN-GB when EN isn't a target. "*" matches the first supported
+// language not excluded with q=0.
+func NegotiateTargetLang(acceptLanguage string, supported []Language) (Language, error) {
+	return negotiateTargetLang(acceptLanguage, supported, nil)
+}
+
+// negotiateTargetLang is NegotiateTargetLang with a variant preference,
+// which is tried before the bare language and its other variants.
+func negotiateTargetLang(acceptLanguage string, supported []Language, prefs map[string]string) (Language, error) {
+	byCode := make(map[string]Language, len(supported))
+	for _, l := range supported {
+		byCode[strings.ToUpper(l.Code)] = l
+	}
+
+	ranges := parseAcceptLanguage(acceptLanguage)
+	excluded := make(map[string]bool)
+	for _, r := range ranges {
+		if r.q == 0 {
+			excluded[r.tag] = true
+		}
+	}
+
+	wildcard := false
+	for _, r := range ranges {
+		if r.q == 0 {
+			continue
+		}
+		if r.tag == "*" {
+			wildcard = true
+			continue
+		}
+		base := r.tag
+		if i := strings.IndexByte(base, '-'); i >= 0 {
+			base = base[:i]
+		}
+		for tag := r.tag; tag != ""; tag = truncateTag(tag) {
+			if variant, ok := prefs[tag]; ok && tag == base {
+				if l, ok := byCode[variant]; ok && !excluded[variant] {
+					return l, nil
+				}
+			}
+			if l, ok := byCode[tag]; ok && !excluded[tag] {
+				return l, nil
+			}
+		}
+		for _, l := range supported {
+			code := strings.ToUpper(l.Code)
+			if strings.HasPrefix(code, base+"-") && !excluded[code] && !excluded[base] {
+				return l, nil
+			}
+		}
+	}
+
+	if wildcard {
+		for _, l := range supported {
+			code := strings.ToUpper(l.Code)
+			base := code
+			if i := strings.IndexByte(base, '-'); i >= 0 {
+				base = base[:i]
+			}
+			if !excluded[code] && !excluded[base] {
+				return l, nil
+			}
+		}
+	}
+	return Language{}, xerrors.Errorf("Failed to negotiate target language for %q: %w", acceptLanguage, ErrNoLanguageMatch)
+}
+
+// truncateTag removes the last subtag of tag, and a single-letter subtag
+// left before it, as described in RFC 4647 section 3.4.
+func truncateTag(tag string) string {
+	i := strings.LastIndexByte(tag, '-')
+	if i < 0 {
+		return ""
+	}
+	tag = tag[:i]
+	if j := strings.LastIndexByte(tag, '-'); j >= 0 && len(tag)-j == 2 {
+		tag = tag[:j]
+	}
+	return tag
+}
+
+// FormalitySupported reports whether target accepts the formality
+// parameter, according to the /v2/languages listing.
+func FormalitySupported(target Language) bool {
+	return target.SupportsFormality
+}
+
+// WithFormalityDowngrade makes Translate replace formality "more" and
+// "less" with "prefer_more" and "prefer_less" for target languages that
+// don't support formality, instead of failing with a 400. The target
+// listing is fetched once per client.
+func WithFormalityDowngrade() Option {
+	return func(c *Client) error {
+		c.DowngradeFormality = true
+		return nil
+	}
+}
+
+// WithFormalityRetry makes Translate resend a request once when DeepL
+// rejects its formality for the target language with a 400, with
+// "prefer_more" or "prefer_less" in place of "more" and "less" and without
+// any other formality. Such results have Metadata.FormalityDropped set.
+// Unlike WithFormalityDowngrade it needs no language listing.
+func WithFormalityRetry() Option {
+	return func(c *Client) error {
+		c.FormalityRetry = true
+		return nil
+	}
+}
+
+// isFormalityError reports whether err is DeepL rejecting the formality
+// parameter, as in "'formality' is not supported for given 'target_lang'.".
+func isFormalityError(err error) bool {
+	var apiErr *APIError
+	if !xerrors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	message := strings.ToLower(apiErr.Message)
+	return strings.Contains(message, "formality") && strings.Contains(message, "not supported")
+}
+
+// retryFormality returns the formality to resend a request rejected by
+// isFormalityError with.
+func retryFormality(f Formality) Formality {
+	switch normalizeEnum(f) {
+	case FormalityMore:
+		return FormalityPreferMore
+	case FormalityLess:
+		return FormalityPreferLess
+	}
+	return ""
+}
+
+// downgradeFormality applies WithFormalityDowngrade to req. If the listing
+// can't be fetched the request is sent unchanged.
+func (c *Client) downgradeFormality(ctx context.Context, req *TranslateRequest) {
+	var preferred Formality
+	switch normalizeEnum(req.Formality) {
+	case FormalityMore:
+		preferred = FormalityPreferMore
+	case FormalityLess:
+		preferred = FormalityPreferLess
+	default:
+		return
+	}
+
+	languages, err := c.targetLanguages(ctx)
+	if err != nil {
+		c.logf(ctx, "Failed to check formality support of %s: %v", req.TargetLang, err)
+		return
+	}
+	target := strings.ToUpper(strings.TrimSpace(req.TargetLang))
+	for _, l := range languages {
+		if strings.ToUpper(l.Code) == target {
+			if !FormalitySupported(l) {
+				req.Formality = preferred
+			}
+			return
+		}
+	}
+}
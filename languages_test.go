@@ -0,0 +1,317 @@
+package deepl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+func loadTargetLanguages(t *testing.T) []Language {
+	b, err := ioutil.ReadFile("testdata/GetLanguages/target-body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var languages []Language
+	if err := json.Unmarshal(b, &languages); err != nil {
+		t.Fatal(err)
+	}
+	return languages
+}
+
+func TestNegotiateTargetLang(t *testing.T) {
+	supported := loadTargetLanguages(t)
+
+	tt := []struct {
+		name string
+
+		acceptLanguage string
+
+		expected           string
+		expectedErrMessage string
+	}{
+		{name: "chrome en-US", acceptLanguage: "en-US,en;q=0.9", expected: "EN-US"},
+		{name: "chrome de with english fallback", acceptLanguage: "de-DE,de;q=0.9,en-US;q=0.8,en;q=0.7", expected: "DE"},
+		{name: "firefox weights out of order", acceptLanguage: "en;q=0.3,ja,en-US;q=0.7", expected: "JA"},
+		{name: "safari fr-CA", acceptLanguage: "fr-CA", expected: "FR"},
+		{name: "bare english picks first variant", acceptLanguage: "en", expected: "EN-GB"},
+		{name: "portuguese brazil", acceptLanguage: "pt-BR,pt;q=0.9", expected: "PT-BR"},
+		{name: "traditional chinese script", acceptLanguage: "zh-Hant-TW,zh;q=0.8", expected: "ZH-HANT"},
+		{name: "chinese region falls back to base", acceptLanguage: "zh-CN,zh;q=0.9", expected: "ZH"},
+		{name: "unsupported then supported", acceptLanguage: "xx-YY,es;q=0.5", expected: "ES"},
+		{name: "wildcard", acceptLanguage: "xx,*;q=0.1", expected: "BG"},
+		{name: "wildcard with exclusion", acceptLanguage: "*, bg;q=0", expected: "DE"},
+		{name: "excluded exact match", acceptLanguage: "de;q=0, fr;q=0.5", expected: "FR"},
+		{name: "private use extension", acceptLanguage: "ja-x-kansai", expected: "JA"},
+		{name: "no match", acceptLanguage: "xx, yy;q=0.5", expectedErrMessage: "No supported language matches"},
+		{name: "empty header", acceptLanguage: "", expectedErrMessage: "No supported language matches"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			lang, err := NegotiateTargetLang(tc.acceptLanguage, supported)
+			if tc.expectedErrMessage != "" {
+				if !xerrors.Is(err, ErrNoLanguageMatch) {
+					t.Fatalf("expected ErrNoLanguageMatch, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lang.Code != tc.expected {
+				t.Fatalf("got %s, expected %s", lang.Code, tc.expected)
+			}
+		})
+	}
+}
+
+func TestClient_GetLanguages(t *testing.T) {
+	cli, teardown := initTestServer(t, "testdata/GetLanguages/target-header", "testdata/GetLanguages/target-body",
+		http.MethodGet, "/v2/languages", "type=target", "")
+	defer teardown()
+
+	languages, err := cli.GetLanguages(context.Background(), LanguageTypeTarget)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(languages) != 11 || languages[1] != (Language{Code: "DE", Name: "German", SupportsFormality: true}) {
+		t.Fatalf("unexpected languages %+v", languages)
+	}
+}
+
+// initLanguagesServer serves the target listing fixture and translates with
+// prefixTranslations.
+func initLanguagesServer(t *testing.T) (*Client, *[]TranslateRequest, *int, func()) {
+	listing, err := ioutil.ReadFile("testdata/GetLanguages/target-body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listings := 0
+	var received []TranslateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/v2/languages":
+			listings++
+			w.Write(listing)
+		case "/v2/translate":
+			var r TranslateRequest
+			if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+				t.Fatalf("failed to decode request body: %s", err.Error())
+			}
+			received = append(received, r)
+			json.NewEncoder(w).Encode(TranslateResult{Translations: prefixTranslations(r)})
+		}
+	}))
+
+	cli, err := New(server.URL, nil, WithAPIKey(testAPIKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cli, &received, &listings, server.Close
+}
+
+func TestClient_TranslateForAcceptLanguage(t *testing.T) {
+	cli, received, listings, teardown := initLanguagesServer(t)
+	defer teardown()
+
+	for _, header := range []string{"fr-CA,fr;q=0.9", "en-US,en;q=0.9"} {
+		if _, err := cli.TranslateForAcceptLanguage(context.Background(), TranslateRequest{Text: []string{"Hello"}}, header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(*received) != 2 || (*received)[0].TargetLang != "FR" || (*received)[1].TargetLang != "EN-US" {
+		t.Fatalf("unexpected requests %+v", *received)
+	}
+	if *listings != 1 {
+		t.Fatalf("target languages fetched %d times, expected 1", *listings)
+	}
+
+	if _, err := cli.TranslateForAcceptLanguage(context.Background(), TranslateRequest{Text: []string{"Hello"}}, "xx"); !xerrors.Is(err, ErrNoLanguageMatch) {
+		t.Fatalf("expected ErrNoLanguageMatch, got %v", err)
+	}
+}
+
+func TestClient_FormalityDowngrade(t *testing.T) {
+	tt := []struct {
+		name string
+
+		targetLang string
+		formality  Formality
+
+		expected Formality
+	}{
+		{name: "supported target keeps formality", targetLang: "DE", formality: FormalityMore, expected: FormalityMore},
+		{name: "unsupported target prefers more", targetLang: "BG", formality: FormalityMore, expected: FormalityPreferMore},
+		{name: "unsupported target prefers less", targetLang: "en-gb", formality: FormalityLess, expected: FormalityPreferLess},
+		{name: "prefer values are untouched", targetLang: "BG", formality: FormalityPreferLess, expected: FormalityPreferLess},
+		{name: "unknown target is untouched", targetLang: "XX", formality: FormalityMore, expected: FormalityMore},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, received, _, teardown := initLanguagesServer(t)
+			defer teardown()
+			cli.DowngradeFormality = true
+
+			if _, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"Hello"}, TargetLang: tc.targetLang, Formality: tc.formality}); err != nil {
+				t.Fatal(err)
+			}
+			if got := (*received)[0].Formality; got != tc.expected {
+				t.Fatalf("sent formality %q, expected %q", got, tc.expected)
+			}
+		})
+	}
+
+	languages := loadTargetLanguages(t)
+	if !FormalitySupported(languages[1]) || FormalitySupported(languages[0]) {
+		t.Fatal("FormalitySupported disagrees with the listing")
+	}
+}
+
+func TestClient_FormalityRetry(t *testing.T) {
+	formalityErr, err := ioutil.ReadFile("testdata/Translate/unsupported-formality-body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	targetErr, err := ioutil.ReadFile("testdata/TranslateText/unsuport-target_lang-body")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name string
+
+		retry     bool
+		formality Formality
+		reject    func(r TranslateRequest) []byte
+		rejectAll bool
+
+		expectedFormalities []Formality
+		expectedDropped     bool
+		expectedErr         bool
+	}{
+		{
+			name: "more becomes prefer_more",
+
+			retry:     true,
+			formality: FormalityMore,
+			reject:    func(r TranslateRequest) []byte { return formalityErr },
+
+			expectedFormalities: []Formality{FormalityMore, FormalityPreferMore},
+			expectedDropped:     true,
+		},
+		{
+			name: "less becomes prefer_less",
+
+			retry:     true,
+			formality: "LESS",
+			reject:    func(r TranslateRequest) []byte { return formalityErr },
+
+			expectedFormalities: []Formality{"less", FormalityPreferLess},
+			expectedDropped:     true,
+		},
+		{
+			name: "other formality is dropped",
+
+			retry:     true,
+			formality: FormalityDefault,
+			reject:    func(r TranslateRequest) []byte { return formalityErr },
+
+			expectedFormalities: []Formality{FormalityDefault, ""},
+			expectedDropped:     true,
+		},
+		{
+			name: "exactly one adjusted retry",
+
+			retry:     true,
+			formality: FormalityMore,
+			reject: func(r TranslateRequest) []byte {
+				if r.Formality == FormalityPreferMore {
+					return []byte(`{"message":"Value for 'formality' is not supported."}`)
+				}
+				return formalityErr
+			},
+			rejectAll: true,
+
+			expectedFormalities: []Formality{FormalityMore, FormalityPreferMore},
+			expectedErr:         true,
+		},
+		{
+			name: "opt-in",
+
+			formality: FormalityMore,
+			reject:    func(r TranslateRequest) []byte { return formalityErr },
+
+			expectedFormalities: []Formality{FormalityMore},
+			expectedErr:         true,
+		},
+		{
+			name: "other bad requests are not retried",
+
+			retry:     true,
+			formality: FormalityMore,
+			reject:    func(r TranslateRequest) []byte { return targetErr },
+
+			expectedFormalities: []Formality{FormalityMore},
+			expectedErr:         true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var received []TranslateRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				var r TranslateRequest
+				if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+					t.Fatal(err)
+				}
+				received = append(received, r)
+				if len(received) == 1 || tc.rejectAll {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write(tc.reject(r))
+					return
+				}
+				json.NewEncoder(w).Encode(TranslateResult{Translations: prefixTranslations(r)})
+			}))
+			defer server.Close()
+
+			opts := []Option{WithAPIKey(testAPIKey), WithRetries(2, time.Millisecond)}
+			if tc.retry {
+				opts = append(opts, WithFormalityRetry())
+			}
+			cli, err := New(server.URL, nil, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"Hello"}, TargetLang: "JA", Formality: tc.formality})
+
+			var formalities []Formality
+			for _, r := range received {
+				formalities = append(formalities, r.Formality)
+			}
+			if !reflect.DeepEqual(formalities, tc.expectedFormalities) {
+				t.Fatalf("sent formalities %q, expected %q", formalities, tc.expectedFormalities)
+			}
+			if tc.expectedErr {
+				if !IsInvalidRequest(err) {
+					t.Fatalf("expected a bad request, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Metadata.FormalityDropped != tc.expectedDropped || res.Texts()[0] != "JA:Hello" {
+				t.Fatalf("unexpected result %+v", res)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package deepl
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// rateLimiter is a token bucket. Waiters reserve a token up front so that
+// they are served in arrival order.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	clock  Clock
+
+	waits waitStats
+}
+
+func newRateLimiter(requestsPerSecond float64, burst int, clock Clock) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{rate: requestsPerSecond, burst: float64(burst), tokens: float64(burst), last: clock.Now(), clock: clock}
+}
+
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := l.clock.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		l.waits.done(0)
+		return nil
+	}
+	l.waits.enter()
+	defer l.waits.leave()
+	timer := l.clock.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		l.waits.done(l.clock.Now().Sub(now))
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return xerrors.Errorf("Failed to wait for rate limiter: %w", ctx.Err())
+	}
+}
+
+// available returns the tokens left after refilling, negative when waiters
+// have reserved tokens ahead of time.
+func (l *rateLimiter) available() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	tokens := l.tokens + l.clock.Now().Sub(l.last).Seconds()*l.rate
+	if tokens > l.burst {
+		tokens = l.burst
+	}
+	return tokens
+}
+
+// waitStats counts the callers blocked in a limiter and the time they
+// waited.
+type waitStats struct {
+	mu      sync.Mutex
+	waiting int
+	count   int64
+	total   time.Duration
+}
+
+func (s *waitStats) enter() {
+	s.mu.Lock()
+	s.waiting++
+	s.mu.Unlock()
+}
+
+func (s *waitStats) leave() {
+	s.mu.Lock()
+	s.waiting--
+	s.mu.Unlock()
+}
+
+// done records an acquisition that took d.
+func (s *waitStats) done(d time.Duration) {
+	s.mu.Lock()
+	s.count++
+	s.total += d
+	s.mu.Unlock()
+}
+
+func (s *waitStats) snapshot() (waiting int, average time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.count > 0 {
+		average = s.total / time.Duration(s.count)
+	}
+	return s.waiting, average
+}
+
+// LimitStats is a snapshot of the client's rate limiter and concurrency
+// limit. Fields of a disabled limit are zero.
+type LimitStats struct {
+	// Tokens are the requests the rate limiter allows right now. It is
+	// negative while RateWaiters hold reservations.
+	Tokens          float64
+	RateWaiters     int
+	AverageRateWait time.Duration
+
+	// InFlight is the number of concurrency slots taken, out of
+	// MaxConcurrency.
+	InFlight               int
+	ConcurrencyWaiters     int
+	AverageConcurrencyWait time.Duration
+}
+
+// LimitStats tells whether throughput is limited by the client: callers
+// queue in the rate limiter or for a concurrency slot. Averages include
+// acquisitions that didn't wait.
+func (c *Client) LimitStats() LimitStats {
+	c.initLimits()
+	var stats LimitStats
+	if c.limiter != nil {
+		stats.Tokens = c.limiter.available()
+		stats.RateWaiters, stats.AverageRateWait = c.limiter.waits.snapshot()
+	}
+	if c.semaphore != nil {
+		stats.InFlight = len(c.semaphore)
+		stats.ConcurrencyWaiters, stats.AverageConcurrencyWait = c.semaphoreWaits.snapshot()
+	}
+	return stats
+}
+
+// initLimits creates the rate limiter and concurrency semaphore from the
+// client settings on first use.
+func (c *Client) initLimits() {
+	c.limitsOnce.Do(func() {
+		if c.RequestsPerSecond > 0 {
+			c.limiter = newRateLimiter(c.RequestsPerSecond, c.RateBurst, c.clock())
+		}
+		if c.MaxConcurrency > 0 {
+			c.semaphore = make(chan struct{}, c.MaxConcurrency)
+		}
+	})
+}
+
+// acquire blocks until a concurrency slot is free. The returned function
+// releases it.
+func (c *Client) acquire(ctx context.Context) (func(), error) {
+	c.initLimits()
+	if c.semaphore == nil {
+		return func() {}, nil
+	}
+	release := func() { <-c.semaphore }
+	select {
+	case c.semaphore <- struct{}{}:
+		c.semaphoreWaits.done(0)
+		return release, nil
+	default:
+	}
+
+	start := c.clock().Now()
+	c.semaphoreWaits.enter()
+	defer c.semaphoreWaits.leave()
+	select {
+	case c.semaphore <- struct{}{}:
+		c.semaphoreWaits.done(c.clock().Now().Sub(start))
+		return release, nil
+	case <-ctx.Done():
+		return nil, xerrors.Errorf("Failed to wait for concurrency limit: %w", ctx.Err())
+	}
+}
+
+// waitRate blocks until the rate limiter allows another request.
+func (c *Client) waitRate(ctx context.Context) error {
+	c.initLimits()
+	if c.limiter == nil {
+		return nil
+	}
+	return c.limiter.Wait(ctx)
+}
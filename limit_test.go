@@ -0,0 +1,91 @@
+package deepl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// waitFor polls cond until it holds or a second has passed.
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestClient_LimitStatsRateWaiters(t *testing.T) {
+	cli, err := New("http://localhost", nil, WithAPIKey(testAPIKey), WithPlanSettings(PlanDefaults{RequestsPerSecond: 1, RateBurst: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.waitRate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cli.waitRate(ctx)
+		}()
+	}
+	waitFor(t, func() bool { return cli.LimitStats().RateWaiters == 2 })
+	if stats := cli.LimitStats(); stats.Tokens > -1.5 {
+		t.Fatalf("expected two reserved tokens, got %+v", stats)
+	}
+
+	cancel()
+	wg.Wait()
+	if stats := cli.LimitStats(); stats.RateWaiters != 0 || stats.Tokens < 0 {
+		t.Fatalf("canceled waiters not released: %+v", stats)
+	}
+}
+
+func TestClient_LimitStatsConcurrency(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`{"character_count":0,"character_limit":0}`))
+	}))
+	defer ts.Close()
+
+	cli, err := New(ts.URL, nil, WithAPIKey(testAPIKey), WithPlanSettings(PlanDefaults{MaxConcurrency: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cli.GetAccountStatus(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	waitFor(t, func() bool {
+		stats := cli.LimitStats()
+		return stats.InFlight == 1 && stats.ConcurrencyWaiters == 2
+	})
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	stats := cli.LimitStats()
+	if stats.InFlight != 0 || stats.ConcurrencyWaiters != 0 || stats.AverageConcurrencyWait <= 0 {
+		t.Fatalf("unexpected stats after contention: %+v", stats)
+	}
+	if stats.Tokens != 0 || stats.RateWaiters != 0 {
+		t.Fatalf("rate limiter stats without a limiter: %+v", stats)
+	}
+}
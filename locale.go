@@ -0,0 +1,52 @@
+package deepl
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// LocaleFile is a key-value file of UI strings, such as a Properties file.
+// Formats that need third-party parsers, like YAML, implement it outside
+// this module.
+type LocaleFile interface {
+	// Keys returns the keys in file order.
+	Keys() []string
+	Get(key string) (string, bool)
+	// Set replaces the value of an existing key.
+	Set(key, value string) bool
+}
+
+// TranslateLocale translates the values of f in place. Options other than
+// Text are taken from req. Matches of patterns, such as the placeholders of
+// the file format, are kept out of the translation; a *PlaceholderError is
+// returned when one is lost. When the quota runs out, the values translated
+// so far are kept and a *QuotaExceededError lists the remaining keys.
+func (c *Client) TranslateLocale(ctx context.Context, f LocaleFile, req TranslateRequest, patterns ...*regexp.Regexp) error {
+	var keys []string
+	var protected []protectedText
+	for _, key := range f.Keys() {
+		value, _ := f.Get(key)
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		keys = append(keys, key)
+		protected = append(protected, protectPlaceholders(value, patterns, false))
+	}
+
+	texts := make([]string, len(protected))
+	for i, pt := range protected {
+		texts[i] = pt.text
+	}
+	req.TagHandling = TagHandlingXML
+	return c.runJob(ctx, req, texts, keys, func(start int, result *TranslateResult) error {
+		for j, t := range result.Translations {
+			value, missing := protected[start+j].restore(t.Text)
+			if len(missing) > 0 {
+				return c.localize(&PlaceholderError{Key: keys[start+j], Missing: missing, private: c.PrivateErrors})
+			}
+			f.Set(keys[start+j], value)
+		}
+		return nil
+	})
+}
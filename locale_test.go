@@ -0,0 +1,67 @@
+package deepl
+
+import (
+	"regexp"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+var _ LocaleFile = (*Properties)(nil)
+
+// mapLocale is a LocaleFile with keys in the order of keys.
+type mapLocale struct {
+	keys   []string
+	values map[string]string
+}
+
+func (l *mapLocale) Keys() []string { return l.keys }
+
+func (l *mapLocale) Get(key string) (string, bool) {
+	v, ok := l.values[key]
+	return v, ok
+}
+
+func (l *mapLocale) Set(key, value string) bool {
+	if _, ok := l.values[key]; !ok {
+		return false
+	}
+	l.values[key] = value
+	return true
+}
+
+func TestClient_TranslateLocale(t *testing.T) {
+	cli, received, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+
+	f := &mapLocale{
+		keys:   []string{"empty", "greeting", "title"},
+		values: map[string]string{"empty": " ", "greeting": "Hello %{name} & co", "title": "Home"},
+	}
+	err := cli.TranslateLocale(context.Background(), f, TranslateRequest{TargetLang: "DE"}, regexp.MustCompile(`%\{\w+\}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*received) != 1 || len((*received)[0].Text) != 2 || (*received)[0].TagHandling != TagHandlingXML {
+		t.Fatalf("unexpected requests %+v", *received)
+	}
+	expected := map[string]string{"empty": " ", "greeting": "DE:Hello %{name} & co", "title": "DE:Home"}
+	for key, value := range expected {
+		if f.values[key] != value {
+			t.Errorf("%s: got %q, expected %q", key, f.values[key], value)
+		}
+	}
+}
+
+func TestClient_TranslateLocaleLostPlaceholder(t *testing.T) {
+	cli, _, teardown := initTranslateServer(t, func(req TranslateRequest) []Translation {
+		return []Translation{{Text: "Hallo"}}
+	})
+	defer teardown()
+
+	f := &mapLocale{keys: []string{"greeting"}, values: map[string]string{"greeting": "Hello %{name}"}}
+	err := cli.TranslateLocale(context.Background(), f, TranslateRequest{TargetLang: "DE"}, regexp.MustCompile(`%\{\w+\}`))
+	if perr, ok := err.(*PlaceholderError); !ok || perr.Key != "greeting" {
+		t.Fatalf("expected *PlaceholderError, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package deepl
+
+import (
+	"net/http"
+	"strconv"
+
+	"golang.org/x/xerrors"
+)
+
+// ErrorKind classifies errors for user-facing messages.
+type ErrorKind string
+
+const (
+	ErrorKindAuth                ErrorKind = "auth"
+	ErrorKindQuota               ErrorKind = "quota"
+	ErrorKindRateLimited         ErrorKind = "rate_limited"
+	ErrorKindInvalidRequest      ErrorKind = "invalid_request"
+	ErrorKindServer              ErrorKind = "server"
+	ErrorKindEndpointUnreachable ErrorKind = "endpoint_unreachable"
+	ErrorKindPlaceholder         ErrorKind = "placeholder"
+	ErrorKindUnknown             ErrorKind = "unknown"
+)
+
+// ErrorInfo describes an error to an ErrorLocalizer. It never holds the API
+// key or the texts of a request.
+type ErrorInfo struct {
+	Kind ErrorKind
+	// StatusCode is the HTTP status of API errors, or zero.
+	StatusCode int
+	// Params holds kind specific values: "host" for unreachable endpoints,
+	// "count" of lost placeholders.
+	Params map[string]string
+}
+
+// ErrorLocalizer returns the message shown to end users for an error.
+// Returning "" falls back to DefaultErrorMessages.
+type ErrorLocalizer func(ErrorInfo) string
+
+// DefaultErrorMessages are the English user-facing messages by kind. Teams
+// can copy the table to supply translations to their ErrorLocalizer.
+var DefaultErrorMessages = map[ErrorKind]string{
+	ErrorKindAuth:                "The translation service rejected the credentials.",
+	ErrorKindQuota:               "The translation quota has been used up.",
+	ErrorKindRateLimited:         "Too many translation requests. Please try again shortly.",
+	ErrorKindInvalidRequest:      "The translation request was invalid.",
+	ErrorKindServer:              "The translation service is unavailable. Please try again later.",
+	ErrorKindEndpointUnreachable: "The translation service could not be reached.",
+	ErrorKindPlaceholder:         "Part of the text could not be translated safely.",
+	ErrorKindUnknown:             "Translation failed.",
+}
+
+// WithErrorLocalizer sets the localizer of user-facing error messages.
+func WithErrorLocalizer(l ErrorLocalizer) Option {
+	return func(c *Client) error {
+		c.ErrorLocalizer = l
+		return nil
+	}
+}
+
+// userMessage is embedded in errors that carry a localized message.
+type userMessage struct {
+	localized string
+}
+
+func (m *userMessage) setUserMessage(s string) {
+	m.localized = s
+}
+
+// UserMessage returns the message shown to end users for err: the message
+// of the client's ErrorLocalizer, if any, otherwise the English default.
+func UserMessage(err error) string {
+	var localized interface{ UserMessage() string }
+	if xerrors.As(err, &localized) {
+		return localized.UserMessage()
+	}
+	return DefaultErrorMessages[ErrorKindUnknown]
+}
+
+func (e *APIError) UserMessage() string {
+	return e.userMessage.message(e.info())
+}
+
+func (e *EndpointUnreachableError) UserMessage() string {
+	return e.userMessage.message(e.info())
+}
+
+func (e *PlaceholderError) UserMessage() string {
+	return e.userMessage.message(e.info())
+}
+
+func (m *userMessage) message(info ErrorInfo) string {
+	if m.localized != "" {
+		return m.localized
+	}
+	return DefaultErrorMessages[info.Kind]
+}
+
+func (e *APIError) info() ErrorInfo {
+	info := ErrorInfo{Kind: ErrorKindUnknown, StatusCode: e.StatusCode}
+	switch {
+	case IsAuthError(e):
+		info.Kind = ErrorKindAuth
+	case IsQuotaError(e):
+		info.Kind = ErrorKindQuota
+	case e.StatusCode == http.StatusTooManyRequests:
+		info.Kind = ErrorKindRateLimited
+	case IsInvalidRequest(e):
+		info.Kind = ErrorKindInvalidRequest
+	case e.StatusCode >= 500:
+		info.Kind = ErrorKindServer
+	}
+	return info
+}
+
+func (e *EndpointUnreachableError) info() ErrorInfo {
+	return ErrorInfo{Kind: ErrorKindEndpointUnreachable, Params: map[string]string{"host": e.Host}}
+}
+
+func (e *PlaceholderError) info() ErrorInfo {
+	return ErrorInfo{Kind: ErrorKindPlaceholder, Params: map[string]string{"count": strconv.Itoa(len(e.Missing))}}
+}
+
+// localize stores the message of c.ErrorLocalizer in err and returns err.
+func (c *Client) localize(err error) error {
+	if c.ErrorLocalizer == nil {
+		return err
+	}
+	var target interface {
+		info() ErrorInfo
+		setUserMessage(string)
+	}
+	if xerrors.As(err, &target) {
+		target.setUserMessage(c.ErrorLocalizer(target.info()))
+	}
+	return err
+}
@@ -0,0 +1,72 @@
+package deepl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+func TestClient_ErrorLocalizer(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(StatusQuotaExceeded)
+	}))
+	defer ts.Close()
+
+	localized := map[ErrorKind]string{ErrorKindQuota: "Kontingent erschöpft."}
+	var seen []ErrorInfo
+	cli, err := New(ts.URL, nil, WithAPIKey("secret-key"), WithErrorLocalizer(func(info ErrorInfo) string {
+		seen = append(seen, info)
+		return localized[info.Kind]
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cli.Translate(context.Background(), TranslateRequest{Text: []string{"private text"}, TargetLang: "DE"})
+	var apiErr *APIError
+	if !xerrors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+	if got := UserMessage(err); got != "Kontingent erschöpft." {
+		t.Fatalf("unexpected user message %q", got)
+	}
+	if !strings.HasPrefix(err.Error(), "Quota exceeded") {
+		t.Fatalf("developer message changed: %q", err.Error())
+	}
+	if len(seen) != 1 || seen[0].Kind != ErrorKindQuota || seen[0].StatusCode != StatusQuotaExceeded {
+		t.Fatalf("unexpected localizer calls %+v", seen)
+	}
+	for k, v := range seen[0].Params {
+		if strings.Contains(k+v, "secret-key") || strings.Contains(k+v, "private text") {
+			t.Fatalf("localizer saw request data %q=%q", k, v)
+		}
+	}
+}
+
+func TestUserMessage_Defaults(t *testing.T) {
+	tt := []struct {
+		err      error
+		expected ErrorKind
+	}{
+		{err: &APIError{StatusCode: http.StatusForbidden}, expected: ErrorKindAuth},
+		{err: &APIError{StatusCode: http.StatusTooManyRequests}, expected: ErrorKindRateLimited},
+		{err: &APIError{StatusCode: http.StatusBadRequest}, expected: ErrorKindInvalidRequest},
+		{err: &APIError{StatusCode: http.StatusBadGateway}, expected: ErrorKindServer},
+		{err: &APIError{StatusCode: http.StatusTeapot}, expected: ErrorKindUnknown},
+		{err: &PlaceholderError{Key: "0", Missing: []string{"{0}"}}, expected: ErrorKindPlaceholder},
+		{err: xerrors.Errorf("Failed to send: %w", &APIError{StatusCode: StatusQuotaExceeded}), expected: ErrorKindQuota},
+		{err: xerrors.New("Failed to parse Json"), expected: ErrorKindUnknown},
+	}
+
+	for _, tc := range tt {
+		t.Run(string(tc.expected), func(t *testing.T) {
+			if got := UserMessage(tc.err); got != DefaultErrorMessages[tc.expected] {
+				t.Fatalf("unexpected user message %q", got)
+			}
+		})
+	}
+}
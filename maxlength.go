@@ -0,0 +1,113 @@
+package deepl
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const ellipsis = "…"
+
+// MaxLengthResult is returned by TranslateWithMaxLength.
+type MaxLengthResult struct {
+	// Full is the translation of the original text.
+	Full string
+	// Text is the translation that fits the limit.
+	Text string
+	// Truncated reports whether Text was cut with an ellipsis.
+	Truncated bool
+	// Shortened reports whether Text is the translation of a shortened
+	// source.
+	Shortened bool
+}
+
+// MaxLengthOption configures TranslateWithMaxLength.
+type MaxLengthOption func(*maxLengthConfig)
+
+type maxLengthConfig struct {
+	shorten func(text string) string
+}
+
+// WithShortenedSource makes TranslateWithMaxLength translate shorten(text)
+// when the translation of text is too long, before falling back to
+// truncation.
+func WithShortenedSource(shorten func(text string) string) MaxLengthOption {
+	return func(c *maxLengthConfig) {
+		c.shorten = shorten
+	}
+}
+
+// TranslateWithMaxLength translates text into dst and makes the result fit
+// in maxRunes characters, truncating at a word boundary if needed.
+func (c *Client) TranslateWithMaxLength(ctx context.Context, text, dst string, maxRunes int, opts ...MaxLengthOption) (*MaxLengthResult, error) {
+	var config maxLengthConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	translated, err := c.translateOne(ctx, text, dst)
+	if err != nil {
+		return nil, err
+	}
+	result := &MaxLengthResult{Full: translated, Text: translated}
+	if runeLen(translated) <= maxRunes {
+		return result, nil
+	}
+
+	if config.shorten != nil {
+		if shortened := config.shorten(text); shortened != "" && shortened != text {
+			translated, err := c.translateOne(ctx, shortened, dst)
+			if err != nil {
+				return nil, err
+			}
+			result.Text = translated
+			result.Shortened = true
+			if runeLen(translated) <= maxRunes {
+				return result, nil
+			}
+		}
+	}
+
+	result.Text = truncateWithEllipsis(result.Text, maxRunes)
+	result.Truncated = true
+	return result, nil
+}
+
+func (c *Client) translateOne(ctx context.Context, text, dst string) (string, error) {
+	res, err := c.Translate(ctx, TranslateRequest{Text: []string{text}, TargetLang: dst})
+	if err != nil {
+		return "", err
+	}
+	if len(res.Translations) == 0 {
+		return "", nil
+	}
+	return res.Translations[0].Text, nil
+}
+
+func runeLen(s string) int {
+	return len([]rune(s))
+}
+
+// truncateWithEllipsis cuts s to at most maxRunes characters including the
+// ellipsis. It cuts at the last word boundary when there is one and never
+// inside a grapheme cluster.
+func truncateWithEllipsis(s string, maxRunes int) string {
+	if runeLen(s) <= maxRunes {
+		return s
+	}
+	if maxRunes < 1 {
+		return ""
+	}
+	cut := truncateGraphemes(s, maxRunes-1)
+	// move back to the last word boundary when the next character is not a
+	// space, since the last word would otherwise be cut in half
+	if next, _ := utf8.DecodeRuneInString(s[len(cut):]); !unicode.IsSpace(next) {
+		if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > 0 {
+			cut = cut[:i]
+		}
+	}
+	return strings.TrimRightFunc(cut, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + ellipsis
+}
@@ -0,0 +1,107 @@
+package deepl
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestTruncateWithEllipsis(t *testing.T) {
+	tt := []struct {
+		text     string
+		maxRunes int
+		expected string
+	}{
+		{text: "Save", maxRunes: 10, expected: "Save"},
+		{text: "Save changes now", maxRunes: 16, expected: "Save changes now"},
+		{text: "Save changes now", maxRunes: 12, expected: "Save…"},
+		{text: "Save changes now", maxRunes: 13, expected: "Save changes…"},
+		{text: "Save changes, now", maxRunes: 14, expected: "Save changes…"},
+		{text: "Änderungen speichern", maxRunes: 8, expected: "Änderun…"},
+		{text: "変更を保存します", maxRunes: 5, expected: "変更を保…"},
+		{text: "Save", maxRunes: 0, expected: ""},
+	}
+	for _, tc := range tt {
+		if got := truncateWithEllipsis(tc.text, tc.maxRunes); got != tc.expected {
+			t.Errorf("truncateWithEllipsis(%q, %d) = %q, expected %q", tc.text, tc.maxRunes, got, tc.expected)
+		}
+		if got := truncateWithEllipsis(tc.text, tc.maxRunes); runeLen(got) > tc.maxRunes {
+			t.Errorf("truncateWithEllipsis(%q, %d) is too long", tc.text, tc.maxRunes)
+		}
+	}
+}
+
+func TestClient_TranslateWithMaxLength(t *testing.T) {
+	tt := []struct {
+		name string
+
+		text     string
+		maxRunes int
+		opts     []MaxLengthOption
+
+		expected MaxLengthResult
+		requests int
+	}{
+		{
+			name: "fits",
+
+			text:     "Save",
+			maxRunes: 10,
+
+			expected: MaxLengthResult{Full: "DE:Save", Text: "DE:Save"},
+			requests: 1,
+		},
+		{
+			name: "truncated",
+
+			text:     "Save all changes",
+			maxRunes: 13,
+
+			expected: MaxLengthResult{Full: "DE:Save all changes", Text: "DE:Save all…", Truncated: true},
+			requests: 1,
+		},
+		{
+			name: "shortened source fits",
+
+			text:     "Save all changes",
+			maxRunes: 13,
+			opts: []MaxLengthOption{WithShortenedSource(func(text string) string {
+				return strings.Fields(text)[0]
+			})},
+
+			expected: MaxLengthResult{Full: "DE:Save all changes", Text: "DE:Save", Shortened: true},
+			requests: 2,
+		},
+		{
+			name: "shortened source still too long",
+
+			text:     "Save all changes",
+			maxRunes: 10,
+			opts: []MaxLengthOption{WithShortenedSource(func(text string) string {
+				return "Save changes"
+			})},
+
+			expected: MaxLengthResult{Full: "DE:Save all changes", Text: "DE:Save…", Truncated: true, Shortened: true},
+			requests: 2,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, received, teardown := initTranslateServer(t, prefixTranslations)
+			defer teardown()
+
+			res, err := cli.TranslateWithMaxLength(context.Background(), tc.text, "DE", tc.maxRunes, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *res != tc.expected {
+				t.Fatalf("got %+v, expected %+v", *res, tc.expected)
+			}
+			if len(*received) != tc.requests {
+				t.Fatalf("sent %d requests, expected %d", len(*received), tc.requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package deepl
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// Values of Translation.Source.
+const (
+	SourceAPI          = "api"
+	SourceTM           = "tm"
+	SourceTable        = "table"
+	SourceUntranslated = "untranslated"
+)
+
+// TranslationMemory holds approved translations that take precedence over
+// DeepL. Lookup receives upper-case language codes; src is empty when the
+// request relies on source language detection.
+type TranslationMemory interface {
+	Lookup(text, src, dst string) (string, bool)
+}
+
+// MissHandler decides what happens to texts the TranslationMemory doesn't
+// know.
+type MissHandler int
+
+const (
+	// MissFallThrough sends misses to DeepL.
+	MissFallThrough MissHandler = iota
+	// MissUntranslated returns misses unchanged without calling DeepL.
+	MissUntranslated
+)
+
+// WithTranslationMemory sets Client.TranslationMemory.
+func WithTranslationMemory(tm TranslationMemory) Option {
+	return func(c *Client) error {
+		c.TranslationMemory = tm
+		return nil
+	}
+}
+
+// WithMissHandler sets Client.MissHandler.
+func WithMissHandler(h MissHandler) Option {
+	return func(c *Client) error {
+		c.MissHandler = h
+		return nil
+	}
+}
+
+type memoryKey struct {
+	text, src, dst string
+}
+
+// MapTranslationMemory is a TranslationMemory backed by a map. It is safe for
+// concurrent use and Lookup doesn't allocate.
+type MapTranslationMemory struct {
+	mu      sync.RWMutex
+	entries map[memoryKey]string
+}
+
+func NewMapTranslationMemory() *MapTranslationMemory {
+	return &MapTranslationMemory{entries: make(map[memoryKey]string)}
+}
+
+// Add stores translation as the approved translation of text from src to
+// dst. An empty src matches requests without a source language.
+func (m *MapTranslationMemory) Add(text, src, dst, translation string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[memoryKey{text: text, src: strings.ToUpper(src), dst: strings.ToUpper(dst)}] = translation
+}
+
+func (m *MapTranslationMemory) Lookup(text, src, dst string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	translation, ok := m.entries[memoryKey{text: text, src: src, dst: dst}]
+	return translation, ok
+}
+
+// translateWithMemory answers texts from the TranslationMemory and sends the
+// remaining ones to DeepL according to the MissHandler.
+func (c *Client) translateWithMemory(ctx context.Context, req TranslateRequest, raw *json.RawMessage) (*TranslateResult, error) {
+	canonical := req.canonical()
+	result := &TranslateResult{Translations: make([]Translation, len(canonical.Text))}
+
+	var missTexts []string
+	var missIndexes []int
+	for i, text := range canonical.Text {
+		if translation, ok := c.TranslationMemory.Lookup(text, canonical.SourceLang, canonical.TargetLang); ok {
+			result.Translations[i] = Translation{DetectedSourceLanguage: canonical.SourceLang, Text: translation, Source: SourceTM}
+			continue
+		}
+		if c.MissHandler == MissUntranslated {
+			result.Translations[i] = Translation{Text: text, Source: SourceUntranslated}
+			continue
+		}
+		missTexts = append(missTexts, text)
+		missIndexes = append(missIndexes, i)
+	}
+	if len(missTexts) == 0 {
+		return result, nil
+	}
+
+	missReq := canonical
+	missReq.Text = missTexts
+	var translated *TranslateResult
+	if c.TranslationCache != nil && raw == nil {
+		key := CanonicalRequestHash(missReq)
+		if translations, ok := c.cachedTranslations(key, len(missTexts)); ok {
+			translated = &TranslateResult{Translations: translations}
+			translated.Metadata.Cached = true
+		} else {
+			result.cacheKey, result.cacheIndexes = key, missIndexes
+		}
+	}
+	if translated == nil {
+		var err error
+		if translated, err = c.translate(ctx, &missReq, false, raw); err != nil {
+			return nil, err
+		}
+	}
+	for j, t := range translated.Translations {
+		if j < len(missIndexes) {
+			result.Translations[missIndexes[j]] = t
+		}
+	}
+	result.Metadata = translated.Metadata
+	return result, nil
+}
@@ -0,0 +1,108 @@
+package deepl
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestClient_TranslateWithMemory(t *testing.T) {
+	tt := []struct {
+		name string
+
+		inputTexts       []string
+		inputSourceLang  string
+		inputMissHandler MissHandler
+
+		expectedTranslations []Translation
+		expectedRequestTexts [][]string
+	}{
+		{
+			name: "hits and misses",
+
+			inputTexts:      []string{"hello", "bye", "thanks"},
+			inputSourceLang: "en",
+
+			expectedTranslations: []Translation{
+				{DetectedSourceLanguage: "EN", Text: "ようこそ", Source: SourceTM},
+				{DetectedSourceLanguage: "EN", Text: "JA:bye", Source: SourceAPI},
+				{DetectedSourceLanguage: "EN", Text: "JA:thanks", Source: SourceAPI},
+			},
+			expectedRequestTexts: [][]string{{"bye", "thanks"}},
+		},
+		{
+			name: "all hits skip the api",
+
+			inputTexts:      []string{"hello", "hello"},
+			inputSourceLang: "EN",
+
+			expectedTranslations: []Translation{
+				{DetectedSourceLanguage: "EN", Text: "ようこそ", Source: SourceTM},
+				{DetectedSourceLanguage: "EN", Text: "ようこそ", Source: SourceTM},
+			},
+		},
+		{
+			name: "source language is part of the key",
+
+			inputTexts: []string{"hello"},
+
+			expectedTranslations: []Translation{
+				{DetectedSourceLanguage: "EN", Text: "JA:hello", Source: SourceAPI},
+			},
+			expectedRequestTexts: [][]string{{"hello"}},
+		},
+		{
+			name: "misses left untranslated",
+
+			inputTexts:       []string{"hello", "bye"},
+			inputSourceLang:  "EN",
+			inputMissHandler: MissUntranslated,
+
+			expectedTranslations: []Translation{
+				{DetectedSourceLanguage: "EN", Text: "ようこそ", Source: SourceTM},
+				{Text: "bye", Source: SourceUntranslated},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, received, teardown := initTranslateServer(t, prefixTranslations)
+			defer teardown()
+
+			tm := NewMapTranslationMemory()
+			tm.Add("hello", "en", "ja", "ようこそ")
+			WithTranslationMemory(tm)(cli)
+			WithMissHandler(tc.inputMissHandler)(cli)
+
+			result, err := cli.Translate(context.Background(), TranslateRequest{Text: tc.inputTexts, SourceLang: tc.inputSourceLang, TargetLang: "ja"})
+			if err != nil {
+				t.Fatalf("response error should be nil. got=%s", err.Error())
+			}
+			if !reflect.DeepEqual(result.Translations, tc.expectedTranslations) {
+				t.Fatalf("translations wrong. want=%+v, got=%+v", tc.expectedTranslations, result.Translations)
+			}
+
+			var requestTexts [][]string
+			for _, r := range *received {
+				requestTexts = append(requestTexts, r.Text)
+			}
+			if !reflect.DeepEqual(requestTexts, tc.expectedRequestTexts) {
+				t.Fatalf("requested texts wrong. want=%q, got=%q", tc.expectedRequestTexts, requestTexts)
+			}
+		})
+	}
+}
+
+func TestMapTranslationMemory_LookupMissAllocs(t *testing.T) {
+	tm := NewMapTranslationMemory()
+	tm.Add("hello", "EN", "JA", "ようこそ")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		tm.Lookup("goodbye", "EN", "JA")
+	})
+	if allocs != 0 {
+		t.Fatalf("lookup miss should not allocate. got=%v allocs", allocs)
+	}
+}
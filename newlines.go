@@ -0,0 +1,110 @@
+package deepl
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+var newlineNormalizer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// WithNormalizeNewlines converts \r\n and \r to \n in texts before they are
+// sent.
+func WithNormalizeNewlines() Option {
+	return func(c *Client) error {
+		c.NormalizeNewlines = true
+		return nil
+	}
+}
+
+// WithRestoreNewlines rewrites the line endings of each translation to the
+// dominant convention of its source text.
+func WithRestoreNewlines() Option {
+	return func(c *Client) error {
+		c.RestoreNewlines = true
+		return nil
+	}
+}
+
+func normalizeNewlines(s string) string {
+	if !strings.Contains(s, "\r") {
+		return s
+	}
+	return newlineNormalizer.Replace(s)
+}
+
+// dominantNewline returns the most frequent line ending of s, or "" when s
+// has none.
+func dominantNewline(s string) string {
+	crlf := strings.Count(s, "\r\n")
+	lf := strings.Count(s, "\n") - crlf
+	cr := strings.Count(s, "\r") - crlf
+	switch {
+	case crlf == 0 && lf == 0 && cr == 0:
+		return ""
+	case crlf >= lf && crlf >= cr:
+		return "\r\n"
+	case lf >= cr:
+		return "\n"
+	default:
+		return "\r"
+	}
+}
+
+// restoreNewlines rewrites all line endings of translated to those of source.
+func restoreNewlines(source, translated string) string {
+	nl := dominantNewline(source)
+	if nl == "" {
+		return translated
+	}
+	translated = normalizeNewlines(translated)
+	if nl == "\n" {
+		return translated
+	}
+	return strings.ReplaceAll(translated, "\n", nl)
+}
+
+// TranslateLines translates each line as a text of its own, so that the
+// result has exactly one translation per line regardless of how DeepL
+// handles newlines. Blank lines are returned unchanged without being sent,
+// and lines must not contain line breaks themselves. A response with a
+// different number of translations fails with a *TranslationCountError.
+func (c *Client) TranslateLines(ctx context.Context, lines []string, sourceLang, targetLang string, opts ...TranslateOption) ([]string, error) {
+	// the chunks are one logical call
+	ctx, _ = withRequestID(ctx)
+	translated := make([]string, len(lines))
+	var texts []string
+	var indexes []int
+	for i, line := range lines {
+		if strings.ContainsAny(line, "\r\n") {
+			return nil, xerrors.Errorf("Failed to translate lines: line %d contains a line break", i)
+		}
+		if strings.TrimSpace(line) == "" {
+			translated[i] = line
+			continue
+		}
+		texts = append(texts, line)
+		indexes = append(indexes, i)
+	}
+
+	ids := make([]string, len(indexes))
+	for j, i := range indexes {
+		ids[j] = strconv.Itoa(i)
+	}
+	req := TranslateRequest{SourceLang: sourceLang, TargetLang: targetLang}
+	err := c.runJob(ctx, req, texts, ids, func(start int, res *TranslateResult) error {
+		if start+len(res.Translations) > len(texts) {
+			return &TranslationCountError{Expected: len(texts) - start, Got: len(res.Translations)}
+		}
+		for j, t := range res.Translations {
+			translated[indexes[start+j]] = t.Text
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return translated, nil
+}
@@ -0,0 +1,214 @@
+package deepl
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+func TestClient_Newlines(t *testing.T) {
+	tt := []struct {
+		name string
+
+		normalize      bool
+		restore        bool
+		splitSentences SplitSentences
+		text           string
+
+		expectedSent string
+		expected     string
+	}{
+		{
+			name: "untouched by default",
+
+			text: "one\r\ntwo\nthree",
+
+			expectedSent: "one\r\ntwo\nthree",
+			expected:     "DE:one\r\ntwo\nthree",
+		},
+		{
+			name: "normalize input",
+
+			normalize: true,
+			text:      "one\r\ntwo\rthree",
+
+			expectedSent: "one\ntwo\nthree",
+			expected:     "DE:one\ntwo\nthree",
+		},
+		{
+			name: "restore dominant CRLF",
+
+			normalize: true,
+			restore:   true,
+			text:      "one\r\ntwo\r\nthree\nfour",
+
+			expectedSent: "one\ntwo\nthree\nfour",
+			expected:     "DE:one\r\ntwo\r\nthree\r\nfour",
+		},
+		{
+			name: "restore without normalize",
+
+			restore: true,
+			text:    "one\r\ntwo",
+
+			expectedSent: "one\r\ntwo",
+			expected:     "DE:one\r\ntwo",
+		},
+		{
+			name: "restore LF",
+
+			restore: true,
+			text:    "one\ntwo\nthree\r\n",
+
+			expectedSent: "one\ntwo\nthree\r\n",
+			expected:     "DE:one\ntwo\nthree\n",
+		},
+		{
+			name: "no newline in source",
+
+			restore: true,
+			text:    "one",
+
+			expectedSent: "one",
+			expected:     "DE:one",
+		},
+		{
+			name: "nonewlines with CRLF input",
+
+			normalize:      true,
+			restore:        true,
+			splitSentences: SplitSentencesNoNewlines,
+			text:           "line one.\r\nline two.",
+
+			expectedSent: "line one.\nline two.",
+			expected:     "DE:line one.\r\nline two.",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			// the mock server answers with mixed endings, like DeepL may
+			cli, received, teardown := initTranslateServer(t, func(req TranslateRequest) []Translation {
+				return []Translation{{Text: "DE:" + req.Text[0]}}
+			})
+			defer teardown()
+			cli.NormalizeNewlines = tc.normalize
+			cli.RestoreNewlines = tc.restore
+
+			res, err := cli.Translate(context.Background(), TranslateRequest{
+				Text:           []string{tc.text},
+				TargetLang:     "DE",
+				SplitSentences: tc.splitSentences,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := (*received)[0]
+			if req.Text[0] != tc.expectedSent {
+				t.Fatalf("sent %q, expected %q", req.Text[0], tc.expectedSent)
+			}
+			if req.SplitSentences != tc.splitSentences {
+				t.Fatalf("split_sentences = %q, expected %q", req.SplitSentences, tc.splitSentences)
+			}
+			if res.Translations[0].Text != tc.expected {
+				t.Fatalf("got %q, expected %q", res.Translations[0].Text, tc.expected)
+			}
+		})
+	}
+}
+
+func TestTranslate_NormalizeNewlinesKeepsCallerText(t *testing.T) {
+	cli, _, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	cli.NormalizeNewlines = true
+
+	texts := []string{"a\r\nb"}
+	if _, err := cli.Translate(context.Background(), TranslateRequest{Text: texts, TargetLang: "DE"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(texts[0], "\r\n") {
+		t.Fatalf("caller's text was modified: %q", texts[0])
+	}
+}
+
+func TestTranslate_NewlinesPerSplitMode(t *testing.T) {
+	text := "first line\nsecond line\r\n\nafter a blank line"
+	for _, encoding := range []RequestEncoding{RequestEncodingJSON, RequestEncodingForm} {
+		for _, mode := range []SplitSentences{"", SplitSentencesOff, SplitSentencesOn, SplitSentencesNoNewlines} {
+			var sent []string
+			var sentMode string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Content-Type") == "application/json" {
+					var req TranslateRequest
+					if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+						t.Errorf("failed to decode request body: %s", err.Error())
+					}
+					sent, sentMode = req.Text, string(req.SplitSentences)
+				} else {
+					if err := r.ParseForm(); err != nil {
+						t.Errorf("failed to parse form: %s", err.Error())
+					}
+					sent, sentMode = r.PostForm["text"], r.PostForm.Get("split_sentences")
+				}
+				w.Write([]byte(`{"translations":[{"text":"x"}]}`))
+			}))
+
+			cli, err := New(server.URL, nil, WithAPIKey("test"), WithRequestEncoding(encoding))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = cli.Translate(context.Background(), TranslateRequest{Text: []string{text}, TargetLang: "DE", SplitSentences: mode})
+			server.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sent) != 1 || sent[0] != text || sentMode != string(mode) {
+				t.Fatalf("encoding %d, mode %q: sent %q with split_sentences %q", encoding, mode, sent, sentMode)
+			}
+		}
+	}
+}
+
+func TestClient_TranslateLines(t *testing.T) {
+	cli, received, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	cli.MaxTextsPerRequest = 2
+
+	lines := []string{"one", "", "two", "  ", "three"}
+	got, err := cli.TranslateLines(context.Background(), lines, "EN", "DE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"DE:one", "", "DE:two", "  ", "DE:three"}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Fatalf("got %q, expected %q", got, expected)
+	}
+	if len(*received) != 2 || len((*received)[0].Text) != 2 {
+		t.Fatalf("unexpected requests %+v", *received)
+	}
+
+	if _, err := cli.TranslateLines(context.Background(), []string{"a\nb"}, "EN", "DE"); err == nil {
+		t.Fatal("expected error for a line with a line break")
+	}
+}
+
+func TestClient_TranslateLinesCountMismatch(t *testing.T) {
+	cli, _, teardown := initTranslateServer(t, func(req TranslateRequest) []Translation {
+		return []Translation{{Text: strings.Join(req.Text, "\n")}}
+	})
+	defer teardown()
+
+	_, err := cli.TranslateLines(context.Background(), []string{"one", "two"}, "EN", "DE")
+	var countErr *TranslationCountError
+	if !xerrors.As(err, &countErr) || countErr.Expected != 2 || countErr.Got != 1 {
+		t.Fatalf("expected *TranslationCountError, got %v", err)
+	}
+	if !xerrors.Is(err, ErrUnexpectedResponse) {
+		t.Fatalf("%v doesn't match ErrUnexpectedResponse", err)
+	}
+}
@@ -0,0 +1,66 @@
+package deepl
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// CallObserver is notified of every API call, for metrics and tracing
+// integrations that live outside this module. It must be safe for
+// concurrent use.
+type CallObserver interface {
+	// StartCall is called before the first attempt of a call. The returned
+	// context is used for the call, for example to carry a span, and end is
+	// called once the response was parsed or the call failed.
+	StartCall(ctx context.Context, call CallInfo) (_ context.Context, end func(CallResult))
+}
+
+// CallInfo describes an API call.
+type CallInfo struct {
+	Method string
+	Path   string
+	// Header is sent with every attempt, for example to propagate a trace.
+	Header http.Header
+	// CostTags are the tags of WithCostTag.
+	CostTags []string
+	// RequestID identifies the logical call, see RequestIDFromContext.
+	RequestID string
+}
+
+// CallResult is the outcome of an API call. StatusCode is zero when no
+// response was received.
+type CallResult struct {
+	StatusCode int
+	Attempts   int
+	Duration   time.Duration
+	Err        error
+	// ModelTypeUsed is the model_type_used of a translate response.
+	ModelTypeUsed string
+}
+
+// WithCallObserver sets Client.CallObserver.
+func WithCallObserver(o CallObserver) Option {
+	return func(c *Client) error {
+		c.CallObserver = o
+		return nil
+	}
+}
+
+// modelTypeUsed returns the model that served a translate response decoded
+// into out.
+func modelTypeUsed(out interface{}) string {
+	if capture, ok := out.(*rawCapture); ok {
+		out = capture.out
+	}
+	result, ok := out.(*TranslateResult)
+	if !ok {
+		return ""
+	}
+	for _, t := range result.Translations {
+		if t.ModelTypeUsed != "" {
+			return t.ModelTypeUsed
+		}
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package deepl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+type recordingCallObserver struct {
+	calls   []CallInfo
+	results []CallResult
+}
+
+func (o *recordingCallObserver) StartCall(ctx context.Context, call CallInfo) (context.Context, func(CallResult)) {
+	call.Header.Set("Traceparent", "00-trace-span-01")
+	o.calls = append(o.calls, call)
+	return ctx, func(res CallResult) {
+		o.results = append(o.results, res)
+	}
+}
+
+func TestClient_CallObserver(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Traceparent") != "00-trace-span-01" {
+			t.Errorf("missing trace header")
+		}
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"translations":[{"detected_source_language":"EN","text":"Hallo","model_type_used":"quality_optimized"}]}`))
+	}))
+	defer server.Close()
+
+	o := &recordingCallObserver{}
+	cli, err := New(server.URL, nil, WithAPIKey("test"), WithCallObserver(o))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.MaxRetries = 1
+	cli.RetryBackoff = time.Millisecond
+	if _, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(o.calls) != 1 || o.calls[0].Method != http.MethodPost || o.calls[0].Path != "/v2/translate" {
+		t.Fatalf("unexpected calls %+v", o.calls)
+	}
+	res := o.results[0]
+	if res.StatusCode != http.StatusOK || res.Attempts != 2 || res.Err != nil || res.Duration <= 0 || res.ModelTypeUsed != "quality_optimized" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	if _, err := cli.GetLanguages(context.Background(), LanguageTypeTarget); err == nil {
+		t.Fatal("expected error")
+	}
+	if res := o.results[1]; res.Err == nil || res.Attempts != 1 || res.StatusCode != http.StatusOK || res.ModelTypeUsed != "" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
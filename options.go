@@ -0,0 +1,217 @@
+package deepl
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/proxy"
+	"golang.org/x/xerrors"
+)
+
+// Option configures a Client created by New.
+type Option func(*Client) error
+
+// RequestEncoding selects how translate parameters are sent.
+type RequestEncoding int
+
+const (
+	// RequestEncodingAuto sends JSON for multi-text methods and a form body
+	// for the legacy single-text TranslateSentence.
+	RequestEncodingAuto RequestEncoding = iota
+	// RequestEncodingJSON always sends application/json bodies.
+	RequestEncodingJSON
+	// RequestEncodingForm always sends application/x-www-form-urlencoded
+	// bodies, for older proxies that don't understand JSON requests.
+	RequestEncodingForm
+)
+
+// WithAPIKey sets Client.APIKey instead of reading DEEPL_API_KEY.
+func WithAPIKey(key string) Option {
+	return func(c *Client) error {
+		c.APIKey = key
+		return nil
+	}
+}
+
+// WithNoEnv makes the client ignore environment variables, so that all of
+// its configuration is explicit. New fails with ErrMissingAPIKey unless an
+// API key is given, for example with WithAPIKey, or ProfileCI falls back
+// to pseudo translations. The base URL is always explicit.
+func WithNoEnv() Option {
+	return func(c *Client) error {
+		c.noEnv = true
+		return nil
+	}
+}
+
+// WithTimeout limits every attempt of a request, including reading the
+// response, to d. Like the transport options it needs the client's own
+// HTTPClient.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) error {
+		if d <= 0 {
+			return xerrors.Errorf("Failed to configure timeout: invalid timeout %v", d)
+		}
+		if _, err := c.defaultTransport(); err != nil {
+			return err
+		}
+		c.HTTPClient.Timeout = d
+		return nil
+	}
+}
+
+// WithRetries sets MaxRetries and RetryBackoff. Unlike setting the fields
+// directly, they are kept when New applies the defaults of PlanAuto.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) error {
+		if maxRetries < 0 || backoff <= 0 {
+			return xerrors.Errorf("Failed to configure retries: invalid count %d or backoff %v", maxRetries, backoff)
+		}
+		c.MaxRetries = maxRetries
+		c.RetryBackoff = backoff
+		c.retriesSet = true
+		return nil
+	}
+}
+
+// WithSoftFail makes Translate return the source texts with
+// Metadata.Failed set instead of failing when the error is retryable, such
+// as an outage or rate limiting. Auth and invalid-request errors still fail.
+func WithSoftFail() Option {
+	return func(c *Client) error {
+		c.SoftFail = true
+		return nil
+	}
+}
+
+// WithRequestEncoding overrides the request encoding of translate calls.
+func WithRequestEncoding(encoding RequestEncoding) Option {
+	return func(c *Client) error {
+		c.RequestEncoding = encoding
+		return nil
+	}
+}
+
+// WithFallbackBaseURL sets Client.FallbackBaseURL, for example to reach
+// api.deepl.com directly when a caching proxy in BaseURL is down.
+func WithFallbackBaseURL(rawURL string) Option {
+	return func(c *Client) error {
+		fallbackURL, err := parseBaseURL(rawURL)
+		if err != nil {
+			return xerrors.Errorf("Failed to parse fallback URL: %w", err)
+		}
+		c.FallbackBaseURL = fallbackURL
+		return nil
+	}
+}
+
+// WithHTTPClient sets Client.HTTPClient. It can't be combined with options
+// that configure the default transport, such as WithForceHTTP1.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) error {
+		if c.transport != nil {
+			return xerrors.New("Failed to set HTTPClient: transport options were already applied to the default client")
+		}
+		c.HTTPClient = hc
+		return nil
+	}
+}
+
+// WithForceHTTP1 disables HTTP/2 on the default transport, for proxies that
+// mishandle it.
+func WithForceHTTP1() Option {
+	return func(c *Client) error {
+		t, err := c.defaultTransport()
+		if err != nil {
+			return err
+		}
+		t.ForceAttemptHTTP2 = false
+		// a non-nil empty map keeps net/http from enabling HTTP/2
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		return nil
+	}
+}
+
+// defaultTransport returns the transport of the client's own HTTP client,
+// creating it on first use. It fails if HTTPClient was supplied by the
+// caller, since changing a shared client would affect other users.
+func (c *Client) defaultTransport() (*http.Transport, error) {
+	if c.transport != nil {
+		return c.transport, nil
+	}
+	if c.HTTPClient != http.DefaultClient {
+		return nil, xerrors.New("Failed to configure transport: HTTPClient was supplied by the caller")
+	}
+	c.transport = http.DefaultTransport.(*http.Transport).Clone()
+	c.HTTPClient = &http.Client{Transport: c.transport}
+	return c.transport, nil
+}
+
+// DialerFunc adapts a dial function to a proxy.Dialer for WithDialer.
+type DialerFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+func (f DialerFunc) Dial(network, addr string) (net.Conn, error) {
+	return f(context.Background(), network, addr)
+}
+
+func (f DialerFunc) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return f(ctx, network, addr)
+}
+
+// WithDialer makes the default transport open connections with d, for
+// example a SOCKS5 dialer from golang.org/x/net/proxy.
+func WithDialer(d proxy.Dialer) Option {
+	return func(c *Client) error {
+		t, err := c.defaultTransport()
+		if err != nil {
+			return err
+		}
+		if cd, ok := d.(proxy.ContextDialer); ok {
+			t.DialContext = cd.DialContext
+		} else {
+			t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return d.Dial(network, addr)
+			}
+		}
+		// the dialer decides the route, HTTPS_PROXY would bypass it
+		t.Proxy = nil
+		return nil
+	}
+}
+
+// WithProxyURL sends requests through the proxy at rawURL. socks5, socks5h,
+// http and https proxies are supported. Without it the HTTPS_PROXY and
+// HTTP_PROXY environment variables are respected.
+func WithProxyURL(rawURL string) Option {
+	return func(c *Client) error {
+		proxyURL, err := url.Parse(rawURL)
+		if err != nil {
+			return xerrors.Errorf("Failed to parse proxy URL: %w", err)
+		}
+		if proxyURL.Host == "" {
+			return xerrors.Errorf("Failed to parse proxy URL: missing host in %q", rawURL)
+		}
+
+		switch proxyURL.Scheme {
+		case "http", "https":
+			t, err := c.defaultTransport()
+			if err != nil {
+				return err
+			}
+			t.Proxy = http.ProxyURL(proxyURL)
+			return nil
+		case "socks5", "socks5h":
+			d, err := proxy.FromURL(proxyURL, proxy.Direct)
+			if err != nil {
+				return xerrors.Errorf("Failed to create proxy dialer: %w", err)
+			}
+			return WithDialer(d)(c)
+		default:
+			return xerrors.Errorf("Failed to parse proxy URL: unsupported scheme %q", proxyURL.Scheme)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package deepl
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestWithForceHTTP1(t *testing.T) {
+	var proto int
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proto = r.ProtoMajor
+		w.Write([]byte(`{"character_count":0,"character_limit":0}`))
+	}))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	// the server negotiates HTTP/2 with a client that offers it
+	resp, err := ts.Client().Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Fatalf("test server negotiated HTTP/%d, expected HTTP/2", resp.ProtoMajor)
+	}
+
+	cli, err := New(ts.URL, nil, WithAPIKey(testAPIKey), WithForceHTTP1())
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ts.Certificate())
+	cli.transport.TLSClientConfig = &tls.Config{RootCAs: roots}
+
+	if _, err := cli.GetAccountStatus(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if proto != 1 {
+		t.Fatalf("request used HTTP/%d, expected HTTP/1.1", proto)
+	}
+}
+
+func TestWithForceHTTP1_CustomHTTPClient(t *testing.T) {
+	if _, err := New("https://api.deepl.com", nil, WithHTTPClient(&http.Client{}), WithForceHTTP1()); err == nil {
+		t.Fatal("expected error for WithForceHTTP1 with a custom HTTPClient")
+	}
+	if _, err := New("https://api.deepl.com", nil, WithForceHTTP1(), WithHTTPClient(&http.Client{})); err == nil {
+		t.Fatal("expected error for WithHTTPClient after WithForceHTTP1")
+	}
+}
+
+// startSOCKS5Server starts a SOCKS5 proxy without authentication that
+// supports CONNECT and counts the connections it relays.
+func startSOCKS5Server(t *testing.T) (string, *int32, func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var relayed int32
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				// greeting: version, method count, methods
+				header := make([]byte, 2)
+				if _, err := io.ReadFull(conn, header); err != nil {
+					return
+				}
+				if _, err := io.ReadFull(conn, make([]byte, header[1])); err != nil {
+					return
+				}
+				conn.Write([]byte{5, 0})
+
+				// request: version, command, reserved, address type
+				req := make([]byte, 4)
+				if _, err := io.ReadFull(conn, req); err != nil {
+					return
+				}
+				var host string
+				switch req[3] {
+				case 1:
+					ip := make([]byte, 4)
+					io.ReadFull(conn, ip)
+					host = net.IP(ip).String()
+				case 3:
+					n := make([]byte, 1)
+					io.ReadFull(conn, n)
+					name := make([]byte, n[0])
+					io.ReadFull(conn, name)
+					host = string(name)
+				default:
+					return
+				}
+				port := make([]byte, 2)
+				io.ReadFull(conn, port)
+
+				target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+				if err != nil {
+					conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+					return
+				}
+				defer target.Close()
+				atomic.AddInt32(&relayed, 1)
+				conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+				go io.Copy(target, conn)
+				io.Copy(conn, target)
+			}()
+		}
+	}()
+	return l.Addr().String(), &relayed, func() { l.Close() }
+}
+
+func TestWithProxyURL_SOCKS5(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"character_count":0,"character_limit":0}`))
+	}))
+	defer ts.Close()
+	proxyAddr, relayed, stop := startSOCKS5Server(t)
+	defer stop()
+
+	cli, err := New(ts.URL, nil, WithAPIKey(testAPIKey), WithProxyURL("socks5://"+proxyAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.GetAccountStatus(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(relayed) != 1 {
+		t.Fatalf("proxy relayed %d connections, expected 1", atomic.LoadInt32(relayed))
+	}
+}
+
+func TestWithDialer(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"character_count":0,"character_limit":0}`))
+	}))
+	defer ts.Close()
+
+	var dialed []string
+	var d net.Dialer
+	cli, err := New(ts.URL, nil, WithAPIKey(testAPIKey), WithDialer(DialerFunc(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		return d.DialContext(ctx, network, addr)
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.GetAccountStatus(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(dialed) != 1 || dialed[0] != ts.Listener.Addr().String() {
+		t.Fatalf("unexpected dials %v", dialed)
+	}
+}
+
+func TestWithProxyURL_Invalid(t *testing.T) {
+	for _, rawURL := range []string{"://bad", "socks5://", "ftp://proxy:21", "proxy:1080"} {
+		if _, err := New("https://api.deepl.com", nil, WithProxyURL(rawURL)); err == nil {
+			t.Errorf("expected New to fail for proxy URL %q", rawURL)
+		}
+	}
+	if _, err := New("https://api.deepl.com", nil, WithHTTPClient(&http.Client{}), WithProxyURL("socks5://127.0.0.1:1080")); err == nil {
+		t.Error("expected error for WithProxyURL with a custom HTTPClient")
+	}
+}
+
+func TestWithNoEnv(t *testing.T) {
+	t.Setenv("DEEPL_API_KEY", "env-key")
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = strings.TrimPrefix(r.Header.Get("Authorization"), "DeepL-Auth-Key ")
+		w.Write([]byte(`{"character_count":0,"character_limit":0}`))
+	}))
+	defer ts.Close()
+
+	if _, err := New(ts.URL, nil, WithNoEnv()); err == nil {
+		t.Fatal("expected error without an explicit API key")
+	}
+
+	cli, err := New(ts.URL, nil, WithNoEnv(), WithAPIKey("explicit-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.GetAccountStatus(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got != "explicit-key" {
+		t.Fatalf("API key = %q, expected explicit-key", got)
+	}
+	cli.APIKey = ""
+	if _, err := cli.GetAccountStatus(context.Background()); err == nil || got != "explicit-key" {
+		t.Fatalf("DEEPL_API_KEY read despite WithNoEnv: %v", err)
+	}
+
+	cli, err = New(ts.URL, nil, append(ProfileCI(), WithNoEnv())...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cli.pseudo == nil {
+		t.Fatal("ProfileCI without an explicit key didn't fall back to pseudo translations")
+	}
+}
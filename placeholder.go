@@ -0,0 +1,222 @@
+package deepl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MessageFormatPattern matches java.text.MessageFormat arguments such as {0}
+// or {1,number,#.##}.
+var MessageFormatPattern = regexp.MustCompile(`\{\d+(?:,[^{}]*)?\}`)
+
+// Patterns protected by WithNumberProtection.
+var (
+	ISODateTimePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?)?`)
+	TimePattern        = regexp.MustCompile(`\b\d{1,2}:\d{2}(?::\d{2})?\b`)
+	DecimalPattern     = regexp.MustCompile(`\d+(?:,\d{3})*\.\d+`)
+	IntegerPattern     = regexp.MustCompile(`\d+(?:,\d{3})*`)
+
+	DefaultProtectedPatterns = []*regexp.Regexp{ISODateTimePattern, TimePattern, DecimalPattern, IntegerPattern}
+)
+
+// tagPattern matches markup tags, which are never protected.
+var tagPattern = regexp.MustCompile(`<[^<>]*>`)
+
+// placeholderTag replaces a protected token in the text sent to DeepL. XML
+// tag handling keeps self-closing tags in place.
+var placeholderTagPattern = regexp.MustCompile(`<dlph id="(\d+)"\s*/>`)
+
+// PlaceholderError reports protected tokens missing from a translation.
+type PlaceholderError struct {
+	// Key identifies the text, e.g. a properties key or a text index.
+	Key     string
+	Missing []string
+
+	userMessage
+	// private hides Missing from Error, see WithPrivateErrors
+	private bool
+}
+
+func (e *PlaceholderError) Error() string {
+	missing := make([]string, len(e.Missing))
+	for i, token := range e.Missing {
+		missing[i] = errorText(token, e.private)
+	}
+	return fmt.Sprintf("Placeholders lost in translation of %s: %s", e.Key, strings.Join(missing, ", "))
+}
+
+// protectedText is a text whose placeholders were replaced by tags.
+type protectedText struct {
+	text   string
+	tokens []string
+	// escaped is set when the plain text was XML-escaped for tag handling
+	// and has to be unescaped again.
+	escaped bool
+	// offset is the id of the first token. Placeholder tags that were in
+	// the markup before have lower ids and are left alone.
+	offset int
+}
+
+// protectPlaceholders replaces every match of patterns with a placeholder
+// tag. Plain text is XML-escaped so that it can be sent with XML tag
+// handling; markup is left as it is.
+func protectPlaceholders(text string, patterns []*regexp.Regexp, markup bool) protectedText {
+	var matches [][]int
+	for _, p := range patterns {
+		matches = append(matches, p.FindAllStringIndex(text, -1)...)
+	}
+	if markup {
+		matches = outsideTags(text, matches)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i][0] != matches[j][0] {
+			return matches[i][0] < matches[j][0]
+		}
+		return matches[i][1] > matches[j][1]
+	})
+
+	p := protectedText{escaped: !markup}
+	if markup {
+		for _, m := range placeholderTagPattern.FindAllStringSubmatch(text, -1) {
+			if id, err := strconv.Atoi(m[1]); err == nil && id >= p.offset {
+				p.offset = id + 1
+			}
+		}
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		if m[0] < last {
+			// overlaps a longer match that was already protected
+			continue
+		}
+		b.WriteString(p.escape(text[last:m[0]]))
+		b.WriteString(`<dlph id="` + strconv.Itoa(p.offset+len(p.tokens)) + `"/>`)
+		p.tokens = append(p.tokens, text[m[0]:m[1]])
+		last = m[1]
+	}
+	b.WriteString(p.escape(text[last:]))
+	p.text = b.String()
+	return p
+}
+
+// outsideTags drops the matches that overlap a markup tag, such as digits in
+// attribute values.
+func outsideTags(text string, matches [][]int) [][]int {
+	tags := tagPattern.FindAllStringIndex(text, -1)
+	if len(tags) == 0 {
+		return matches
+	}
+	kept := matches[:0]
+	for _, m := range matches {
+		inTag := false
+		for _, tag := range tags {
+			if m[0] < tag[1] && tag[0] < m[1] {
+				inTag = true
+				break
+			}
+		}
+		if !inTag {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+func (p protectedText) escape(s string) string {
+	if !p.escaped {
+		return s
+	}
+	return html.EscapeString(s)
+}
+
+// restore puts the original tokens back into translated and returns the
+// tokens that didn't come back.
+func (p protectedText) restore(translated string) (string, []string) {
+	found := make([]bool, len(p.tokens))
+	var b strings.Builder
+	last := 0
+	for _, m := range placeholderTagPattern.FindAllStringSubmatchIndex(translated, -1) {
+		b.WriteString(p.unescape(translated[last:m[0]]))
+		id, err := strconv.Atoi(translated[m[2]:m[3]])
+		id -= p.offset
+		switch {
+		case err == nil && id >= 0 && id < len(p.tokens):
+			b.WriteString(p.tokens[id])
+			found[id] = true
+		case err == nil && id < 0:
+			b.WriteString(translated[m[0]:m[1]])
+		}
+		last = m[1]
+	}
+	b.WriteString(p.unescape(translated[last:]))
+
+	var missing []string
+	for i, ok := range found {
+		if !ok {
+			missing = append(missing, p.tokens[i])
+		}
+	}
+	return b.String(), missing
+}
+
+func (p protectedText) unescape(s string) string {
+	if !p.escaped {
+		return s
+	}
+	return html.UnescapeString(s)
+}
+
+// WithNumberProtection keeps numbers, ISO-8601 dates and times verbatim in
+// translations, using DefaultProtectedPatterns.
+func WithNumberProtection() Option {
+	return WithProtectedPatterns(DefaultProtectedPatterns...)
+}
+
+// WithProtectedPatterns keeps every match of patterns verbatim in
+// translations.
+func WithProtectedPatterns(patterns ...*regexp.Regexp) Option {
+	return func(c *Client) error {
+		c.ProtectedPatterns = patterns
+		return nil
+	}
+}
+
+// translateProtected protects c.ProtectedPatterns in r.Text, translates and
+// puts the tokens back. A *PlaceholderError keyed by text index is returned
+// when a token is lost.
+func (c *Client) translateProtected(ctx context.Context, r *TranslateRequest, legacy bool, raw *json.RawMessage) (*TranslateResult, error) {
+	markup := r.TagHandling != ""
+	protected := make([]protectedText, len(r.Text))
+	pr := *r
+	pr.Text = make([]string, len(r.Text))
+	for i, text := range r.Text {
+		protected[i] = protectPlaceholders(text, c.ProtectedPatterns, markup)
+		pr.Text[i] = protected[i].text
+	}
+	if !markup {
+		pr.TagHandling = TagHandlingXML
+	}
+
+	result, err := c.translateUnprotected(ctx, &pr, legacy, raw)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result.Translations {
+		if i >= len(protected) {
+			break
+		}
+		text, missing := protected[i].restore(result.Translations[i].Text)
+		if len(missing) > 0 {
+			return nil, c.localize(&PlaceholderError{Key: strconv.Itoa(i), Missing: missing, private: c.PrivateErrors})
+		}
+		result.Translations[i].Text = text
+	}
+	return result, nil
+}
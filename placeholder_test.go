@@ -0,0 +1,149 @@
+package deepl
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+func TestProtectPlaceholders(t *testing.T) {
+	tt := []struct {
+		name string
+
+		inputText    string
+		inputMarkup  bool
+		translate    func(string) string
+		inputPattern []*regexp.Regexp
+
+		expectedSent    string
+		expectedOutput  string
+		expectedMissing []string
+	}{
+		{
+			name: "message format arguments",
+
+			inputText:    "Hello {0}, you have {1,number} messages",
+			inputPattern: []*regexp.Regexp{MessageFormatPattern},
+			translate:    func(s string) string { return s },
+
+			expectedSent:   `Hello <dlph id="0"/>, you have <dlph id="1"/> messages`,
+			expectedOutput: "Hello {0}, you have {1,number} messages",
+		},
+		{
+			name: "plain text is escaped",
+
+			inputText:    "a < b & {0}",
+			inputPattern: []*regexp.Regexp{MessageFormatPattern},
+			translate:    func(s string) string { return s },
+
+			expectedSent:   `a &lt; b &amp; <dlph id="0"/>`,
+			expectedOutput: "a < b & {0}",
+		},
+		{
+			name: "markup is not escaped",
+
+			inputText:    "<b>{0}</b>",
+			inputMarkup:  true,
+			inputPattern: []*regexp.Regexp{MessageFormatPattern},
+			translate:    func(s string) string { return s },
+
+			expectedSent:   `<b><dlph id="0"/></b>`,
+			expectedOutput: "<b>{0}</b>",
+		},
+		{
+			name: "reordered and lost tokens",
+
+			inputText:    "{0} and {1}",
+			inputPattern: []*regexp.Regexp{MessageFormatPattern},
+			translate:    func(s string) string { return `<dlph id="1"/> und` },
+
+			expectedSent:    `<dlph id="0"/> and <dlph id="1"/>`,
+			expectedOutput:  "{1} und",
+			expectedMissing: []string{"{0}"},
+		},
+		{
+			name: "overlapping patterns keep the longest match",
+
+			inputText:    "{10}",
+			inputPattern: []*regexp.Regexp{regexp.MustCompile(`\d+`), MessageFormatPattern},
+			translate:    func(s string) string { return s },
+
+			expectedSent:   `<dlph id="0"/>`,
+			expectedOutput: "{10}",
+		},
+		{
+			name: "numbers, dates and times",
+
+			inputText:    "1,000 items cost 3.50 on 2024-05-01T10:00:00Z, open 9:30",
+			inputPattern: DefaultProtectedPatterns,
+			translate:    func(s string) string { return s },
+
+			expectedSent:   `<dlph id="0"/> items cost <dlph id="1"/> on <dlph id="2"/>, open <dlph id="3"/>`,
+			expectedOutput: "1,000 items cost 3.50 on 2024-05-01T10:00:00Z, open 9:30",
+		},
+		{
+			name: "digits inside tags and existing placeholders are kept",
+
+			inputText:    `<font color="#FF0000">42</font> <dlph id="0"/>`,
+			inputMarkup:  true,
+			inputPattern: DefaultProtectedPatterns,
+			translate:    func(s string) string { return s },
+
+			expectedSent:   `<font color="#FF0000"><dlph id="1"/></font> <dlph id="0"/>`,
+			expectedOutput: `<font color="#FF0000">42</font> <dlph id="0"/>`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			p := protectPlaceholders(tc.inputText, tc.inputPattern, tc.inputMarkup)
+			if p.text != tc.expectedSent {
+				t.Fatalf("protected text wrong. want=%s, got=%s", tc.expectedSent, p.text)
+			}
+			output, missing := p.restore(tc.translate(p.text))
+			if output != tc.expectedOutput {
+				t.Fatalf("restored text wrong. want=%s, got=%s", tc.expectedOutput, output)
+			}
+			if !reflect.DeepEqual(missing, tc.expectedMissing) {
+				t.Fatalf("missing tokens wrong. want=%q, got=%q", tc.expectedMissing, missing)
+			}
+		})
+	}
+}
+
+func TestClient_TranslateNumberProtection(t *testing.T) {
+	cli, received, teardown := initTranslateServer(t, func(req TranslateRequest) []Translation {
+		// localize whatever numbers DeepL would see
+		return []Translation{{Text: strings.Replace(req.Text[0], "items", "Artikel", 1)}}
+	})
+	defer teardown()
+	cli.ProtectedPatterns = DefaultProtectedPatterns
+
+	res, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"1,000 items & more"}, TargetLang: "DE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Translations[0].Text != "1,000 Artikel & more" {
+		t.Fatalf("unexpected translation %q", res.Translations[0].Text)
+	}
+	req := (*received)[0]
+	if req.Text[0] != `<dlph id="0"/> items &amp; more` || req.TagHandling != TagHandlingXML {
+		t.Fatalf("unexpected request %+v", req)
+	}
+
+	cli, _, teardown = initTranslateServer(t, func(req TranslateRequest) []Translation {
+		return []Translation{{Text: "1.000 Artikel"}}
+	})
+	defer teardown()
+	cli.ProtectedPatterns = DefaultProtectedPatterns
+
+	_, err = cli.Translate(context.Background(), TranslateRequest{Text: []string{"1,000 items"}, TargetLang: "DE"})
+	var perr *PlaceholderError
+	if !xerrors.As(err, &perr) || perr.Key != "0" || !reflect.DeepEqual(perr.Missing, []string{"1,000"}) {
+		t.Fatalf("expected *PlaceholderError for 1,000, got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package deepl
+
+import (
+	"strings"
+	"time"
+)
+
+// Plan is a DeepL API subscription plan.
+type Plan int
+
+const (
+	PlanPro Plan = iota
+	PlanFree
+	// PlanAuto makes WithPlanDefaults detect the plan from the API key with
+	// DetectPlan when the client is created.
+	PlanAuto
+)
+
+// PlanDefaults are the retry, rate limit and concurrency settings applied
+// for a plan. They are plain values that can be inspected and adjusted
+// before being passed to WithPlanSettings.
+type PlanDefaults struct {
+	MaxRetries        int
+	RetryBackoff      time.Duration
+	RequestsPerSecond float64
+	RateBurst         int
+	MaxConcurrency    int
+}
+
+var (
+	// PlanFreeDefaults keep Free keys well below the point where DeepL
+	// starts answering with 429.
+	PlanFreeDefaults = PlanDefaults{
+		MaxRetries:        5,
+		RetryBackoff:      time.Second,
+		RequestsPerSecond: 2,
+		RateBurst:         2,
+		MaxConcurrency:    2,
+	}
+	PlanProDefaults = PlanDefaults{
+		MaxRetries:        3,
+		RetryBackoff:      500 * time.Millisecond,
+		RequestsPerSecond: 20,
+		RateBurst:         20,
+		MaxConcurrency:    16,
+	}
+)
+
+// Defaults returns the default settings of p, those of PlanPro for
+// PlanAuto.
+func (p Plan) Defaults() PlanDefaults {
+	if p == PlanFree {
+		return PlanFreeDefaults
+	}
+	return PlanProDefaults
+}
+
+// DetectPlan returns PlanFree for keys with the ":fx" suffix DeepL gives
+// Free API keys and PlanPro otherwise.
+func DetectPlan(apiKey string) Plan {
+	if strings.HasSuffix(apiKey, ":fx") {
+		return PlanFree
+	}
+	return PlanPro
+}
+
+// WithPlanDefaults applies the retry, rate limit and concurrency defaults
+// of p. Without it or WithPlanSettings, a client has no limits and doesn't
+// retry.
+func WithPlanDefaults(p Plan) Option {
+	if p == PlanAuto {
+		return func(c *Client) error {
+			c.planAuto = true
+			return nil
+		}
+	}
+	return WithPlanSettings(p.Defaults())
+}
+
+// WithPlanSettings applies d to the client.
+func WithPlanSettings(d PlanDefaults) Option {
+	return func(c *Client) error {
+		d.apply(c)
+		c.planAuto = false
+		return nil
+	}
+}
+
+func (d PlanDefaults) apply(c *Client) {
+	c.MaxRetries = d.MaxRetries
+	c.RetryBackoff = d.RetryBackoff
+	c.RequestsPerSecond = d.RequestsPerSecond
+	c.RateBurst = d.RateBurst
+	c.MaxConcurrency = d.MaxConcurrency
+}
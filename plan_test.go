@@ -0,0 +1,151 @@
+package deepl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestDetectPlan(t *testing.T) {
+	tt := []struct {
+		key      string
+		expected Plan
+	}{
+		{key: "0123-abcd:fx", expected: PlanFree},
+		{key: "0123-abcd", expected: PlanPro},
+		{key: "", expected: PlanPro},
+	}
+	for _, tc := range tt {
+		if got := DetectPlan(tc.key); got != tc.expected {
+			t.Errorf("DetectPlan(%q) = %v, expected %v", tc.key, got, tc.expected)
+		}
+	}
+}
+
+func TestNew_PlanDefaults(t *testing.T) {
+	t.Setenv("DEEPL_API_KEY", "dummy:fx")
+	cli, err := New("https://api-free.deepl.com", nil, WithPlanDefaults(PlanAuto))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cli.MaxConcurrency != PlanFreeDefaults.MaxConcurrency || cli.RequestsPerSecond != PlanFreeDefaults.RequestsPerSecond {
+		t.Fatalf("Free key did not get free plan defaults: %+v", cli)
+	}
+
+	cli, err = New("https://api-free.deepl.com", nil, WithPlanDefaults(PlanPro))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cli.MaxConcurrency != PlanProDefaults.MaxConcurrency || cli.MaxRetries != PlanProDefaults.MaxRetries {
+		t.Fatalf("WithPlanDefaults did not override detected plan: %+v", cli)
+	}
+
+	custom := PlanFreeDefaults
+	custom.MaxConcurrency = 1
+	cli, err = New("https://api-free.deepl.com", nil, WithPlanSettings(custom))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cli.MaxConcurrency != 1 {
+		t.Fatalf("MaxConcurrency = %d, expected 1", cli.MaxConcurrency)
+	}
+}
+
+func TestNew_NoPlanDefaults(t *testing.T) {
+	for _, key := range []string{testAPIKey, "dummy:fx"} {
+		cli, err := New("https://api.deepl.com", nil, WithAPIKey(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cli.MaxRetries != 0 || cli.RequestsPerSecond != 0 || cli.RateBurst != 0 || cli.MaxConcurrency != 0 {
+			t.Fatalf("%s: plan defaults applied without WithPlanDefaults: %+v", key, cli.Config())
+		}
+		if cli.initLimits(); cli.limiter != nil || cli.semaphore != nil {
+			t.Fatalf("%s: client has a rate limiter or concurrency cap", key)
+		}
+	}
+}
+
+func TestClient_RateLimit(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"character_count":0,"character_limit":0}`))
+	}))
+	defer ts.Close()
+
+	clock := newFakeClock()
+	cli, err := New(ts.URL, nil, WithAPIKey(testAPIKey), WithClock(clock), WithPlanSettings(PlanDefaults{RequestsPerSecond: 20, RateBurst: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 3)
+	go func() {
+		for i := 0; i < 3; i++ {
+			_, err := cli.GetAccountStatus(context.Background())
+			done <- err
+		}
+	}()
+	// the first request uses the burst, the other two wait 50ms each
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		clock.waitTimers(t, 1)
+		clock.Advance(49 * time.Millisecond)
+		select {
+		case <-done:
+			t.Fatalf("request %d didn't wait for the rate limiter", i+2)
+		default:
+		}
+		clock.Advance(time.Millisecond)
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cli.GetAccountStatus(ctx); err == nil {
+		t.Fatal("expected error for canceled context while rate limited")
+	}
+}
+
+func TestClient_MaxConcurrency(t *testing.T) {
+	var inFlight, peak int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"character_count":0,"character_limit":0}`))
+	}))
+	defer ts.Close()
+
+	cli, err := New(ts.URL, nil, WithAPIKey(testAPIKey), WithPlanSettings(PlanDefaults{MaxConcurrency: 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cli.GetAccountStatus(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if peak > 2 {
+		t.Fatalf("peak concurrency = %d, expected at most 2", peak)
+	}
+}
@@ -0,0 +1,169 @@
+package deepl
+
+import (
+	"container/list"
+	"context"
+	"log"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// KeyProvider returns the API key of a tenant.
+type KeyProvider func(ctx context.Context, tenantID string) (string, error)
+
+// ClientPool hands out one Client per tenant. All clients share the
+// configuration and HTTP transport of the pool; rate limits are applied per
+// tenant since DeepL enforces them per API key. Usage is tracked per tenant,
+// see Usage. The least recently used clients are evicted when the pool is
+// full. Evicted clients keep working for calls that are still in flight.
+type ClientPool struct {
+	rawBaseURL string
+	logger     *log.Logger
+	opts       []Option
+	keys       KeyProvider
+	maxClients int
+	shared     *Client
+
+	mu      sync.Mutex
+	lru     *list.List
+	clients map[string]*poolEntry
+	// usage outlives evicted clients
+	usage map[string]*UsageTracker
+}
+
+type poolEntry struct {
+	tenantID string
+	elem     *list.Element
+	// ready is closed once client or err is set
+	ready  chan struct{}
+	client *Client
+	err    error
+}
+
+// NewClientPool creates a pool of at most maxClients clients. opts are
+// validated once here and applied to every tenant client. The API keys come
+// from keys, so opts need none even with WithNoEnv. WithUsageTracker is
+// refused since a tracker would mix the usage of all tenants.
+func NewClientPool(rawBaseURL string, logger *log.Logger, keys KeyProvider, maxClients int, opts ...Option) (*ClientPool, error) {
+	if keys == nil {
+		return nil, xerrors.New("Failed to create client pool: key provider is nil")
+	}
+	if maxClients < 1 {
+		return nil, xerrors.Errorf("Failed to create client pool: invalid size %d", maxClients)
+	}
+	// the shared client only provides the transport and sends nothing
+	shared, err := New(rawBaseURL, logger, append(append([]Option(nil), opts...), WithAPIKey(AuthKeyPlaceholder))...)
+	if err != nil {
+		return nil, err
+	}
+	if shared.UsageTracker != nil {
+		return nil, xerrors.New("Failed to create client pool: usage is tracked per tenant, use ClientPool.Usage instead of WithUsageTracker")
+	}
+	return &ClientPool{
+		rawBaseURL: rawBaseURL,
+		logger:     shared.Logger,
+		opts:       opts,
+		keys:       keys,
+		maxClients: maxClients,
+		shared:     shared,
+		lru:        list.New(),
+		clients:    make(map[string]*poolEntry),
+		usage:      make(map[string]*UsageTracker),
+	}, nil
+}
+
+// Get returns the client of tenantID, creating it on first use.
+// Concurrent calls for the same new tenant create a single client.
+func (p *ClientPool) Get(ctx context.Context, tenantID string) (*Client, error) {
+	p.mu.Lock()
+	if e, ok := p.clients[tenantID]; ok {
+		p.lru.MoveToFront(e.elem)
+		p.mu.Unlock()
+		select {
+		case <-e.ready:
+			return e.client, e.err
+		case <-ctx.Done():
+			return nil, xerrors.Errorf("Failed to get client: %w", ctx.Err())
+		}
+	}
+	e := &poolEntry{tenantID: tenantID, ready: make(chan struct{})}
+	e.elem = p.lru.PushFront(e)
+	p.clients[tenantID] = e
+	p.evict()
+	p.mu.Unlock()
+
+	e.client, e.err = p.create(ctx, tenantID)
+	close(e.ready)
+	if e.err != nil {
+		// don't cache failures, the next call asks the key provider again
+		p.mu.Lock()
+		if p.clients[tenantID] == e {
+			p.lru.Remove(e.elem)
+			delete(p.clients, tenantID)
+		}
+		p.mu.Unlock()
+		return nil, e.err
+	}
+	return e.client, nil
+}
+
+// Len returns the number of clients in the pool.
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.clients)
+}
+
+// Usage returns the characters translated by the clients of tenantID,
+// including clients that were evicted since.
+func (p *ClientPool) Usage(tenantID string) Usage {
+	p.mu.Lock()
+	tracker, ok := p.usage[tenantID]
+	p.mu.Unlock()
+	if !ok {
+		return NewUsageTracker().Snapshot()
+	}
+	return tracker.Snapshot()
+}
+
+// tracker returns the UsageTracker of tenantID, creating it on first use.
+func (p *ClientPool) tracker(tenantID string) *UsageTracker {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	tracker, ok := p.usage[tenantID]
+	if !ok {
+		tracker = NewUsageTracker()
+		p.usage[tenantID] = tracker
+	}
+	return tracker
+}
+
+// evict drops the least recently used clients beyond maxClients. The caller
+// holds p.mu.
+func (p *ClientPool) evict() {
+	for p.lru.Len() > p.maxClients {
+		e := p.lru.Remove(p.lru.Back()).(*poolEntry)
+		delete(p.clients, e.tenantID)
+	}
+}
+
+func (p *ClientPool) create(ctx context.Context, tenantID string) (*Client, error) {
+	key, err := p.keys(ctx, tenantID)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to get API key of tenant %s: %w", tenantID, err)
+	}
+	if key == "" {
+		return nil, xerrors.Errorf("Failed to get API key of tenant %s: key is empty", tenantID)
+	}
+	opts := append(append([]Option(nil), p.opts...), WithAPIKey(key), WithUsageTracker(p.tracker(tenantID)))
+	c, err := New(p.rawBaseURL, p.logger, opts...)
+	if err != nil {
+		return nil, err
+	}
+	// share connections instead of the transport the options built for
+	// this client
+	c.HTTPClient = p.shared.HTTPClient
+	c.transport = p.shared.transport
+	return c, nil
+}
@@ -0,0 +1,171 @@
+package deepl
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestClientPool(t *testing.T) {
+	var mu sync.Mutex
+	var keysSeen []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keysSeen = append(keysSeen, strings.TrimPrefix(r.Header.Get("Authorization"), "DeepL-Auth-Key "))
+		mu.Unlock()
+		w.Write([]byte(`{"character_count":0,"character_limit":0}`))
+	}))
+	defer ts.Close()
+
+	lookups := map[string]int{}
+	pool, err := NewClientPool(ts.URL, nil, func(ctx context.Context, tenantID string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		lookups[tenantID]++
+		if tenantID == "broken" {
+			return "", errors.New("no key")
+		}
+		return "key-" + tenantID + ":fx", nil
+	}, 2, WithForceHTTP1(), WithPlanDefaults(PlanAuto))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// concurrent Get calls for a new tenant share one client
+	clients := make([]*Client, 8)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c, err := pool.Get(context.Background(), "a")
+			if err != nil {
+				t.Error(err)
+			}
+			clients[i] = c
+		}(i)
+	}
+	wg.Wait()
+	for _, c := range clients {
+		if c != clients[0] {
+			t.Fatal("concurrent Get calls returned different clients")
+		}
+	}
+	a := clients[0]
+	if lookups["a"] != 1 {
+		t.Fatalf("key provider called %d times for tenant a", lookups["a"])
+	}
+	if a.MaxConcurrency != PlanFreeDefaults.MaxConcurrency {
+		t.Fatal("plan was not detected from the tenant key")
+	}
+
+	b, err := pool.Get(context.Background(), "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.HTTPClient != a.HTTPClient {
+		t.Fatal("tenant clients don't share the HTTP client")
+	}
+	if _, err := pool.Get(context.Background(), "c"); err != nil {
+		t.Fatal(err)
+	}
+	if pool.Len() != 2 {
+		t.Fatalf("pool holds %d clients, expected 2", pool.Len())
+	}
+
+	// a was evicted but still works
+	if _, err := a.GetAccountStatus(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.GetAccountStatus(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if keysSeen[0] != "key-a:fx" || keysSeen[1] != "key-b:fx" {
+		t.Fatalf("unexpected keys %v", keysSeen)
+	}
+	if _, err := pool.Get(context.Background(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	if lookups["a"] != 2 {
+		t.Fatalf("evicted tenant was not recreated, %d lookups", lookups["a"])
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := pool.Get(context.Background(), "broken"); err == nil {
+			t.Fatal("expected error for tenant without key")
+		}
+	}
+	if lookups["broken"] != 2 {
+		t.Fatal("key provider failure was cached")
+	}
+}
+
+func TestClientPool_NoEnv(t *testing.T) {
+	t.Setenv("DEEPL_API_KEY", "")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"character_count":0,"character_limit":0}`))
+	}))
+	defer ts.Close()
+
+	keys := func(ctx context.Context, tenantID string) (string, error) {
+		return "key-" + tenantID, nil
+	}
+	pool, err := NewClientPool(ts.URL, nil, keys, 2, WithNoEnv())
+	if err != nil {
+		t.Fatalf("pool without a key should be created. got=%s", err.Error())
+	}
+	cli, err := pool.Get(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cli.APIKey != "key-a" {
+		t.Fatalf("tenant key wrong. want=key-a, got=%s", cli.APIKey)
+	}
+	if _, err := cli.GetAccountStatus(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClientPool_Usage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"translations":[{"detected_source_language":"EN","text":"Hallo"}]}`))
+	}))
+	defer ts.Close()
+
+	keys := func(ctx context.Context, tenantID string) (string, error) {
+		return "key-" + tenantID, nil
+	}
+	if _, err := NewClientPool(ts.URL, nil, keys, 1, WithUsageTracker(NewUsageTracker())); err == nil {
+		t.Fatal("expected error for a usage tracker shared by all tenants")
+	}
+	pool, err := NewClientPool(ts.URL, nil, keys, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a single client fits, so every call evicts the other tenant
+	for _, call := range []struct{ tenantID, text string }{
+		{"a", "Hello"},
+		{"b", "Hi!!"},
+		{"a", "Hey"},
+	} {
+		cli, err := pool.Get(context.Background(), call.tenantID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{call.text}, TargetLang: "DE"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for tenantID, expected := range map[string]int{"a": 8, "b": 4, "unknown": 0} {
+		if got := pool.Usage(tenantID); got.Characters != expected || got.Untagged != expected {
+			t.Errorf("usage of tenant %s wrong. want=%d, got=%+v", tenantID, expected, got)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package deepl
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PostProcessor rewrites a translation before Translate returns it. markup
+// is set when the request had a TagHandling, so the text may contain tags.
+type PostProcessor func(text string, markup bool) string
+
+// WithPostProcessors appends to Client.PostProcessors.
+func WithPostProcessors(p ...PostProcessor) Option {
+	return func(c *Client) error {
+		c.PostProcessors = append(c.PostProcessors, p...)
+		return nil
+	}
+}
+
+// postProcess applies c.PostProcessors to the translations of r.
+func (c *Client) postProcess(r *TranslateRequest, translations []Translation) {
+	markup := r.TagHandling != ""
+	for i := range translations {
+		for _, p := range c.PostProcessors {
+			translations[i].Text = p(translations[i].Text, markup)
+		}
+	}
+}
+
+// EscapeForJSON escapes text for use inside a JSON string literal, without
+// the quotes. Besides quotes, backslashes and control characters it
+// escapes U+2028 and U+2029, which break JavaScript, and <, > and &, which
+// break HTML script blocks. It is lossless except for invalid UTF-8, such
+// as halves of surrogate pairs, which becomes U+FFFD. markup is ignored.
+func EscapeForJSON(text string, markup bool) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b.WriteRune(unicode.ReplacementChar)
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f || r == '<' || r == '>' || r == '&' || r == '\u2028' || r == '\u2029':
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// EscapeForXML escapes text for use as XML 1.0 character data or an
+// attribute value. Without markup every &, <, >, " and ' is escaped. With
+// markup, tags, comments, CDATA sections, character references and the
+// entities predefined by XML are kept, HTML entities such as &nbsp; become
+// their character and only stray characters between them are escaped,
+// including the > of a "]]>" in text. Characters XML can't represent,
+// such as most control characters, are dropped and invalid UTF-8 becomes
+// U+FFFD; otherwise it is lossless.
+func EscapeForXML(text string, markup bool) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	for i := 0; i < len(text); {
+		if markup {
+			if n := markupLength(text[i:]); n > 0 {
+				writeXMLChars(&b, text[i:i+n])
+				i += n
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+		switch r {
+		case '&':
+			if loc := entityPattern.FindStringIndex(text[i-1:]); markup && loc != nil && loc[0] == 0 {
+				b.WriteString(xmlEntity(text[i-1 : i-1+loc[1]]))
+				i += loc[1] - 1
+				continue
+			}
+			b.WriteString("&amp;")
+		case '<':
+			b.WriteString("&lt;")
+		case '>':
+			b.WriteString("&gt;")
+		case '"':
+			if markup {
+				b.WriteRune(r)
+			} else {
+				b.WriteString("&quot;")
+			}
+		case '\'':
+			if markup {
+				b.WriteRune(r)
+			} else {
+				b.WriteString("&apos;")
+			}
+		default:
+			if size == 1 && r == utf8.RuneError {
+				r = unicode.ReplacementChar
+			}
+			if isXMLChar(r) {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+// markupLength returns the length of the tag, comment or CDATA section at
+// the start of s, or 0.
+func markupLength(s string) int {
+	switch {
+	case strings.HasPrefix(s, "<!--"):
+		if end := strings.Index(s[4:], "-->"); end >= 0 {
+			return 4 + end + 3
+		}
+	case strings.HasPrefix(s, "<![CDATA["):
+		if end := strings.Index(s[9:], "]]>"); end >= 0 {
+			return 9 + end + 3
+		}
+	case len(s) > 1 && s[0] == '<' && (s[1] == '/' || s[1] == '?' || s[1] == '!' || isASCIILetter(s[1])):
+		if end := strings.IndexAny(s[1:], "<>"); end >= 0 && s[1+end] == '>' {
+			return 1 + end + 1
+		}
+	}
+	return 0
+}
+
+// xmlEntity returns entity as XML. The entities predefined by XML and
+// character references are kept, HTML entities are replaced with their
+// character and unknown ones are escaped.
+func xmlEntity(entity string) string {
+	switch entity {
+	case "&amp;", "&lt;", "&gt;", "&quot;", "&apos;":
+		return entity
+	}
+	if entity[1] == '#' {
+		var r int64
+		var err error
+		if digits := entity[2 : len(entity)-1]; digits[0] == 'x' || digits[0] == 'X' {
+			r, err = strconv.ParseInt(digits[1:], 16, 32)
+		} else {
+			r, err = strconv.ParseInt(digits, 10, 32)
+		}
+		if err != nil || !isXMLChar(rune(r)) {
+			return ""
+		}
+		return entity
+	}
+	decoded := html.UnescapeString(entity)
+	if decoded == entity {
+		return "&amp;" + entity[1:]
+	}
+	return EscapeForXML(decoded, false)
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// writeXMLChars writes s without the characters XML can't represent.
+func writeXMLChars(b *strings.Builder, s string) {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		if size == 1 && r == utf8.RuneError {
+			r = unicode.ReplacementChar
+		}
+		if isXMLChar(r) {
+			b.WriteRune(r)
+		}
+	}
+}
+
+// isXMLChar reports whether r is allowed in XML 1.0 documents.
+func isXMLChar(r rune) bool {
+	return r == '\t' || r == '\n' || r == '\r' ||
+		r >= 0x20 && r <= 0xd7ff ||
+		r >= 0xe000 && r <= 0xfffd ||
+		r >= 0x10000 && r <= 0x10ffff
+}
+
+// StripControlCharacters removes control characters except tabs and line
+// breaks, such as the ESC starting terminal escape sequences and the NUL
+// ending C strings, as well as invalid UTF-8. It is lossy by design.
+// markup is ignored.
+func StripControlCharacters(text string, markup bool) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return r
+		}
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(text, ""))
+}
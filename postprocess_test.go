@@ -0,0 +1,203 @@
+package deepl
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"golang.org/x/net/context"
+)
+
+// adversarialTranslations are texts a translation could contain that break
+// naive embedding.
+var adversarialTranslations = []string{
+	"",
+	"plain text",
+	`quote " backslash \ slash /`,
+	"line\nbreak\r\ntab\tend",
+	"nul\x00 bell\a escape\x1b[31m red del\x7f",
+	"c1 \u0085 next line \u009b csi",
+	"separators \u2028 and \u2029",
+	"</script><script>alert(1)</script>",
+	"cdata ]]> end ]]",
+	"amp & lt < gt > apos ' quot \"",
+	"entity &amp; &#38; &#x26; &unknown; & amp;",
+	"bom \ufeff zwj \u200d rtl \u202e override",
+	"astral 😀 𝔘 han 漢字",
+	"nonchar \ufffe \uffff replacement �",
+}
+
+func TestEscapeForJSON(t *testing.T) {
+	for _, text := range adversarialTranslations {
+		escaped := EscapeForJSON(text, false)
+		for _, r := range escaped {
+			if r < 0x20 || r == 0x7f || r == '\u2028' || r == '\u2029' || r == '<' {
+				t.Fatalf("%q left %U in %q", text, r, escaped)
+			}
+		}
+		var decoded string
+		if err := json.Unmarshal([]byte(`"`+escaped+`"`), &decoded); err != nil {
+			t.Fatalf("%q escaped to invalid JSON %q: %v", text, escaped, err)
+		}
+		if decoded != text {
+			t.Fatalf("%q not lossless, got %q", text, decoded)
+		}
+	}
+
+	// halves of surrogate pairs, as CESU-8, and stray bytes
+	for _, text := range []string{"a\xed\xa0\x80b", "\xff", "ok\xc3"} {
+		var decoded string
+		if err := json.Unmarshal([]byte(`"`+EscapeForJSON(text, false)+`"`), &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(decoded, "�") || !utf8.ValidString(decoded) {
+			t.Fatalf("%q decoded to %q", text, decoded)
+		}
+	}
+}
+
+// xmlCharData decodes the character data of doc and fails on malformed
+// XML.
+func xmlCharData(t *testing.T, doc string) string {
+	t.Helper()
+	dec := xml.NewDecoder(strings.NewReader(doc))
+	var b strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return b.String()
+		}
+		if err != nil {
+			t.Fatalf("malformed XML %q: %v", doc, err)
+		}
+		if data, ok := tok.(xml.CharData); ok {
+			b.Write(data)
+		}
+	}
+}
+
+func TestEscapeForXML(t *testing.T) {
+	for _, text := range adversarialTranslations {
+		expected := strings.Map(func(r rune) rune {
+			if !isXMLChar(r) {
+				return -1
+			}
+			return r
+		}, text)
+		// encoding/xml normalizes line breaks like any XML parser
+		expected = strings.ReplaceAll(expected, "\r\n", "\n")
+
+		escaped := EscapeForXML(text, false)
+		if got := xmlCharData(t, "<a>"+escaped+"</a>"); got != expected {
+			t.Fatalf("%q as character data decoded to %q", text, got)
+		}
+		if strings.Contains(escaped, "]]>") {
+			t.Fatalf("%q kept ]]> in %q", text, escaped)
+		}
+		var attr struct {
+			V string `xml:"v,attr"`
+		}
+		if err := xml.Unmarshal([]byte(`<a v="`+escaped+`"/>`), &attr); err != nil {
+			t.Fatalf("%q in an attribute: %v", text, err)
+		}
+
+		// tags are kept as they are, even unbalanced ones, but everything
+		// else must be well-formed
+		if !strings.Contains(text, "</") {
+			xmlCharData(t, "<a>"+EscapeForXML(text, true)+"</a>")
+		}
+	}
+
+	if got := EscapeForXML("bad \xff byte", false); got != "bad � byte" {
+		t.Fatalf("invalid UTF-8 escaped to %q", got)
+	}
+}
+
+func TestEscapeForXML_Markup(t *testing.T) {
+	tt := []struct {
+		text string
+
+		expected string
+	}{
+		{
+			text:     `<p class="x">Tom &amp; Jerry <b>bold</b></p>`,
+			expected: `<p class="x">Tom &amp; Jerry <b>bold</b></p>`,
+		},
+		{
+			text:     "<p>1 < 2 & 3 > 2, end ]]> here</p>",
+			expected: "<p>1 &lt; 2 &amp; 3 &gt; 2, end ]]&gt; here</p>",
+		},
+		{
+			text:     `<br/><!-- a < b --><![CDATA[x < y ]]>&#38;<x`,
+			expected: `<br/><!-- a < b --><![CDATA[x < y ]]>&#38;&lt;x`,
+		},
+		{
+			text:     "&nbsp;&LT;&copy;&#0;&#x1F600;&#65;&nosuch;&#99999999999;",
+			expected: "\u00a0&lt;©&#x1F600;&#65;&amp;nosuch;",
+		},
+		{
+			text:     "<p \x1b>\x00it's \"quoted\"</p>",
+			expected: "<p >it's \"quoted\"</p>",
+		},
+	}
+
+	for _, tc := range tt {
+		got := EscapeForXML(tc.text, true)
+		if got != tc.expected {
+			t.Errorf("%q escaped to %q, expected %q", tc.text, got, tc.expected)
+		}
+	}
+}
+
+func TestStripControlCharacters(t *testing.T) {
+	tt := []struct {
+		text string
+
+		expected string
+	}{
+		{text: "plain text", expected: "plain text"},
+		{text: "line\nbreak\r\ntab\t", expected: "line\nbreak\r\ntab\t"},
+		{text: "nul\x00 bell\a escape\x1b[31m del\x7f", expected: "nul bell escape[31m del"},
+		{text: "c1 \u0085\u009b", expected: "c1 "},
+		{text: "bad \xff\xed\xa0\x80 bytes", expected: "bad  bytes"},
+		{text: "kept \u2028 � 😀", expected: "kept \u2028 � 😀"},
+	}
+
+	for _, tc := range tt {
+		if got := StripControlCharacters(tc.text, false); got != tc.expected {
+			t.Errorf("%q stripped to %q, expected %q", tc.text, got, tc.expected)
+		}
+	}
+}
+
+func TestClient_PostProcessors(t *testing.T) {
+	cli, _, teardown := initTranslateServer(t, func(req TranslateRequest) []Translation {
+		return []Translation{{Text: "<b>A & B</b>\x00"}}
+	})
+	defer teardown()
+	if err := WithPostProcessors(StripControlCharacters, EscapeForXML)(cli); err != nil {
+		t.Fatal(err)
+	}
+
+	var delivered string
+	for _, tc := range []struct {
+		tagHandling TagHandling
+		expected    string
+	}{
+		{tagHandling: TagHandlingXML, expected: "<b>A &amp; B</b>"},
+		{expected: "&lt;b&gt;A &amp; B&lt;/b&gt;"},
+	} {
+		res, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"x"}, TargetLang: "DE", TagHandling: tc.tagHandling}, WithTranslationHandler(func(i int, t Translation) {
+			delivered = t.Text
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Translations[0].Text != tc.expected || delivered != tc.expected {
+			t.Fatalf("got %q, delivered %q, expected %q", res.Translations[0].Text, delivered, tc.expected)
+		}
+	}
+}
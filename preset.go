@@ -0,0 +1,52 @@
+package deepl
+
+import "time"
+
+// Presets return fresh option slices for common scenarios. Options appended
+// to them take precedence.
+
+// ProfileInteractive suits requests a user waits for: every attempt times
+// out after 10 seconds, failures are retried twice with a short backoff and
+// translate calls give up after 30 seconds.
+func ProfileInteractive() []Option {
+	return []Option{
+		WithTimeout(10 * time.Second),
+		WithRetries(2, 200*time.Millisecond),
+		withPresetDefaults(WithCallTimeout(30 * time.Second)),
+	}
+}
+
+// ProfileBatch suits bulk jobs: attempts may take two minutes, retries wait
+// out longer outages and TranslateText calls are coalesced into full
+// requests. The request rate follows the plan defaults and 429 responses
+// are retried after their Retry-After.
+func ProfileBatch() []Option {
+	return []Option{
+		WithTimeout(2 * time.Minute),
+		WithPlanDefaults(PlanAuto),
+		WithRetries(6, 2*time.Second),
+		WithMicroBatching(100*time.Millisecond, maxTextsPerRequest),
+	}
+}
+
+// ProfileCI suits test runs: without an API key translations come from a
+// PseudoTranslator, and with one calls fail fast instead of being retried.
+func ProfileCI() []Option {
+	return []Option{
+		WithTimeout(30 * time.Second),
+		WithRetries(0, defaultRetryBackoff),
+		func(c *Client) error {
+			c.pseudoWithoutKey = true
+			return nil
+		},
+	}
+}
+
+// hasAPIKey reports whether requests would be sent with an API key.
+func (c *Client) hasAPIKey() bool {
+	if c.APIKey != "" || c.APIKeyProvider != nil {
+		return true
+	}
+	_, err := c.envAPIKey()
+	return err == nil
+}
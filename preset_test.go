@@ -0,0 +1,183 @@
+package deepl
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestPresets(t *testing.T) {
+	tt := []struct {
+		name string
+
+		opts []Option
+
+		expected string
+	}{
+		{
+			name: "interactive",
+
+			opts: ProfileInteractive(),
+
+			expected: `BaseURL: https://api.deepl.com
+APIKey: REDACTED (option)
+Timeout: 10s
+MaxRetries: 2
+RetryBackoff: 200ms
+RequestsPerSecond: 0
+RateBurst: 0
+MaxConcurrency: 0
+RequestEncoding: auto
+Wire: auth=header translate_encoding=json legacy_encoding=form api_path=/v2 fallback=false force_http1=false idempotency_keys=false normalize_newlines=false placeholders=false pseudo=false
+TranslationCache: none
+TranslationMemory: none
+SoftFail: false
+`,
+		},
+		{
+			name: "batch",
+
+			opts: ProfileBatch(),
+
+			expected: `BaseURL: https://api.deepl.com
+APIKey: REDACTED (option)
+Timeout: 2m0s
+MaxRetries: 6
+RetryBackoff: 2s
+RequestsPerSecond: 20
+RateBurst: 20
+MaxConcurrency: 16
+MicroBatching: 50 texts or 100ms
+RequestEncoding: auto
+Wire: auth=header translate_encoding=json legacy_encoding=form api_path=/v2 fallback=false force_http1=false idempotency_keys=false normalize_newlines=false placeholders=false pseudo=false
+TranslationCache: none
+TranslationMemory: none
+SoftFail: false
+`,
+		},
+		{
+			name: "ci with key",
+
+			opts: ProfileCI(),
+
+			expected: `BaseURL: https://api.deepl.com
+APIKey: REDACTED (option)
+Timeout: 30s
+MaxRetries: 0
+RetryBackoff: 500ms
+RequestsPerSecond: 0
+RateBurst: 0
+MaxConcurrency: 0
+RequestEncoding: auto
+Wire: auth=header translate_encoding=json legacy_encoding=form api_path=/v2 fallback=false force_http1=false idempotency_keys=false normalize_newlines=false placeholders=false pseudo=false
+TranslationCache: none
+TranslationMemory: none
+SoftFail: false
+`,
+		},
+		{
+			name: "extended",
+
+			opts: append(ProfileInteractive(), WithRetries(1, time.Second), WithTranslationCache(&mapCache{})),
+
+			expected: `BaseURL: https://api.deepl.com
+APIKey: REDACTED (option)
+Timeout: 10s
+MaxRetries: 1
+RetryBackoff: 1s
+RequestsPerSecond: 0
+RateBurst: 0
+MaxConcurrency: 0
+RequestEncoding: auto
+Wire: auth=header translate_encoding=json legacy_encoding=form api_path=/v2 fallback=false force_http1=false idempotency_keys=false normalize_newlines=false placeholders=false pseudo=false
+TranslationCache: *deepl.mapCache
+TranslationMemory: none
+SoftFail: false
+`,
+		},
+		{
+			name: "call defaults",
+
+			opts: append(ProfileInteractive(), WithDefaultLanguages("", "de"), WithCallDefaults(WithFormality(FormalityMore), WithGlossary("g1"))),
+
+			expected: `BaseURL: https://api.deepl.com
+APIKey: REDACTED (option)
+Timeout: 10s
+MaxRetries: 2
+RetryBackoff: 200ms
+RequestsPerSecond: 0
+RateBurst: 0
+MaxConcurrency: 0
+RequestEncoding: auto
+Wire: auth=header translate_encoding=json legacy_encoding=form api_path=/v2 fallback=false force_http1=false idempotency_keys=false normalize_newlines=false placeholders=false pseudo=false
+TranslationCache: none
+TranslationMemory: none
+DefaultLanguages: none -> DE
+CallDefaults: formality=more&glossary_id=g1 timeout=0s soft_fail=false
+SoftFail: false
+`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, err := New("https://api.deepl.com", nil, append(tc.opts, WithAPIKey("secret"))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := cli.ExplainConfig()
+			if got != tc.expected {
+				t.Fatalf("got\n%s\nexpected\n%s", got, tc.expected)
+			}
+			if strings.Contains(got, "secret") {
+				t.Fatal("API key not redacted")
+			}
+		})
+	}
+}
+
+func TestProfileCIWithoutKey(t *testing.T) {
+	t.Setenv("DEEPL_API_KEY", "")
+	os.Unsetenv("DEEPL_API_KEY")
+
+	cli, err := New("http://127.0.0.1:1", nil, ProfileCI()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(cli.ExplainConfig(), "APIKey: unset\nTranslator: pseudo\n") {
+		t.Fatalf("unexpected config\n%s", cli.ExplainConfig())
+	}
+	result, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Translations[0].Text == "Hello" {
+		t.Fatal("expected a pseudo-translation")
+	}
+
+	t.Setenv("DEEPL_API_KEY", "dummy:fx")
+	cli, err = New("http://127.0.0.1:1", nil, ProfileCI()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(cli.ExplainConfig(), "APIKey: REDACTED:fx (DEEPL_API_KEY)\nTimeout") {
+		t.Fatalf("unexpected config\n%s", cli.ExplainConfig())
+	}
+}
+
+func TestWithRetriesKeepsPlanRate(t *testing.T) {
+	cli, err := New("https://api.deepl.com", nil, WithAPIKey("key:fx"), WithPlanDefaults(PlanAuto), WithRetries(1, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cli.MaxRetries != 1 || cli.RetryBackoff != time.Second || cli.RequestsPerSecond != PlanFreeDefaults.RequestsPerSecond {
+		t.Fatalf("unexpected settings %+v", cli)
+	}
+	if _, err := New("https://api.deepl.com", nil, WithTimeout(time.Second), WithHTTPClient(&http.Client{})); err == nil {
+		t.Fatal("expected error for WithHTTPClient after WithTimeout")
+	}
+}
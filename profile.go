@@ -0,0 +1,237 @@
+package deepl
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// TranslateOption configures a single translate call.
+type TranslateOption func(*translateCall)
+
+// translateCall holds the request of a call and its per-call settings.
+type translateCall struct {
+	req *TranslateRequest
+	// raw receives the response body when set by WithRawResponse
+	raw *json.RawMessage
+	// softFail enables soft-fail mode for this call
+	softFail bool
+	// idempotencyKey is set by WithIdempotencyKey
+	idempotencyKey string
+	// timeout is set by WithCallTimeout
+	timeout time.Duration
+	// onTranslation is set by WithTranslationHandler
+	onTranslation func(index int, t Translation)
+}
+
+// WithFormality sets TranslateRequest.Formality.
+func WithFormality(f Formality) TranslateOption {
+	return func(c *translateCall) { c.req.Formality = f }
+}
+
+// WithGlossary sets TranslateRequest.GlossaryID.
+func WithGlossary(glossaryID string) TranslateOption {
+	return func(c *translateCall) { c.req.GlossaryID = glossaryID }
+}
+
+// WithSplitSentences sets TranslateRequest.SplitSentences.
+func WithSplitSentences(s SplitSentences) TranslateOption {
+	return func(c *translateCall) { c.req.SplitSentences = s }
+}
+
+// WithTagHandling sets TranslateRequest.TagHandling.
+func WithTagHandling(t TagHandling) TranslateOption {
+	return func(c *translateCall) { c.req.TagHandling = t }
+}
+
+// WithModelType sets TranslateRequest.ModelType.
+func WithModelType(m ModelType) TranslateOption {
+	return func(c *translateCall) { c.req.ModelType = m }
+}
+
+// WithPreserveFormatting sets TranslateRequest.PreserveFormatting.
+func WithPreserveFormatting() TranslateOption {
+	return func(c *translateCall) { c.req.PreserveFormatting = true }
+}
+
+// WithRawResponse stores the raw JSON body of a successful response in raw,
+// next to the decoded result, for fields this package doesn't model yet.
+// Bodies larger than 1 MiB are not kept.
+func WithRawResponse(raw *json.RawMessage) TranslateOption {
+	return func(c *translateCall) { c.raw = raw }
+}
+
+// WithCallSoftFail enables soft-fail mode, see WithSoftFail, for one call.
+func WithCallSoftFail() TranslateOption {
+	return func(c *translateCall) { c.softFail = true }
+}
+
+// WithCallTimeout limits a call, including its retries, to d.
+func WithCallTimeout(d time.Duration) TranslateOption {
+	return func(c *translateCall) { c.timeout = d }
+}
+
+// callSettings are the options of a pair profile, the client defaults or a
+// preset. Options that only make sense for a single call, such as
+// WithRawResponse, are dropped.
+type callSettings struct {
+	req      TranslateRequest
+	timeout  time.Duration
+	softFail bool
+}
+
+// apply collects opts on top of s.
+func (s callSettings) apply(opts []TranslateOption) callSettings {
+	call := translateCall{req: &s.req, timeout: s.timeout, softFail: s.softFail}
+	for _, opt := range opts {
+		opt(&call)
+	}
+	s.req = s.req.canonical()
+	s.timeout, s.softFail = call.timeout, call.softFail
+	return s
+}
+
+// fill sets the parameters and settings call leaves unset from s.
+func (call *translateCall) fill(s callSettings) {
+	req := call.req
+	if req.SplitSentences == "" {
+		req.SplitSentences = s.req.SplitSentences
+	}
+	if !req.PreserveFormatting {
+		req.PreserveFormatting = s.req.PreserveFormatting
+	}
+	if req.Formality == "" {
+		req.Formality = s.req.Formality
+	}
+	if req.GlossaryID == "" {
+		req.GlossaryID = s.req.GlossaryID
+	}
+	if req.TagHandling == "" {
+		req.TagHandling = s.req.TagHandling
+	}
+	if req.ModelType == "" {
+		req.ModelType = s.req.ModelType
+	}
+	if call.timeout == 0 {
+		call.timeout = s.timeout
+	}
+	call.softFail = call.softFail || s.softFail
+}
+
+// WithCallDefaults sets options applied to every translate call. Repeated
+// uses add to the defaults.
+func WithCallDefaults(opts ...TranslateOption) Option {
+	return func(c *Client) error {
+		c.callDefaults = c.callDefaults.apply(opts)
+		return c.callDefaults.req.validateEnums()
+	}
+}
+
+// WithDefaultLanguages sets the source and target language of translate
+// calls whose request leaves them empty. Either may be empty. The defaults
+// are applied before the variant preference and the pair profiles.
+func WithDefaultLanguages(source, target string) Option {
+	return func(c *Client) error {
+		c.callDefaults.req.SourceLang = strings.ToUpper(strings.TrimSpace(source))
+		c.callDefaults.req.TargetLang = strings.ToUpper(strings.TrimSpace(target))
+		return nil
+	}
+}
+
+// withPresetDefaults sets the call options of a preset, which any other
+// source of options overrides.
+func withPresetDefaults(opts ...TranslateOption) Option {
+	return func(c *Client) error {
+		c.presetDefaults = c.presetDefaults.apply(opts)
+		return nil
+	}
+}
+
+// ResolvedOptions are the effective options of a translate call.
+type ResolvedOptions struct {
+	Request  TranslateRequest
+	Timeout  time.Duration
+	SoftFail bool
+}
+
+// ResolveOptions returns the options Translate would use for req and opts.
+// From highest to lowest precedence they come from opts, the fields of req,
+// the matching pair profile, WithCallDefaults and the preset. Empty
+// languages are taken from WithDefaultLanguages. Formality
+// downgrades, which need the language listing, are not applied.
+func (c *Client) ResolveOptions(req TranslateRequest, opts ...TranslateOption) ResolvedOptions {
+	call := c.resolveCall(&req, opts)
+	return ResolvedOptions{Request: req, Timeout: call.timeout, SoftFail: call.softFail || c.SoftFail}
+}
+
+// resolveCall merges all sources of options into req, in the order
+// documented by ResolveOptions, and returns the per-call settings.
+func (c *Client) resolveCall(req *TranslateRequest, opts []TranslateOption) translateCall {
+	if req.SourceLang == "" {
+		req.SourceLang = c.callDefaults.req.SourceLang
+	}
+	if req.TargetLang == "" {
+		req.TargetLang = c.callDefaults.req.TargetLang
+	}
+	target := req.TargetLang
+	req.TargetLang = c.preferredVariant(target)
+	call := translateCall{req: req}
+	// a profile of the variant wins over one of the target it replaced
+	profile, ok := c.pairProfile(req.SourceLang, req.TargetLang)
+	if !ok && req.TargetLang != target {
+		profile, ok = c.pairProfile(req.SourceLang, target)
+	}
+	if ok {
+		call.fill(profile)
+	}
+	call.fill(c.callDefaults)
+	call.fill(c.presetDefaults)
+	for _, opt := range opts {
+		opt(&call)
+	}
+	return call
+}
+
+type pairKey struct {
+	src, dst string
+}
+
+// WithPairProfile registers options that are applied to every request from
+// src to dst. src may be "*" to match any source language, including
+// automatic detection; a profile for the exact pair takes precedence.
+// Parameters set on the request itself always win.
+func WithPairProfile(src, dst string, opts ...TranslateOption) Option {
+	return func(c *Client) error {
+		// profiles are copied on write so that requests in flight never
+		// see a map being modified
+		profiles := make(map[pairKey]callSettings, len(c.pairProfiles)+1)
+		for k, v := range c.pairProfiles {
+			profiles[k] = v
+		}
+		settings := callSettings{}.apply(opts)
+		if err := settings.req.validateEnums(); err != nil {
+			return err
+		}
+		profiles[newPairKey(src, dst)] = settings
+		c.pairProfiles = profiles
+		return nil
+	}
+}
+
+func newPairKey(src, dst string) pairKey {
+	return pairKey{src: strings.ToUpper(strings.TrimSpace(src)), dst: strings.ToUpper(strings.TrimSpace(dst))}
+}
+
+// pairProfile returns the profile matching src and dst.
+func (c *Client) pairProfile(src, dst string) (callSettings, bool) {
+	if len(c.pairProfiles) == 0 {
+		return callSettings{}, false
+	}
+	key := newPairKey(src, dst)
+	profile, ok := c.pairProfiles[key]
+	if !ok {
+		key.src = "*"
+		profile, ok = c.pairProfiles[key]
+	}
+	return profile, ok
+}
@@ -0,0 +1,244 @@
+package deepl
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+func TestClient_PairProfile(t *testing.T) {
+	tt := []struct {
+		name string
+
+		req  TranslateRequest
+		opts []TranslateOption
+
+		expected TranslateRequest
+	}{
+		{
+			name: "exact pair",
+
+			req: TranslateRequest{SourceLang: "en", TargetLang: "de"},
+
+			expected: TranslateRequest{SourceLang: "EN", TargetLang: "DE", GlossaryID: "en-de", Formality: FormalityLess},
+		},
+		{
+			name: "wildcard source",
+
+			req: TranslateRequest{SourceLang: "FR", TargetLang: "ja"},
+
+			expected: TranslateRequest{SourceLang: "FR", TargetLang: "JA", Formality: FormalityDefault},
+		},
+		{
+			name: "wildcard matches detected source",
+
+			req: TranslateRequest{TargetLang: "ZH"},
+
+			expected: TranslateRequest{TargetLang: "ZH", SplitSentences: SplitSentencesNoNewlines},
+		},
+		{
+			name: "request fields win",
+
+			req: TranslateRequest{SourceLang: "EN", TargetLang: "DE", GlossaryID: "mine"},
+
+			expected: TranslateRequest{SourceLang: "EN", TargetLang: "DE", GlossaryID: "mine", Formality: FormalityLess},
+		},
+		{
+			name: "per-call options win",
+
+			req:  TranslateRequest{SourceLang: "EN", TargetLang: "DE"},
+			opts: []TranslateOption{WithFormality(FormalityMore)},
+
+			expected: TranslateRequest{SourceLang: "EN", TargetLang: "DE", GlossaryID: "en-de", Formality: FormalityMore},
+		},
+		{
+			name: "no match",
+
+			req: TranslateRequest{SourceLang: "EN", TargetLang: "FR"},
+
+			expected: TranslateRequest{SourceLang: "EN", TargetLang: "FR"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, received, teardown := initTranslateServer(t, prefixTranslations)
+			defer teardown()
+			for _, opt := range []Option{
+				WithPairProfile("*", "JA", WithFormality(FormalityDefault)),
+				WithPairProfile("*", "zh", WithSplitSentences(SplitSentencesNoNewlines)),
+				WithPairProfile("*", "DE", WithFormality(FormalityPreferMore)),
+				WithPairProfile("en", "de", WithGlossary("en-de"), WithFormality(FormalityLess)),
+			} {
+				if err := opt(cli); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tc.req.Text = []string{"Hello"}
+			if _, err := cli.Translate(context.Background(), tc.req, tc.opts...); err != nil {
+				t.Fatal(err)
+			}
+			got := (*received)[0]
+			got.Text = nil
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("got %+v, expected %+v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestClient_ResolveOptionsPrecedence(t *testing.T) {
+	type layer int
+	const (
+		preset layer = iota
+		clientDefault
+		pairProfile
+		request
+		perCall
+		layers
+	)
+	tt := []struct {
+		name string
+
+		// option returns the option setting the value of l, request fields
+		// are set by field
+		option func(l layer) TranslateOption
+		field  func(req *TranslateRequest, l layer)
+		get    func(r ResolvedOptions) interface{}
+		value  func(l layer) interface{}
+	}{
+		{
+			name: "formality",
+
+			option: func(l layer) TranslateOption { return WithFormality(Formalities()[l]) },
+			field:  func(req *TranslateRequest, l layer) { req.Formality = Formalities()[l] },
+			get:    func(r ResolvedOptions) interface{} { return r.Request.Formality },
+			value:  func(l layer) interface{} { return Formalities()[l] },
+		},
+		{
+			name: "glossary",
+
+			option: func(l layer) TranslateOption { return WithGlossary(fmt.Sprint("glossary-", l)) },
+			field:  func(req *TranslateRequest, l layer) { req.GlossaryID = fmt.Sprint("glossary-", l) },
+			get:    func(r ResolvedOptions) interface{} { return r.Request.GlossaryID },
+			value:  func(l layer) interface{} { return fmt.Sprint("glossary-", l) },
+		},
+		{
+			name: "timeout",
+
+			option: func(l layer) TranslateOption { return WithCallTimeout(time.Duration(l+1) * time.Second) },
+			get:    func(r ResolvedOptions) interface{} { return r.Timeout },
+			value:  func(l layer) interface{} { return time.Duration(l+1) * time.Second },
+		},
+	}
+
+	for _, tc := range tt {
+		// every combination of layers setting the option
+		for set := 0; set < 1<<layers; set++ {
+			var opts, callOpts []Option
+			var call []TranslateOption
+			req := TranslateRequest{SourceLang: "EN", TargetLang: "DE"}
+			expected := interface{}(nil)
+			for l := preset; l < layers; l++ {
+				if set&(1<<l) == 0 {
+					continue
+				}
+				if l == request && tc.field == nil {
+					continue
+				}
+				expected = tc.value(l)
+				switch l {
+				case preset:
+					opts = append(opts, withPresetDefaults(tc.option(l)))
+				case clientDefault:
+					opts = append(opts, WithCallDefaults(tc.option(l)))
+				case pairProfile:
+					callOpts = append(callOpts, WithPairProfile("EN", "DE", tc.option(l)))
+				case request:
+					tc.field(&req, l)
+				case perCall:
+					call = append(call, tc.option(l))
+				}
+			}
+			if expected == nil {
+				continue
+			}
+			// the order of client options doesn't matter
+			cli, err := New("https://api.deepl.com", nil, append(append(callOpts, opts...), WithAPIKey("test"))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tc.get(cli.ResolveOptions(req, call...)); got != expected {
+				t.Errorf("%s with layers %05b: got %v, expected %v", tc.name, set, got, expected)
+			}
+		}
+	}
+}
+
+func TestClient_DefaultLanguages(t *testing.T) {
+	cli, err := New("https://api.deepl.com", nil, WithAPIKey("test"),
+		WithDefaultLanguages("en", "de"),
+		WithPairProfile("EN", "DE", WithFormality(FormalityMore)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolved := cli.ResolveOptions(TranslateRequest{Text: []string{"Hello"}})
+	if resolved.Request.SourceLang != "EN" || resolved.Request.TargetLang != "DE" || resolved.Request.Formality != FormalityMore {
+		t.Fatalf("defaults not applied before the pair profile: %+v", resolved.Request)
+	}
+	resolved = cli.ResolveOptions(TranslateRequest{Text: []string{"Hello"}, TargetLang: "FR"})
+	if resolved.Request.SourceLang != "EN" || resolved.Request.TargetLang != "FR" || resolved.Request.Formality != "" {
+		t.Fatalf("request languages don't win over the defaults: %+v", resolved.Request)
+	}
+}
+
+func TestClient_PairProfileVariant(t *testing.T) {
+	cli, err := New("https://api.deepl.com", nil, WithAPIKey("test"),
+		WithVariantPreference(map[string]string{"EN": "EN-US", "PT": "PT-BR"}),
+		WithPairProfile("*", "EN", WithFormality(FormalityMore)),
+		WithPairProfile("*", "PT", WithFormality(FormalityMore)),
+		WithPairProfile("*", "PT-BR", WithFormality(FormalityLess)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		target string
+
+		expectedTarget    string
+		expectedFormality Formality
+	}{
+		{target: "en", expectedTarget: "EN-US", expectedFormality: FormalityMore},
+		{target: "EN-US", expectedTarget: "EN-US"},
+		{target: "PT", expectedTarget: "PT-BR", expectedFormality: FormalityLess},
+	}
+	for _, tc := range tt {
+		resolved := cli.ResolveOptions(TranslateRequest{Text: []string{"Hello"}, TargetLang: tc.target})
+		if resolved.Request.TargetLang != tc.expectedTarget || resolved.Request.Formality != tc.expectedFormality {
+			t.Errorf("%s: got %s with formality %q, expected %s with %q", tc.target, resolved.Request.TargetLang, resolved.Request.Formality, tc.expectedTarget, tc.expectedFormality)
+		}
+	}
+}
+
+func TestClient_CallTimeout(t *testing.T) {
+	cli, _, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	if err := WithCallDefaults(WithCallTimeout(time.Nanosecond))(cli); err != nil {
+		t.Fatal(err)
+	}
+	_, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"})
+	if !xerrors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if _, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"}, WithCallTimeout(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,360 @@
+package deepl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+// propertiesLine is a logical line of a .properties file together with the
+// physical lines it was read from.
+type propertiesLine struct {
+	raw []string
+
+	entry bool
+	// prefix is the key and separator as written in the file.
+	prefix   string
+	key      string
+	value    string
+	modified bool
+}
+
+// Properties is a parsed Java .properties file. Comments, blank lines and
+// unchanged entries are written back byte for byte.
+type Properties struct {
+	lines []propertiesLine
+	crlf  bool
+
+	// EscapeUnicode writes characters outside printable ASCII as \uXXXX
+	// when values are re-encoded, as needed for ISO-8859-1 files. It is
+	// set by ParseProperties unless the file contains raw UTF-8.
+	EscapeUnicode bool
+}
+
+// ParseProperties reads a .properties file encoded in UTF-8 or ISO-8859-1.
+func ParseProperties(r io.Reader) (*Properties, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to read properties: %w", err)
+	}
+
+	latin1 := !utf8.Valid(data)
+	p := &Properties{
+		crlf:          bytes.Contains(data, []byte("\r\n")),
+		EscapeUnicode: latin1 || !hasNonASCII(data),
+	}
+
+	physical := strings.Split(string(data), "\n")
+	if physical[len(physical)-1] == "" {
+		physical = physical[:len(physical)-1]
+	}
+	decode := func(line string) string {
+		line = strings.TrimSuffix(line, "\r")
+		if !latin1 {
+			return line
+		}
+		runes := make([]rune, len(line))
+		for i := 0; i < len(line); i++ {
+			runes[i] = rune(line[i])
+		}
+		return string(runes)
+	}
+
+	for i := 0; i < len(physical); i++ {
+		line := propertiesLine{raw: []string{physical[i]}}
+		logical := decode(physical[i])
+		trimmed := strings.TrimLeft(logical, " \t\f")
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '!' {
+			p.lines = append(p.lines, line)
+			continue
+		}
+
+		for endsWithContinuation(logical) && i+1 < len(physical) {
+			i++
+			line.raw = append(line.raw, physical[i])
+			logical = logical[:len(logical)-1] + strings.TrimLeft(decode(physical[i]), " \t\f")
+		}
+		if endsWithContinuation(logical) {
+			logical = logical[:len(logical)-1]
+		}
+
+		keyStart := len(logical) - len(strings.TrimLeft(logical, " \t\f"))
+		keyEnd := keyStart
+		for keyEnd < len(logical) {
+			c := logical[keyEnd]
+			if c == '\\' {
+				keyEnd += 2
+				continue
+			}
+			if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+				break
+			}
+			keyEnd++
+		}
+		if keyEnd > len(logical) {
+			keyEnd = len(logical)
+		}
+		valueStart := keyEnd
+		for valueStart < len(logical) && strings.IndexByte(" \t\f", logical[valueStart]) >= 0 {
+			valueStart++
+		}
+		if valueStart < len(logical) && (logical[valueStart] == '=' || logical[valueStart] == ':') {
+			valueStart++
+		}
+		for valueStart < len(logical) && strings.IndexByte(" \t\f", logical[valueStart]) >= 0 {
+			valueStart++
+		}
+
+		key, err := unescapeProperty(logical[keyStart:keyEnd])
+		if err != nil {
+			return nil, xerrors.Errorf("Failed to parse properties line %d: %w", i+1, err)
+		}
+		value, err := unescapeProperty(logical[valueStart:])
+		if err != nil {
+			return nil, xerrors.Errorf("Failed to parse properties line %d: %w", i+1, err)
+		}
+		line.entry = true
+		line.prefix = logical[:valueStart]
+		line.key = key
+		line.value = value
+		p.lines = append(p.lines, line)
+	}
+	return p, nil
+}
+
+func hasNonASCII(data []byte) bool {
+	for _, b := range data {
+		if b >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
+
+// endsWithContinuation reports whether line ends with an odd number of
+// backslashes.
+func endsWithContinuation(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", xerrors.New("Malformed \\uxxxx encoding")
+			}
+			code, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", xerrors.New("Malformed \\uxxxx encoding")
+			}
+			// surrogate pairs are written as two escapes
+			r := rune(code)
+			if utf16.IsSurrogate(r) && i+11 <= len(s) && s[i+5:i+7] == `\u` {
+				if low, err := strconv.ParseUint(s[i+7:i+11], 16, 16); err == nil {
+					r = utf16.DecodeRune(r, rune(low))
+					i += 6
+				}
+			}
+			b.WriteRune(r)
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+func escapeProperty(s string, escapeUnicode bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case r == ' ' && i == 0:
+			b.WriteString(`\ `)
+		case r < 0x20 || (escapeUnicode && r > 0x7e):
+			writeUnicodeEscape(&b, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func escapeNonASCII(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r > 0x7e {
+			writeUnicodeEscape(&b, r)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// writeUnicodeEscape writes r as \uXXXX, using a surrogate pair outside the
+// basic multilingual plane.
+func writeUnicodeEscape(b *strings.Builder, r rune) {
+	if r > 0xffff {
+		r1, r2 := utf16.EncodeRune(r)
+		fmt.Fprintf(b, `\u%04X\u%04X`, r1, r2)
+		return
+	}
+	fmt.Fprintf(b, `\u%04X`, r)
+}
+
+// Keys returns the keys in file order.
+func (p *Properties) Keys() []string {
+	var keys []string
+	for _, line := range p.lines {
+		if line.entry {
+			keys = append(keys, line.key)
+		}
+	}
+	return keys
+}
+
+// Get returns the decoded value of key.
+func (p *Properties) Get(key string) (string, bool) {
+	for _, line := range p.lines {
+		if line.entry && line.key == key {
+			return line.value, true
+		}
+	}
+	return "", false
+}
+
+// Set replaces the value of an existing key. It reports whether the key was
+// found.
+func (p *Properties) Set(key, value string) bool {
+	found := false
+	for i := range p.lines {
+		if p.lines[i].entry && p.lines[i].key == key {
+			p.lines[i].value = value
+			p.lines[i].modified = true
+			found = true
+		}
+	}
+	return found
+}
+
+// WriteTo writes p. Modified entries are written on a single line.
+func (p *Properties) WriteTo(w io.Writer) (int64, error) {
+	newline := "\n"
+	if p.crlf {
+		newline = "\r\n"
+	}
+
+	var buf bytes.Buffer
+	for _, line := range p.lines {
+		if !line.modified {
+			for _, raw := range line.raw {
+				buf.WriteString(strings.TrimSuffix(raw, "\r") + newline)
+			}
+			continue
+		}
+		// the prefix is still in its escaped form
+		prefix := line.prefix
+		if p.EscapeUnicode {
+			prefix = escapeNonASCII(prefix)
+		}
+		buf.WriteString(prefix)
+		buf.WriteString(escapeProperty(line.value, p.EscapeUnicode) + newline)
+	}
+	return buf.WriteTo(w)
+}
+
+func (p *Properties) clone() *Properties {
+	out := *p
+	out.lines = append([]propertiesLine(nil), p.lines...)
+	return &out
+}
+
+// TranslateProperties translates the values of p and returns a new
+// Properties with the same keys, comments and layout. Options other than
+// Text are taken from req. MessageFormat arguments such as {0} are kept out
+// of the translation; a *PlaceholderError is returned when one is lost. When
+// the quota runs out, the entries translated so far are returned with a
+// *QuotaExceededError listing the remaining keys.
+func (c *Client) TranslateProperties(ctx context.Context, p *Properties, req TranslateRequest) (*Properties, error) {
+	out := p.clone()
+	patterns := []*regexp.Regexp{MessageFormatPattern}
+
+	var lineIndexes []int
+	var protected []protectedText
+	for i, line := range p.lines {
+		if !line.entry || strings.TrimSpace(line.value) == "" {
+			continue
+		}
+		lineIndexes = append(lineIndexes, i)
+		protected = append(protected, protectPlaceholders(line.value, patterns, false))
+	}
+
+	req.TagHandling = TagHandlingXML
+	texts := make([]string, len(protected))
+	keys := make([]string, len(protected))
+	for i, pt := range protected {
+		texts[i] = pt.text
+		keys[i] = p.lines[lineIndexes[i]].key
+	}
+	err := c.runJob(ctx, req, texts, keys, func(start int, result *TranslateResult) error {
+		for j, t := range result.Translations {
+			lineIndex := lineIndexes[start+j]
+			value, missing := protected[start+j].restore(t.Text)
+			if len(missing) > 0 {
+				return c.localize(&PlaceholderError{Key: p.lines[lineIndex].key, Missing: missing, private: c.PrivateErrors})
+			}
+			out.lines[lineIndex].value = value
+			out.lines[lineIndex].modified = true
+		}
+		return nil
+	})
+	var quotaErr *QuotaExceededError
+	if xerrors.As(err, &quotaErr) {
+		return out, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,171 @@
+package deepl
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+func TestParseProperties(t *testing.T) {
+	input, err := ioutil.ReadFile("testdata/Properties/messages.properties")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %s", err.Error())
+	}
+
+	p, err := ParseProperties(bytes.NewReader(input))
+	if err != nil {
+		t.Fatalf("parse error should be nil. got=%s", err.Error())
+	}
+	if !p.EscapeUnicode {
+		t.Fatalf("ASCII file should escape unicode on write")
+	}
+
+	expectedKeys := []string{"greeting", "cafe", "multi.line", "path with spaces", "emoji", "empty", "tab"}
+	if got := p.Keys(); !reflect.DeepEqual(got, expectedKeys) {
+		t.Fatalf("keys wrong. want=%q, got=%q", expectedKeys, got)
+	}
+
+	tt := []struct {
+		key   string
+		value string
+	}{
+		{key: "cafe", value: "Café opens at {1,time,short}"},
+		{key: "multi.line", value: "This message continues here"},
+		{key: "path with spaces", value: "Save to {0}"},
+		{key: "emoji", value: "Smile 😀"},
+		{key: "empty", value: ""},
+		{key: "tab", value: "separated value"},
+	}
+	for _, tc := range tt {
+		if got, _ := p.Get(tc.key); got != tc.value {
+			t.Fatalf("value of %s wrong. want=%q, got=%q", tc.key, tc.value, got)
+		}
+	}
+
+	var out bytes.Buffer
+	if _, err := p.WriteTo(&out); err != nil {
+		t.Fatalf("write error should be nil. got=%s", err.Error())
+	}
+	if out.String() != string(input) {
+		t.Fatalf("round trip wrong. want=%q, got=%q", input, out.String())
+	}
+}
+
+func TestProperties_WriteModified(t *testing.T) {
+	tt := []struct {
+		name string
+
+		input      string
+		inputValue string
+
+		expectedOutput string
+	}{
+		{name: "escaped", input: "k=v\n", inputValue: " café\ttab\\", expectedOutput: "k=\\ caf\\u00E9\\ttab\\\\\n"},
+		{name: "raw utf-8 file keeps utf-8", input: "k=é\n", inputValue: "ü", expectedOutput: "k=ü\n"},
+		{name: "latin-1 file", input: "k=\xe9\nl=\xe9\n", inputValue: "ü", expectedOutput: "k=\\u00FC\nl=\xe9\n"},
+		{name: "crlf", input: "a=b\r\nk=v\r\n", inputValue: "w", expectedOutput: "a=b\r\nk=w\r\n"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := ParseProperties(strings.NewReader(tc.input))
+			if err != nil {
+				t.Fatalf("parse error should be nil. got=%s", err.Error())
+			}
+			p.Set("k", tc.inputValue)
+
+			var out bytes.Buffer
+			p.WriteTo(&out)
+			if out.String() != tc.expectedOutput {
+				t.Fatalf("output wrong. want=%q, got=%q", tc.expectedOutput, out.String())
+			}
+		})
+	}
+}
+
+func TestParseProperties_Malformed(t *testing.T) {
+	if _, err := ParseProperties(strings.NewReader("k=\\u12\n")); err == nil {
+		t.Fatalf("parse error should not be non-nil. got=nil")
+	}
+}
+
+func TestClient_TranslateProperties(t *testing.T) {
+	cli, received, teardown := initTranslateServer(t, func(req TranslateRequest) []Translation {
+		translations := make([]Translation, len(req.Text))
+		for i, text := range req.Text {
+			// DeepL returns XML-escaped text when tag handling is on
+			translations[i] = Translation{Text: "JA:" + text}
+		}
+		return translations
+	})
+	defer teardown()
+
+	input, err := ioutil.ReadFile("testdata/Properties/messages.properties")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %s", err.Error())
+	}
+	expected, err := ioutil.ReadFile("testdata/Properties/messages-translated.properties")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %s", err.Error())
+	}
+
+	p, err := ParseProperties(bytes.NewReader(input))
+	if err != nil {
+		t.Fatalf("parse error should be nil. got=%s", err.Error())
+	}
+	translated, err := cli.TranslateProperties(context.Background(), p, TranslateRequest{TargetLang: "JA"})
+	if err != nil {
+		t.Fatalf("response error should be nil. got=%s", err.Error())
+	}
+
+	var out bytes.Buffer
+	translated.WriteTo(&out)
+	if out.String() != string(expected) {
+		t.Fatalf("translated properties wrong. want=%q, got=%q", expected, out.String())
+	}
+
+	// the source must not change
+	var original bytes.Buffer
+	p.WriteTo(&original)
+	if original.String() != string(input) {
+		t.Fatalf("source properties modified. got=%q", original.String())
+	}
+
+	r := (*received)[0]
+	if r.TagHandling != TagHandlingXML {
+		t.Fatalf("tag handling wrong. want=%s, got=%s", TagHandlingXML, r.TagHandling)
+	}
+	if r.Text[0] != `Hello <dlph id="0"/>!` {
+		t.Fatalf("placeholder not protected. got=%s", r.Text[0])
+	}
+}
+
+func TestClient_TranslatePropertiesLostPlaceholder(t *testing.T) {
+	cli, _, teardown := initTranslateServer(t, func(req TranslateRequest) []Translation {
+		translations := make([]Translation, len(req.Text))
+		for i := range req.Text {
+			translations[i] = Translation{Text: "lost"}
+		}
+		return translations
+	})
+	defer teardown()
+
+	p, err := ParseProperties(strings.NewReader("greeting=Hello {0}\n"))
+	if err != nil {
+		t.Fatalf("parse error should be nil. got=%s", err.Error())
+	}
+	_, err = cli.TranslateProperties(context.Background(), p, TranslateRequest{TargetLang: "JA"})
+
+	var placeholderErr *PlaceholderError
+	if !xerrors.As(err, &placeholderErr) {
+		t.Fatalf("error should be a *PlaceholderError. got=%v", err)
+	}
+	if placeholderErr.Key != "greeting" || !reflect.DeepEqual(placeholderErr.Missing, []string{"{0}"}) {
+		t.Fatalf("placeholder error wrong. got=%+v", placeholderErr)
+	}
+}
@@ -0,0 +1,131 @@
+package deepl
+
+import (
+	"context"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// Translator translates requests. Client implements it, as does
+// PseudoTranslator for tests and layout checks.
+type Translator interface {
+	Translate(ctx context.Context, req TranslateRequest, opts ...TranslateOption) (*TranslateResult, error)
+}
+
+var (
+	_ Translator = (*Client)(nil)
+	_ Translator = (*PseudoTranslator)(nil)
+)
+
+// MarkerStyle selects the markers around pseudo-translations.
+type MarkerStyle int
+
+const (
+	// MarkerBrackets wraps texts in [ and ].
+	MarkerBrackets MarkerStyle = iota
+	// MarkerGuillemets wraps texts in « and ».
+	MarkerGuillemets
+	// MarkerNone adds no markers.
+	MarkerNone
+)
+
+// PseudoTranslator produces deterministic pseudo-localized text: ASCII
+// letters are replaced with accented ones, the text is padded by Expansion
+// of its length and wrapped in markers, so "Hello" becomes
+// "[Ĥéļļö··]". Markup and entities are left alone when TagHandling is set.
+//
+// To run the client's batching, placeholder and file helpers offline, pass
+// it to WithPseudoTranslation instead of calling it directly.
+type PseudoTranslator struct {
+	// Expansion is the fraction of characters added, 0.3 by
+	// NewPseudoTranslator.
+	Expansion float64
+	Markers   MarkerStyle
+}
+
+// NewPseudoTranslator returns a PseudoTranslator expanding texts by 30%.
+func NewPseudoTranslator() *PseudoTranslator {
+	return &PseudoTranslator{Expansion: 0.3}
+}
+
+// WithPseudoTranslation makes the client answer translate requests with p
+// instead of sending them to DeepL. Everything around the request, such as
+// chunking and placeholder protection, runs as usual.
+func WithPseudoTranslation(p *PseudoTranslator) Option {
+	return func(c *Client) error {
+		c.pseudo = p
+		return nil
+	}
+}
+
+// Translate pseudo-translates req.Text. opts are ignored.
+func (p *PseudoTranslator) Translate(ctx context.Context, req TranslateRequest, opts ...TranslateOption) (*TranslateResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return p.translate(&req), nil
+}
+
+func (p *PseudoTranslator) translate(req *TranslateRequest) *TranslateResult {
+	source := strings.ToUpper(strings.TrimSpace(req.SourceLang))
+	if source == "" {
+		source = "EN"
+	}
+	markup := req.TagHandling != ""
+	result := &TranslateResult{Translations: make([]Translation, len(req.Text))}
+	for i, text := range req.Text {
+		result.Translations[i] = Translation{DetectedSourceLanguage: source, Text: p.Pseudo(text, markup)}
+	}
+	return result
+}
+
+var pseudoLetters = map[rune]rune{
+	'a': 'å', 'b': 'ƀ', 'c': 'ç', 'd': 'ð', 'e': 'é', 'f': 'ƒ', 'g': 'ĝ', 'h': 'ĥ', 'i': 'î',
+	'j': 'ĵ', 'k': 'ķ', 'l': 'ļ', 'm': 'ɱ', 'n': 'ñ', 'o': 'ö', 'p': 'þ', 'q': 'ǫ', 'r': 'ŕ',
+	's': 'š', 't': 'ţ', 'u': 'û', 'v': 'ṽ', 'w': 'ŵ', 'x': 'ẋ', 'y': 'ý', 'z': 'ž',
+	'A': 'Å', 'B': 'Ɓ', 'C': 'Ç', 'D': 'Ð', 'E': 'É', 'F': 'Ƒ', 'G': 'Ĝ', 'H': 'Ĥ', 'I': 'Î',
+	'J': 'Ĵ', 'K': 'Ķ', 'L': 'Ļ', 'M': 'Ṁ', 'N': 'Ñ', 'O': 'Ö', 'P': 'Þ', 'Q': 'Ǫ', 'R': 'Ŕ',
+	'S': 'Š', 'T': 'Ţ', 'U': 'Û', 'V': 'Ṽ', 'W': 'Ŵ', 'X': 'Ẋ', 'Y': 'Ý', 'Z': 'Ž',
+}
+
+// Pseudo returns the pseudo-translation of text. With markup set, tags and
+// entities are copied unchanged and don't count towards the expansion.
+func (p *PseudoTranslator) Pseudo(text string, markup bool) string {
+	open, close := "[", "]"
+	switch p.Markers {
+	case MarkerGuillemets:
+		open, close = "«", "»"
+	case MarkerNone:
+		open, close = "", ""
+	}
+
+	var b strings.Builder
+	b.WriteString(open)
+	visible := 0
+	for i := 0; i < len(text); {
+		if markup && (text[i] == '<' || text[i] == '&') {
+			end := '>'
+			if text[i] == '&' {
+				end = ';'
+			}
+			if j := strings.IndexRune(text[i:], end); j > 0 {
+				b.WriteString(text[i : i+j+1])
+				i += j + 1
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if accented, ok := pseudoLetters[r]; ok {
+			r = accented
+		}
+		b.WriteRune(r)
+		visible++
+		i += size
+	}
+	if pad := int(math.Round(float64(visible) * p.Expansion)); pad > 0 {
+		b.WriteString(strings.Repeat("·", pad))
+	}
+	b.WriteString(close)
+	return b.String()
+}
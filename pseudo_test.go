@@ -0,0 +1,78 @@
+package deepl
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestPseudoTranslator_Pseudo(t *testing.T) {
+	tt := []struct {
+		name string
+
+		p      PseudoTranslator
+		text   string
+		markup bool
+
+		expected string
+	}{
+		{name: "default", p: *NewPseudoTranslator(), text: "Hello", expected: "[Ĥéļļö··]"},
+		{name: "longer text", p: *NewPseudoTranslator(), text: "Save changes", expected: "[Šåṽé çĥåñĝéš····]"},
+		{name: "no expansion", p: PseudoTranslator{Markers: MarkerGuillemets}, text: "Ok!", expected: "«Öķ!»"},
+		{name: "no markers", p: PseudoTranslator{Markers: MarkerNone}, text: "Zoë", expected: "Žöë"},
+		{name: "markup kept", p: PseudoTranslator{}, text: `<b class="x">Hi</b> &amp; <dlph id="0"/>`, markup: true, expected: `[<b class="x">Ĥî</b> &amp; <dlph id="0"/>]`},
+		{name: "markup translated without tag handling", p: PseudoTranslator{}, text: "<b>", expected: "[<ƀ>]"},
+		{name: "empty", p: *NewPseudoTranslator(), expected: "[]"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.p.Pseudo(tc.text, tc.markup); got != tc.expected {
+				t.Fatalf("got %q, expected %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestPseudoTranslator_Translate(t *testing.T) {
+	var tr Translator = NewPseudoTranslator()
+	res, err := tr.Translate(context.Background(), TranslateRequest{Text: []string{"Hello", "Bye"}, TargetLang: "DE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(res.Texts(), "|"); got != "[Ĥéļļö··]|[Ɓýé·]" {
+		t.Fatalf("unexpected texts %q", got)
+	}
+	if res.Translations[0].DetectedSourceLanguage != "EN" {
+		t.Fatalf("unexpected source %q", res.Translations[0].DetectedSourceLanguage)
+	}
+}
+
+func TestClient_PseudoTranslation(t *testing.T) {
+	// no server: requests would fail
+	cli, err := New("http://127.0.0.1:1", nil, WithAPIKey("test"), WithPseudoTranslation(NewPseudoTranslator()), WithNumberProtection(), WithMaxTextsPerRequest(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := cli.TranslateBilingual(context.Background(), TranslateRequest{Text: []string{"Buy 3 apples. Pay 4 euros."}, TargetLang: "DE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Requests != 2 || res.Pairs[0].Target != "[Ɓûý 3 åþþļéš.····]" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	p, err := ParseProperties(strings.NewReader("greeting=Hello {0}, you have {1,number} messages\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	translated, err := cli.TranslateProperties(context.Background(), p, TranslateRequest{TargetLang: "DE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := translated.Get("greeting"); !strings.Contains(got, "{0}") || !strings.Contains(got, "{1,number}") || !strings.HasPrefix(got, "[Ĥéļļö") {
+		t.Fatalf("unexpected value %q", got)
+	}
+}
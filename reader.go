@@ -0,0 +1,42 @@
+package deepl
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+// ErrInvalidUTF8 matches the error of TranslateReader for input that isn't
+// valid UTF-8.
+var ErrInvalidUTF8 = xerrors.New("Text is not valid UTF-8")
+
+// TranslateReader reads all of r and translates it as one text. Input larger
+// than MaxRequestBytes, 128 KiB by default, fails with a
+// *RequestTooLargeError before anything is sent. Reading stops at the first
+// byte over the limit, so r may be endless and Bytes is only a lower bound
+// of its size. Input that isn't valid UTF-8 fails with ErrInvalidUTF8.
+func (c *Client) TranslateReader(ctx context.Context, r io.Reader, sourceLang, targetLang string, opts ...TranslateOption) (string, error) {
+	limit := c.MaxRequestBytes
+	if limit <= 0 || limit > maxRequestBytes {
+		limit = maxRequestBytes
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return "", xerrors.Errorf("Failed to read text: %w", err)
+	}
+	if len(data) > limit {
+		return "", &RequestTooLargeError{Bytes: len(data), Limit: limit, Texts: 1, LargestBytes: len(data)}
+	}
+	if !utf8.Valid(data) {
+		return "", xerrors.Errorf("Failed to translate text: %w", ErrInvalidUTF8)
+	}
+
+	res, err := c.Translate(ctx, TranslateRequest{Text: []string{string(data)}, SourceLang: sourceLang, TargetLang: targetLang}, opts...)
+	if err != nil {
+		return "", err
+	}
+	return res.Translations[0].Text, nil
+}
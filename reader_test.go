@@ -0,0 +1,59 @@
+package deepl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+// endlessReader is an endless stream of spaces counting the bytes read.
+type endlessReader struct {
+	n int
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = ' '
+	}
+	r.n += len(p)
+	return len(p), nil
+}
+
+func TestClient_TranslateReader(t *testing.T) {
+	cli, received, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	cli.MaxRequestBytes = 8
+
+	text, err := cli.TranslateReader(context.Background(), strings.NewReader("Hello"), "EN", "DE", WithFormality(FormalityLess))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "DE:Hello" || (*received)[0].SourceLang != "EN" || (*received)[0].Formality != FormalityLess {
+		t.Fatalf("unexpected translation %q of request %+v", text, (*received)[0])
+	}
+
+	_, err = cli.TranslateReader(context.Background(), strings.NewReader("123456789012"), "", "DE")
+	if !xerrors.Is(err, ErrRequestTooLarge) {
+		t.Fatalf("expected ErrRequestTooLarge, got %v", err)
+	}
+	// reading stops after the limit, so the size is a lower bound
+	if err.Error() != "Request too large: 9 bytes with 1 texts exceeds the limit of 8 bytes, the largest is text 0 with 9 bytes" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	endless := &endlessReader{}
+	if _, err := cli.TranslateReader(context.Background(), endless, "", "DE"); !xerrors.Is(err, ErrRequestTooLarge) {
+		t.Fatalf("expected ErrRequestTooLarge for endless input, got %v", err)
+	}
+	if endless.n > 1024 {
+		t.Fatalf("read %d bytes of endless input", endless.n)
+	}
+	if _, err := cli.TranslateReader(context.Background(), bytes.NewReader([]byte{0xff, 0xfe}), "", "DE"); !xerrors.Is(err, ErrInvalidUTF8) {
+		t.Fatalf("expected ErrInvalidUTF8, got %v", err)
+	}
+	if len(*received) != 1 {
+		t.Fatalf("rejected input was sent: %+v", *received)
+	}
+}
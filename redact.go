@@ -0,0 +1,36 @@
+package deepl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// maxErrorTextRunes is the length of request text quoted in errors.
+const maxErrorTextRunes = 32
+
+var errorTextNewlines = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// WithPrivateErrors keeps request texts out of error messages. Errors then
+// identify texts by index and by a short SHA-256 of the quoted text.
+func WithPrivateErrors() Option {
+	return func(c *Client) error {
+		c.PrivateErrors = true
+		return nil
+	}
+}
+
+// errorText prepares text from a request or translation for an error
+// message: newlines become spaces and long texts are truncated. When private
+// is set only the first bytes of its SHA-256 are returned.
+func errorText(text string, private bool) string {
+	if private {
+		sum := sha256.Sum256([]byte(text))
+		return "sha256:" + hex.EncodeToString(sum[:6])
+	}
+	text = errorTextNewlines.Replace(text)
+	if runeLen(text) > maxErrorTextRunes {
+		text = truncateGraphemes(text, maxErrorTextRunes-1) + ellipsis
+	}
+	return text
+}
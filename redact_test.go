@@ -0,0 +1,78 @@
+package deepl
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestErrorText(t *testing.T) {
+	tt := []struct {
+		name string
+
+		inputText    string
+		inputPrivate bool
+
+		expected string
+	}{
+		{name: "short text", inputText: "{0}", expected: "{0}"},
+		{name: "newlines", inputText: "line 1\r\nline 2\nline 3", expected: "line 1 line 2 line 3"},
+		{name: "truncated", inputText: strings.Repeat("あ", 40), expected: strings.Repeat("あ", 31) + ellipsis},
+		{name: "private", inputText: "1,000", inputPrivate: true, expected: "sha256:66dbae68e4f4"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := errorText(tc.inputText, tc.inputPrivate); got != tc.expected {
+				t.Fatalf("errorText wrong. want=%q, got=%q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestClient_PrivateErrors(t *testing.T) {
+	const secret = "4711"
+	tt := []struct {
+		name string
+
+		configure func(*Client)
+		translate func(TranslateRequest) []Translation
+	}{
+		{
+			name:      "lost placeholder",
+			configure: func(c *Client) { c.ProtectedPatterns = DefaultProtectedPatterns },
+			translate: func(TranslateRequest) []Translation { return []Translation{{Text: "Kunde"}} },
+		},
+		{
+			name:      "unexpected entity",
+			configure: func(c *Client) { c.HTMLEntities = HTMLEntitiesStrict },
+			translate: func(TranslateRequest) []Translation { return []Translation{{Text: "Kunde &#52;&#55;&#49;&#49;"}} },
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, _, teardown := initTranslateServer(t, tc.translate)
+			defer teardown()
+			tc.configure(cli)
+
+			req := TranslateRequest{Text: []string{"customer " + secret}, TargetLang: "DE"}
+			_, err := cli.Translate(context.Background(), req)
+			if err == nil || !(strings.Contains(err.Error(), secret) || strings.Contains(err.Error(), "&#")) {
+				t.Fatalf("expected an error quoting the text, got %v", err)
+			}
+
+			if err := WithPrivateErrors()(cli); err != nil {
+				t.Fatal(err)
+			}
+			_, err = cli.Translate(context.Background(), req)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if strings.Contains(err.Error(), secret) || strings.Contains(err.Error(), "customer") || strings.Contains(err.Error(), "&#") {
+				t.Fatalf("error leaks request content: %s", err.Error())
+			}
+		})
+	}
+}
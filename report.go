@@ -0,0 +1,156 @@
+package deepl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Report accumulates the cost of bulk jobs, such as TranslateSRT,
+// TranslateProperties, TranslateLocale, TranslateBatch, TranslateBilingual
+// and TranslateLines, run with a context from
+// ContextWithReport. Characters are the billed characters of the texts
+// translated by DeepL; cached texts and failed requests add none. A job
+// resumed after a *QuotaExceededError adds to the same Report, which may
+// also be decoded from the JSON of WriteJSON. It is safe for concurrent
+// use.
+type Report struct {
+	mu sync.Mutex
+
+	Characters int `json:"characters"`
+	// Languages, Files and CostTags break Characters down by target
+	// language, by the file of ContextWithReport and by cost tag. Texts
+	// with several tags count towards each of them.
+	Languages map[string]int `json:"languages"`
+	Files     map[string]int `json:"files,omitempty"`
+	CostTags  map[string]int `json:"cost_tags,omitempty"`
+	// Texts counts the texts translated, CacheHits those of them served
+	// by the TranslationCache and Untranslated those left when a job
+	// failed or stopped.
+	Texts        int `json:"texts"`
+	CacheHits    int `json:"cache_hits"`
+	Untranslated int `json:"untranslated"`
+	// Calls counts the API calls made, failed ones included. Retries
+	// counts their attempts beyond the first.
+	Calls   int `json:"calls"`
+	Retries int `json:"retries"`
+	// Runs counts the jobs, WallTime sums their duration.
+	Runs     int           `json:"runs"`
+	WallTime time.Duration `json:"wall_time_ns"`
+}
+
+type reportKey struct{}
+
+type reportTarget struct {
+	report *Report
+	file   string
+}
+
+// ContextWithReport returns a copy of ctx whose calls and bulk jobs are
+// recorded in r, the characters of jobs under file, which may be empty.
+func ContextWithReport(ctx context.Context, r *Report, file string) context.Context {
+	return context.WithValue(ctx, reportKey{}, reportTarget{report: r, file: file})
+}
+
+func reportFrom(ctx context.Context) (reportTarget, bool) {
+	target, ok := ctx.Value(reportKey{}).(reportTarget)
+	return target, ok && target.report != nil
+}
+
+// addCall records an API call sent in attempts attempts.
+func (r *Report) addCall(attempts int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Calls++
+	if attempts > 1 {
+		r.Retries += attempts - 1
+	}
+}
+
+// addResult records the result of translating texts of a job.
+func (r *Report) addResult(file string, tags []string, texts []string, result *TranslateResult) {
+	characters := 0
+	if !result.Metadata.Cached {
+		characters = billedCharacters(texts, result)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if result.Metadata.Failed {
+		// soft fail returned the source texts, addRun counts them
+		return
+	}
+	r.Texts += len(texts)
+	if result.Metadata.Cached {
+		r.CacheHits += len(texts)
+	}
+	r.Characters += characters
+	r.Languages = addCount(r.Languages, result.targetLang, characters)
+	if file != "" {
+		r.Files = addCount(r.Files, file, characters)
+	}
+	for _, tag := range tags {
+		r.CostTags = addCount(r.CostTags, tag, characters)
+	}
+}
+
+// addRun records a job that took d and left untranslated texts.
+func (r *Report) addRun(d time.Duration, untranslated int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Runs++
+	r.WallTime += d
+	r.Untranslated += untranslated
+}
+
+func addCount(m map[string]int, key string, n int) map[string]int {
+	if m == nil {
+		m = make(map[string]int)
+	}
+	m[key] += n
+	return m
+}
+
+// WriteJSON writes r as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// String formats r for humans, breakdowns sorted by key.
+func (r *Report) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var b strings.Builder
+	fmt.Fprintf(&b, "Characters: %d\n", r.Characters)
+	writeCounts(&b, "Languages", r.Languages)
+	writeCounts(&b, "Files", r.Files)
+	writeCounts(&b, "Cost tags", r.CostTags)
+	fmt.Fprintf(&b, "Texts: %d (%d cache hits, %d untranslated)\n", r.Texts, r.CacheHits, r.Untranslated)
+	fmt.Fprintf(&b, "API calls: %d (%d retries)\n", r.Calls, r.Retries)
+	fmt.Fprintf(&b, "Wall time: %v in %d runs\n", r.WallTime, r.Runs)
+	return b.String()
+}
+
+func writeCounts(b *strings.Builder, name string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Fprintf(b, "%s:\n", name)
+	for _, key := range keys {
+		fmt.Fprintf(b, "  %s: %d\n", key, counts[key])
+	}
+}
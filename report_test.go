@@ -0,0 +1,113 @@
+package deepl
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+func TestClient_Report(t *testing.T) {
+	report := &Report{}
+	ctx := WithCostTag(context.Background(), "team=subtitles")
+
+	cli, teardown := initQuotaServer(t, 1)
+	defer teardown()
+	s, err := ParseSRT(strings.NewReader("1\n00:00:01,000 --> 00:00:02,000\nHello\n\n2\n00:00:03,000 --> 00:00:04,000\nBye\n\n3\n00:00:05,000 --> 00:00:06,000\nAgain\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.TranslateSRT(ContextWithReport(ctx, report, "movie.srt"), s, TranslateRequest{TargetLang: "DE"}); !IsQuotaError(err) {
+		t.Fatalf("expected quota error, got %v", err)
+	}
+	if report.Characters != 5 || report.Texts != 1 || report.Untranslated != 2 || report.Calls != 2 || report.Runs != 1 {
+		t.Fatalf("unexpected report after partial failure %+v", report)
+	}
+
+	// the job is resumed from the JSON report once the quota resets
+	var b bytes.Buffer
+	if err := report.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	resumed := &Report{}
+	if err := json.Unmarshal(b.Bytes(), resumed); err != nil {
+		t.Fatal(err)
+	}
+	cli, teardown = initQuotaServer(t, 10)
+	defer teardown()
+	cli.TranslationCache = &mapCache{m: map[string][]Translation{}}
+	p, err := ParseProperties(strings.NewReader("greeting=Hello\nfarewell=Bye\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := cli.TranslateProperties(ContextWithReport(ctx, resumed, "app.properties"), p, TranslateRequest{TargetLang: "FR"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := &Report{
+		Characters:   13,
+		Languages:    map[string]int{"DE": 5, "FR": 8},
+		Files:        map[string]int{"movie.srt": 5, "app.properties": 8},
+		CostTags:     map[string]int{"team=subtitles": 13},
+		Texts:        5,
+		CacheHits:    2,
+		Untranslated: 2,
+		Calls:        4,
+		Runs:         3,
+		WallTime:     resumed.WallTime,
+	}
+	if !reflect.DeepEqual(resumed, expected) {
+		t.Fatalf("report wrong.\nwant=%+v\ngot =%+v", expected, resumed)
+	}
+	for _, line := range []string{"Characters: 13\n", "  app.properties: 8\n", "Texts: 5 (2 cache hits, 2 untranslated)\n", "API calls: 4 (0 retries)\n"} {
+		if !strings.Contains(resumed.String(), line) {
+			t.Fatalf("%q missing from\n%s", line, resumed.String())
+		}
+	}
+}
+
+func TestClient_ReportBulkHelpers(t *testing.T) {
+	cli, _, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	report := &Report{}
+	ctx := ContextWithReport(context.Background(), report, "")
+
+	if _, err := cli.TranslateLines(ctx, []string{"a", "", "b"}, "EN", "DE"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.TranslateBilingual(ctx, TranslateRequest{Text: []string{"One. Two."}, TargetLang: "DE"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.TranslateBatch(ctx, TranslateRequest{TargetLang: "DE"}, []BatchItem{{Text: "c"}}); err != nil {
+		t.Fatal(err)
+	}
+	if report.Runs != 3 || report.Texts != 5 || report.Untranslated != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
+
+func TestClient_ReportFailedJob(t *testing.T) {
+	cli, _, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	cli.MaxTextsPerRequest = 1
+	report := &Report{}
+	ctx := ContextWithReport(context.Background(), report, "")
+
+	texts := []string{"a", "b"}
+	err := cli.runJob(ctx, TranslateRequest{TargetLang: "DE"}, texts, texts, func(start int, result *TranslateResult) error {
+		return xerrors.New("failed to apply translation")
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	// the texts of the failed chunk count as untranslated
+	if report.Untranslated != 2 || report.Runs != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
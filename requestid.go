@@ -0,0 +1,78 @@
+package deepl
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// requestIDPrefix tells processes apart, requestIDCounter tells the calls
+// of a process apart.
+var (
+	requestIDPrefix  = strconv.FormatUint(uint64(rand.New(rand.NewSource(time.Now().UnixNano())).Uint32()), 36)
+	requestIDCounter uint64
+)
+
+func newRequestID() string {
+	return requestIDPrefix + "-" + strconv.FormatUint(atomic.AddUint64(&requestIDCounter, 1), 36)
+}
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying id as the request ID
+// of the calls made with it, instead of a generated one.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// withRequestID returns ctx carrying a request ID, generating one unless
+// ctx already has one.
+func withRequestID(ctx context.Context) (context.Context, string) {
+	if id, ok := RequestIDFromContext(ctx); ok {
+		return ctx, id
+	}
+	id := newRequestID()
+	return ContextWithRequestID(ctx, id), id
+}
+
+// RequestIDError adds the request ID of a failed call to its error.
+type RequestIDError struct {
+	RequestID string
+	Err       error
+}
+
+func (e *RequestIDError) Error() string {
+	return e.Err.Error() + " (request " + e.RequestID + ")"
+}
+
+func (e *RequestIDError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorRequestID returns the request ID of the call that failed with err,
+// or "" if err doesn't carry one.
+func ErrorRequestID(err error) string {
+	var idErr *RequestIDError
+	if xerrors.As(err, &idErr) {
+		return idErr.RequestID
+	}
+	return ""
+}
+
+// withRequestIDError adds id to err unless it already carries a request ID.
+func withRequestIDError(err error, id string) error {
+	if err == nil || ErrorRequestID(err) != "" {
+		return err
+	}
+	return &RequestIDError{RequestID: id, Err: err}
+}
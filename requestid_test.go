@@ -0,0 +1,99 @@
+package deepl
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+func TestNewRequestID(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100000; i++ {
+		id := newRequestID()
+		if seen[id] {
+			t.Fatalf("request ID %s generated twice", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestClient_RequestID(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch {
+		case r.URL.Path == "/v2/usage":
+			w.WriteHeader(http.StatusForbidden)
+		case requests == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(`{"translations":[{"text":"Hallo"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	observer := &recordingCallObserver{}
+	cli, err := New(server.URL, log.New(&logs, "", 0), WithAPIKey("test"), WithCallObserver(observer), WithAuditRecord())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.MaxRetries = 1
+	cli.RetryBackoff = time.Millisecond
+
+	result, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := result.Metadata.RequestID
+	if id == "" || result.Audit.RequestID != id {
+		t.Fatalf("unexpected request IDs %q and %q", id, result.Audit.RequestID)
+	}
+	if len(observer.calls) != 1 || observer.calls[0].RequestID != id || observer.results[0].Attempts != 2 {
+		t.Fatalf("unexpected calls %+v", observer.calls)
+	}
+	if !strings.HasPrefix(logs.String(), "["+id+"] Retrying POST /v2/translate") {
+		t.Fatalf("retry not logged with the request ID %s: %q", id, logs.String())
+	}
+
+	_, err = cli.GetAccountStatus(context.Background())
+	var apiErr *APIError
+	if !xerrors.As(err, &apiErr) || ErrorRequestID(err) == "" || ErrorRequestID(err) == id {
+		t.Fatalf("expected *APIError with a new request ID, got %v", err)
+	}
+	if !strings.HasSuffix(err.Error(), " (request "+ErrorRequestID(err)+")") {
+		t.Fatalf("request ID missing from %q", err.Error())
+	}
+
+	ctx := ContextWithRequestID(context.Background(), "ticket-42")
+	if _, err := cli.GetAccountStatus(ctx); ErrorRequestID(err) != "ticket-42" {
+		t.Fatalf("expected request ID ticket-42, got %v", err)
+	}
+}
+
+func TestClient_RequestIDChunks(t *testing.T) {
+	cli, _, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	cli.MaxTextsPerRequest = 1
+	observer := &recordingCallObserver{}
+	cli.CallObserver = observer
+
+	if _, err := cli.TranslateLines(context.Background(), []string{"a", "b", "c"}, "EN", "DE"); err != nil {
+		t.Fatal(err)
+	}
+	if len(observer.calls) != 3 {
+		t.Fatalf("expected 3 calls, got %d", len(observer.calls))
+	}
+	for _, call := range observer.calls {
+		if call.RequestID == "" || call.RequestID != observer.calls[0].RequestID {
+			t.Fatalf("chunks sent with different request IDs %+v", observer.calls)
+		}
+	}
+}
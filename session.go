@@ -0,0 +1,101 @@
+package deepl
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const defaultMinDetectLength = 20
+
+// SessionTranslator translates the messages of one conversation. Language
+// detection often flips on short messages like "ok" or "si", so requests
+// without a SourceLang whose texts are shorter than MinDetectLength runes
+// in total are sent with the language detected for earlier messages.
+// Longer messages are detected again and update the hint.
+type SessionTranslator struct {
+	// MinDetectLength is the number of runes from which messages are
+	// detected again, 20 by NewSessionTranslator.
+	MinDetectLength int
+
+	next Translator
+	mu   sync.Mutex
+	hint string
+}
+
+var _ Translator = (*SessionTranslator)(nil)
+
+// NewSessionTranslator returns a SessionTranslator sending requests to
+// next, usually a *Client.
+func NewSessionTranslator(next Translator) *SessionTranslator {
+	return &SessionTranslator{MinDetectLength: defaultMinDetectLength, next: next}
+}
+
+// Translate translates req with the session's source language hint. Until
+// a long message has been detected, short messages set the hint too.
+func (s *SessionTranslator) Translate(ctx context.Context, req TranslateRequest, opts ...TranslateOption) (*TranslateResult, error) {
+	if req.SourceLang != "" {
+		return s.next.Translate(ctx, req, opts...)
+	}
+	short := s.short(req.Text)
+	hint := s.SourceHint()
+	if short && hint != "" {
+		req.SourceLang = hint
+		return s.next.Translate(ctx, req, opts...)
+	}
+
+	res, err := s.next.Translate(ctx, req, opts...)
+	if err != nil {
+		return res, err
+	}
+	if detected := detectedLanguage(req.Text, res); detected != "" {
+		s.mu.Lock()
+		if !short || s.hint == "" {
+			s.hint = detected
+		}
+		s.mu.Unlock()
+	}
+	return res, nil
+}
+
+// SourceHint returns the source language sent with short messages, or ""
+// if none has been detected yet.
+func (s *SessionTranslator) SourceHint() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hint
+}
+
+// Reset forgets the source language hint, for example when a new
+// conversation starts.
+func (s *SessionTranslator) Reset() {
+	s.mu.Lock()
+	s.hint = ""
+	s.mu.Unlock()
+}
+
+func (s *SessionTranslator) short(texts []string) bool {
+	n := 0
+	for _, text := range texts {
+		n += utf8.RuneCountInString(strings.TrimSpace(text))
+	}
+	return n < s.MinDetectLength
+}
+
+// detectedLanguage returns the language detected for the longest text.
+func detectedLanguage(texts []string, res *TranslateResult) string {
+	if res == nil {
+		return ""
+	}
+	lang, longest := "", -1
+	for i, t := range res.Translations {
+		if i >= len(texts) || t.DetectedSourceLanguage == "" {
+			continue
+		}
+		if n := len(texts[i]); n > longest {
+			lang, longest = t.DetectedSourceLanguage, n
+		}
+	}
+	return strings.ToUpper(lang)
+}
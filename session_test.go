@@ -0,0 +1,58 @@
+package deepl
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestSessionTranslator(t *testing.T) {
+	cli, received, teardown := initTranslateServer(t, func(req TranslateRequest) []Translation {
+		translations := prefixTranslations(req)
+		for i, text := range req.Text {
+			switch {
+			case req.SourceLang != "":
+				translations[i].DetectedSourceLanguage = req.SourceLang
+			case strings.HasPrefix(text, "Hola"):
+				translations[i].DetectedSourceLanguage = "ES"
+			case strings.HasPrefix(text, "Bonjour"):
+				translations[i].DetectedSourceLanguage = "FR"
+			}
+		}
+		return translations
+	})
+	defer teardown()
+	s := NewSessionTranslator(cli)
+
+	tt := []struct {
+		text       string
+		sourceLang string
+		reset      bool
+
+		expectedSourceLang string
+		expectedHint       string
+	}{
+		{text: "ok", expectedHint: "EN"},
+		{text: "Hola, ¿cómo estás hoy?", expectedHint: "ES"},
+		{text: "ok", expectedSourceLang: "ES", expectedHint: "ES"},
+		{text: "Bonjour, comment allez-vous ?", expectedHint: "FR"},
+		{text: "si", expectedSourceLang: "FR", expectedHint: "FR"},
+		{text: "ja", sourceLang: "DE", expectedSourceLang: "DE", expectedHint: "FR"},
+		{text: "si", reset: true, expectedHint: "EN"},
+	}
+	for i, tc := range tt {
+		if tc.reset {
+			s.Reset()
+		}
+		if _, err := s.Translate(context.Background(), TranslateRequest{Text: []string{tc.text}, SourceLang: tc.sourceLang, TargetLang: "JA"}); err != nil {
+			t.Fatal(err)
+		}
+		if got := (*received)[i].SourceLang; got != tc.expectedSourceLang {
+			t.Fatalf("message %d %q sent with source %q, expected %q", i, tc.text, got, tc.expectedSourceLang)
+		}
+		if got := s.SourceHint(); got != tc.expectedHint {
+			t.Fatalf("hint after message %d %q is %q, expected %q", i, tc.text, got, tc.expectedHint)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package deepl
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+const defaultShortInputLength = 3
+
+// ShortInputAction selects what happens to a short text with letters or
+// digits.
+type ShortInputAction int
+
+const (
+	// ShortInputTranslate sends the text as usual.
+	ShortInputTranslate ShortInputAction = iota
+	// ShortInputPassThrough returns the text unchanged without sending it.
+	ShortInputPassThrough
+	// ShortInputTable looks the text up in ShortInputPolicy.Table and
+	// sends it if it isn't there.
+	ShortInputTable
+)
+
+// ShortInputPolicy configures WithShortInputPolicy.
+type ShortInputPolicy struct {
+	// MinLength is the length from which texts are always sent, 3 by
+	// default. It counts characters as displayed, so an emoji made of
+	// several code points counts once.
+	MinLength int
+	// Action applies to short texts with letters or digits, in any script:
+	// a single CJK character is a word. ShortInputTranslate by default.
+	Action ShortInputAction
+	// TranslateSymbols sends short texts of only whitespace, punctuation,
+	// symbols and emoji, which are passed through by default.
+	TranslateSymbols bool
+	// Table maps target languages, or "*" for any, to lower-cased texts and
+	// their translations. Nil means a small built-in table of greetings and
+	// answers.
+	Table map[string]map[string]string
+}
+
+// ShortInput records the decision of WithShortInputPolicy for a text.
+type ShortInput struct {
+	Index  int
+	Action ShortInputAction
+}
+
+// shortInputTable is the built-in ShortInputPolicy.Table.
+var shortInputTable = map[string]map[string]string{
+	"*":  {"ok": "OK"},
+	"DE": {"no": "Nein", "hi": "Hallo"},
+	"ES": {"no": "No", "hi": "Hola"},
+	"FR": {"no": "Non", "hi": "Salut"},
+	"IT": {"no": "No", "hi": "Ciao"},
+	"JA": {"no": "いいえ", "hi": "こんにちは"},
+	"NL": {"no": "Nee", "hi": "Hoi"},
+	"PL": {"no": "Nie", "hi": "Cześć"},
+	"PT": {"no": "Não", "hi": "Olá"},
+	"ZH": {"no": "不", "hi": "你好"},
+}
+
+// WithShortInputPolicy decides before sending what happens to texts
+// shorter than p.MinLength, which often come back surprising and are pure
+// waste at volume. Decisions are recorded in Metadata.ShortInputs.
+func WithShortInputPolicy(p ShortInputPolicy) Option {
+	return func(c *Client) error {
+		if p.MinLength < 0 || p.Action < ShortInputTranslate || p.Action > ShortInputTable {
+			return xerrors.Errorf("Failed to configure short input policy: invalid length %d or action %d", p.MinLength, p.Action)
+		}
+		if p.MinLength == 0 {
+			p.MinLength = defaultShortInputLength
+		}
+		if p.Table == nil {
+			p.Table = shortInputTable
+		}
+		c.shortInputs = &p
+		return nil
+	}
+}
+
+// decide returns the action for text, ok is false when text isn't short.
+func (p *ShortInputPolicy) decide(text string) (ShortInputAction, bool) {
+	length, words := 0, false
+	for s := text; s != ""; length++ {
+		if length == p.MinLength {
+			return 0, false
+		}
+		n := graphemeLen(s)
+		words = words || isWordCluster(s[:n])
+		s = s[n:]
+	}
+	switch {
+	case length >= p.MinLength:
+		return 0, false
+	case words:
+		return p.Action, true
+	case p.TranslateSymbols:
+		return ShortInputTranslate, true
+	}
+	return ShortInputPassThrough, true
+}
+
+// isWordCluster reports whether a grapheme cluster is a letter or digit,
+// as opposed to whitespace, punctuation, symbols and emoji such as the
+// keycap "1️⃣".
+func isWordCluster(cluster string) bool {
+	r, _ := utf8.DecodeRuneInString(cluster)
+	return unicode.In(r, unicode.Letter, unicode.Number) && !strings.ContainsRune(cluster, 0x20E3)
+}
+
+// lookup returns the translation of text into dst from the table.
+func (p *ShortInputPolicy) lookup(text, dst string) (string, bool) {
+	key := strings.ToLower(strings.TrimSpace(text))
+	if translation, ok := p.Table[baseLanguage(strings.ToUpper(dst))][key]; ok {
+		return translation, true
+	}
+	translation, ok := p.Table["*"][key]
+	return translation, ok
+}
+
+// translateShortInputs answers the short texts of r according to
+// c.shortInputs and sends the rest.
+func (c *Client) translateShortInputs(ctx context.Context, r *TranslateRequest, legacy bool, raw *json.RawMessage) (*TranslateResult, error) {
+	translations := make([]Translation, len(r.Text))
+	var decisions []ShortInput
+	var sendTexts []string
+	var sendIndexes []int
+	for i, text := range r.Text {
+		action, short := c.shortInputs.decide(text)
+		if short && action == ShortInputTable {
+			if translation, ok := c.shortInputs.lookup(text, r.TargetLang); ok {
+				translations[i] = Translation{DetectedSourceLanguage: strings.ToUpper(r.SourceLang), Text: translation, Source: SourceTable}
+			} else {
+				action = ShortInputTranslate
+			}
+		}
+		if short && action == ShortInputPassThrough {
+			translations[i] = Translation{Text: text, Source: SourceUntranslated}
+		}
+		if short {
+			decisions = append(decisions, ShortInput{Index: i, Action: action})
+		}
+		if !short || action == ShortInputTranslate {
+			sendTexts = append(sendTexts, text)
+			sendIndexes = append(sendIndexes, i)
+		}
+	}
+	if len(sendTexts) == len(r.Text) {
+		result, err := c.translateSent(ctx, r, legacy, raw)
+		if err == nil {
+			result.Metadata.ShortInputs = decisions
+		}
+		return result, err
+	}
+
+	result := &TranslateResult{Translations: translations}
+	if len(sendTexts) > 0 {
+		sendReq := *r
+		sendReq.Text = sendTexts
+		sent, err := c.translateSent(ctx, &sendReq, legacy, raw)
+		if err != nil {
+			return nil, err
+		}
+		// WithFormalityRetry may have changed the formality
+		r.Formality = sendReq.Formality
+		if len(sent.Translations) != len(sendTexts) {
+			return nil, &TranslationCountError{Expected: len(sendTexts), Got: len(sent.Translations)}
+		}
+		for j, t := range sent.Translations {
+			result.Translations[sendIndexes[j]] = t
+		}
+		result.Metadata = sent.Metadata
+	}
+	result.Metadata.ShortInputs = decisions
+	return result, nil
+}
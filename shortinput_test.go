@@ -0,0 +1,130 @@
+package deepl
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestShortInputPolicy_Decide(t *testing.T) {
+	tt := []struct {
+		text string
+
+		expectedAction ShortInputAction
+		expectedShort  bool
+	}{
+		{text: "", expectedAction: ShortInputPassThrough, expectedShort: true},
+		{text: "  ", expectedAction: ShortInputPassThrough, expectedShort: true},
+		{text: "?!", expectedAction: ShortInputPassThrough, expectedShort: true},
+		{text: "👍", expectedAction: ShortInputPassThrough, expectedShort: true},
+		{text: "👍🏽", expectedAction: ShortInputPassThrough, expectedShort: true},
+		{text: "👨‍👩‍👧‍👦", expectedAction: ShortInputPassThrough, expectedShort: true},
+		{text: "🇩🇪🇫🇷", expectedAction: ShortInputPassThrough, expectedShort: true},
+		{text: "1️⃣", expectedAction: ShortInputPassThrough, expectedShort: true},
+		{text: "猫", expectedAction: ShortInputTable, expectedShort: true},
+		{text: "はい", expectedAction: ShortInputTable, expectedShort: true},
+		{text: "ok", expectedAction: ShortInputTable, expectedShort: true},
+		{text: "é", expectedAction: ShortInputTable, expectedShort: true},
+		{text: "42", expectedAction: ShortInputTable, expectedShort: true},
+		{text: "ok👍", expectedAction: 0, expectedShort: false},
+		{text: "yes", expectedShort: false},
+		{text: "👍👍👍", expectedShort: false},
+		{text: "日本語", expectedShort: false},
+	}
+
+	p := &ShortInputPolicy{MinLength: 3, Action: ShortInputTable}
+	for _, tc := range tt {
+		action, short := p.decide(tc.text)
+		if action != tc.expectedAction || short != tc.expectedShort {
+			t.Errorf("%q decided as %d, %t, expected %d, %t", tc.text, action, short, tc.expectedAction, tc.expectedShort)
+		}
+	}
+}
+
+func TestClient_ShortInputPolicy(t *testing.T) {
+	texts := []string{"Hello world", "ok", "👍", "猫", "No", "?", "xy"}
+	tt := []struct {
+		name string
+
+		policy ShortInputPolicy
+
+		expectedSent      []string
+		expectedTexts     []string
+		expectedDecisions []ShortInput
+	}{
+		{
+			name: "defaults translate words",
+
+			expectedSent:  []string{"Hello world", "ok", "猫", "No", "xy"},
+			expectedTexts: []string{"DE:Hello world", "DE:ok", "👍", "DE:猫", "DE:No", "?", "DE:xy"},
+			expectedDecisions: []ShortInput{
+				{Index: 1, Action: ShortInputTranslate}, {Index: 2, Action: ShortInputPassThrough},
+				{Index: 3, Action: ShortInputTranslate}, {Index: 4, Action: ShortInputTranslate},
+				{Index: 5, Action: ShortInputPassThrough}, {Index: 6, Action: ShortInputTranslate},
+			},
+		},
+		{
+			name: "table",
+
+			policy: ShortInputPolicy{Action: ShortInputTable},
+
+			expectedSent:  []string{"Hello world", "猫", "xy"},
+			expectedTexts: []string{"DE:Hello world", "OK", "👍", "DE:猫", "Nein", "?", "DE:xy"},
+			expectedDecisions: []ShortInput{
+				{Index: 1, Action: ShortInputTable}, {Index: 2, Action: ShortInputPassThrough},
+				{Index: 3, Action: ShortInputTranslate}, {Index: 4, Action: ShortInputTable},
+				{Index: 5, Action: ShortInputPassThrough}, {Index: 6, Action: ShortInputTranslate},
+			},
+		},
+		{
+			name: "pass through everything short",
+
+			policy: ShortInputPolicy{MinLength: 2, Action: ShortInputPassThrough, TranslateSymbols: true},
+
+			expectedSent:  []string{"Hello world", "ok", "👍", "No", "?", "xy"},
+			expectedTexts: []string{"DE:Hello world", "DE:ok", "DE:👍", "猫", "DE:No", "DE:?", "DE:xy"},
+			expectedDecisions: []ShortInput{
+				{Index: 2, Action: ShortInputTranslate}, {Index: 3, Action: ShortInputPassThrough},
+				{Index: 5, Action: ShortInputTranslate},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, received, teardown := initTranslateServer(t, prefixTranslations)
+			defer teardown()
+			if err := WithShortInputPolicy(tc.policy)(cli); err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := cli.Translate(context.Background(), TranslateRequest{Text: texts, TargetLang: "DE"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(*received) != 1 || !reflect.DeepEqual((*received)[0].Text, tc.expectedSent) {
+				t.Fatalf("sent %+v, expected %q", *received, tc.expectedSent)
+			}
+			if !reflect.DeepEqual(res.Texts(), tc.expectedTexts) {
+				t.Fatalf("translations wrong.\nwant=%q\ngot =%q", tc.expectedTexts, res.Texts())
+			}
+			if !reflect.DeepEqual(res.Metadata.ShortInputs, tc.expectedDecisions) {
+				t.Fatalf("decisions wrong.\nwant=%+v\ngot =%+v", tc.expectedDecisions, res.Metadata.ShortInputs)
+			}
+		})
+	}
+
+	cli, received, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	if err := WithShortInputPolicy(ShortInputPolicy{})(cli); err != nil {
+		t.Fatal(err)
+	}
+	res, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"🎉", "?!"}, TargetLang: "DE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*received) != 0 || res.Translations[0].Source != SourceUntranslated || res.Texts()[1] != "?!" {
+		t.Fatalf("symbols were sent or changed: %+v", res)
+	}
+}
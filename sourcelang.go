@@ -0,0 +1,190 @@
+package deepl
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/xerrors"
+)
+
+// sourceSampleRunes is the length of the start of a text that is examined
+// for its language.
+const sourceSampleRunes = 200
+
+// ErrSourceLanguageNotAllowed matches the *SourceLanguageError returned
+// for texts refused by WithAllowedSourceLanguages.
+var ErrSourceLanguageNotAllowed = xerrors.New("Source language not allowed")
+
+// SourceLanguageError is returned when a request was refused before being
+// sent because of its source language.
+type SourceLanguageError struct {
+	// Index is the text that was refused, or -1 when the explicit
+	// SourceLang of the request isn't allowed.
+	Index int
+	// Language is the explicit or detected language, empty when the text
+	// couldn't be classified.
+	Language string
+}
+
+func (e *SourceLanguageError) Error() string {
+	switch {
+	case e.Index < 0:
+		return fmt.Sprintf("%s: source_lang %s", ErrSourceLanguageNotAllowed.Error(), e.Language)
+	case e.Language == "":
+		return fmt.Sprintf("%s: language of text %d is unknown", ErrSourceLanguageNotAllowed.Error(), e.Index)
+	}
+	return fmt.Sprintf("%s: text %d looks like %s", ErrSourceLanguageNotAllowed.Error(), e.Index, e.Language)
+}
+
+func (e *SourceLanguageError) Is(target error) bool {
+	return target == ErrSourceLanguageNotAllowed
+}
+
+// SourceLanguageDetector guesses the language of text locally. ok is false
+// when it can't tell.
+type SourceLanguageDetector func(text string) (lang string, ok bool)
+
+// sourceLanguagePolicy is set by WithAllowedSourceLanguages and
+// WithSourceLanguageDetector.
+type sourceLanguagePolicy struct {
+	allowed    map[string]bool
+	detect     SourceLanguageDetector
+	failClosed bool
+}
+
+// WithAllowedSourceLanguages refuses to send texts in other languages than
+// langs, such as EN or DE, with a *SourceLanguageError. An explicit
+// SourceLang is trusted. Without one, the start of every text is examined
+// by the detector of WithSourceLanguageDetector, DetectScriptLanguage by
+// default. Detection is best-effort: only texts it classified as another
+// language are refused, unless WithSourceLanguageDetector sets the detector
+// to fail closed.
+func WithAllowedSourceLanguages(langs ...Language) Option {
+	return func(c *Client) error {
+		if len(langs) == 0 {
+			return xerrors.New("Failed to configure allowed source languages: no language given")
+		}
+		c.sourcePolicy.allowed = make(map[string]bool, len(langs))
+		for _, lang := range langs {
+			c.sourcePolicy.allowed[normalizeSourceLanguage(lang.Code)] = true
+		}
+		return nil
+	}
+}
+
+// WithSourceLanguageDetector sets the detector of
+// WithAllowedSourceLanguages. With failClosed set, texts it can't classify
+// are refused too.
+func WithSourceLanguageDetector(detect SourceLanguageDetector, failClosed bool) Option {
+	return func(c *Client) error {
+		c.sourcePolicy.detect = detect
+		c.sourcePolicy.failClosed = failClosed
+		return nil
+	}
+}
+
+// check returns a *SourceLanguageError for the first text of r that must
+// not be sent.
+func (p *sourceLanguagePolicy) check(r *TranslateRequest) error {
+	if p.allowed == nil {
+		return nil
+	}
+	if r.SourceLang != "" {
+		if lang := normalizeSourceLanguage(r.SourceLang); !p.allowed[lang] {
+			return &SourceLanguageError{Index: -1, Language: lang}
+		}
+		return nil
+	}
+	detect := p.detect
+	if detect == nil {
+		detect = DetectScriptLanguage
+	}
+	for i, text := range r.Text {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		lang, ok := detect(languageSample(text))
+		if !ok {
+			if !p.failClosed {
+				continue
+			}
+			return &SourceLanguageError{Index: i}
+		}
+		if lang = normalizeSourceLanguage(lang); !p.allowed[lang] {
+			return &SourceLanguageError{Index: i, Language: lang}
+		}
+	}
+	return nil
+}
+
+// languageSample returns the first sourceSampleRunes runes of text.
+func languageSample(text string) string {
+	n := 0
+	for i := range text {
+		if n == sourceSampleRunes {
+			return text[:i]
+		}
+		n++
+	}
+	return text
+}
+
+// normalizeSourceLanguage returns lang upper-cased without its region.
+func normalizeSourceLanguage(lang string) string {
+	return baseLanguage(strings.ToUpper(strings.TrimSpace(lang)))
+}
+
+// scriptLanguages are the scripts used by a single language DeepL
+// translates from. Texts in Han without kana are taken to be Chinese.
+var scriptLanguages = []struct {
+	script *unicode.RangeTable
+	lang   string
+}{
+	{unicode.Hiragana, "JA"},
+	{unicode.Katakana, "JA"},
+	{unicode.Hangul, "KO"},
+	{unicode.Greek, "EL"},
+	{unicode.Han, "ZH"},
+}
+
+// DetectScriptLanguage is the default SourceLanguageDetector. It only
+// recognizes languages by their script, namely Japanese, Korean, Greek and
+// Chinese, and can't tell languages written in Latin, Cyrillic or Arabic
+// script apart.
+func DetectScriptLanguage(text string) (string, bool) {
+	counts := make([]int, len(scriptLanguages))
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for i, s := range scriptLanguages {
+			if unicode.Is(s.script, r) {
+				counts[i]++
+				break
+			}
+		}
+	}
+	classified := 0
+	for _, n := range counts {
+		classified += n
+	}
+	// most letters must be in a known script, so that a quoted word
+	// doesn't decide
+	if letters == 0 || classified*2 <= letters {
+		return "", false
+	}
+	best := 0
+	for i, n := range counts {
+		if n > counts[best] {
+			best = i
+		}
+	}
+	// kana make Han Japanese
+	if scriptLanguages[best].lang == "ZH" && counts[0]+counts[1] > 0 {
+		return "JA", true
+	}
+	return scriptLanguages[best].lang, true
+}
@@ -0,0 +1,125 @@
+package deepl
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+func TestDetectScriptLanguage(t *testing.T) {
+	tt := []struct {
+		text string
+
+		expectedLang string
+		expectedOK   bool
+	}{
+		{text: "こんにちは世界", expectedLang: "JA", expectedOK: true},
+		{text: "日本語の文章です", expectedLang: "JA", expectedOK: true},
+		{text: "你好世界", expectedLang: "ZH", expectedOK: true},
+		{text: "안녕하세요", expectedLang: "KO", expectedOK: true},
+		{text: "Καλημέρα κόσμε", expectedLang: "EL", expectedOK: true},
+		{text: "Hello world"},
+		{text: "Привет мир"},
+		{text: "Say 你好 to everyone here"},
+		{text: "123 !?"},
+	}
+
+	for _, tc := range tt {
+		lang, ok := DetectScriptLanguage(tc.text)
+		if lang != tc.expectedLang || ok != tc.expectedOK {
+			t.Errorf("%q detected as %q, %t, expected %q, %t", tc.text, lang, ok, tc.expectedLang, tc.expectedOK)
+		}
+	}
+}
+
+func TestWithAllowedSourceLanguages(t *testing.T) {
+	tt := []struct {
+		name string
+
+		opts []Option
+		req  TranslateRequest
+
+		expectedErr *SourceLanguageError
+	}{
+		{
+			name: "explicit source allowed",
+
+			req: TranslateRequest{Text: []string{"こんにちは"}, SourceLang: "en"},
+		},
+		{
+			name: "explicit source refused",
+
+			req: TranslateRequest{Text: []string{"Hello"}, SourceLang: "ZH-HANS"},
+
+			expectedErr: &SourceLanguageError{Index: -1, Language: "ZH"},
+		},
+		{
+			name: "detected language allowed",
+
+			req: TranslateRequest{Text: []string{"", "日本語の文章です"}},
+		},
+		{
+			name: "detected language refused",
+
+			req: TranslateRequest{Text: []string{"日本語の文章です", "안녕하세요"}},
+
+			expectedErr: &SourceLanguageError{Index: 1, Language: "KO"},
+		},
+		{
+			name: "unknown language fails open",
+
+			req: TranslateRequest{Text: []string{"Bonjour", "Hello"}},
+		},
+		{
+			name: "unknown language fails closed",
+
+			opts: []Option{WithSourceLanguageDetector(DetectScriptLanguage, true)},
+			req:  TranslateRequest{Text: []string{"Hello"}},
+
+			expectedErr: &SourceLanguageError{Index: 0},
+		},
+		{
+			name: "custom detector",
+
+			opts: []Option{WithSourceLanguageDetector(func(text string) (string, bool) {
+				if len([]rune(text)) != sourceSampleRunes {
+					t.Errorf("detector got %d runes", len([]rune(text)))
+				}
+				return "de", true
+			}, false)},
+			req: TranslateRequest{Text: []string{strings.Repeat("ü", 2*sourceSampleRunes)}},
+
+			expectedErr: &SourceLanguageError{Index: 0, Language: "DE"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, received, teardown := initTranslateServer(t, prefixTranslations)
+			defer teardown()
+			for _, opt := range append([]Option{WithAllowedSourceLanguages(Language{Code: "EN"}, Language{Code: "ja"})}, tc.opts...) {
+				if err := opt(cli); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tc.req.TargetLang = "FR"
+			_, err := cli.Translate(context.Background(), tc.req)
+			if tc.expectedErr == nil {
+				if err != nil || len(*received) != 1 {
+					t.Fatalf("expected request to be sent, got %v", err)
+				}
+				return
+			}
+			var langErr *SourceLanguageError
+			if !xerrors.Is(err, ErrSourceLanguageNotAllowed) || !xerrors.As(err, &langErr) || *langErr != *tc.expectedErr {
+				t.Fatalf("expected %v, got %v", tc.expectedErr, err)
+			}
+			if IsRetryable(err) || len(*received) != 0 {
+				t.Fatalf("refused request was retryable or sent: %v", err)
+			}
+		})
+	}
+}
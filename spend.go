@@ -0,0 +1,143 @@
+package deepl
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+// ErrCharacterRateExceeded is matched by the *CharacterRateError returned
+// when a translation would exceed the limit of WithCharacterRate.
+var ErrCharacterRateExceeded = xerrors.New("Character rate exceeded")
+
+// CharacterRateError is returned instead of sending a translation that
+// would exceed the limit of WithCharacterRate. RetryAfter is the time
+// until enough characters are free, or zero when the request is larger than
+// the limit itself.
+type CharacterRateError struct {
+	Characters int
+	RetryAfter time.Duration
+}
+
+func (e *CharacterRateError) Error() string {
+	if e.RetryAfter == 0 {
+		return fmt.Sprintf("%s: %d characters exceed the limit", ErrCharacterRateExceeded.Error(), e.Characters)
+	}
+	return fmt.Sprintf("%s: %d characters fit in %v", ErrCharacterRateExceeded.Error(), e.Characters, e.RetryAfter)
+}
+
+func (e *CharacterRateError) Unwrap() error {
+	return ErrCharacterRateExceeded
+}
+
+// WithCharacterRate limits the characters translated in any sliding window
+// of the given length, for example 200000 per hour. Requests are counted
+// with the characters of their texts when sent and corrected to the
+// billed_characters of the response, if present. Failed requests don't
+// count.
+func WithCharacterRate(chars int, window time.Duration) Option {
+	return func(c *Client) error {
+		if chars < 1 || window <= 0 {
+			return xerrors.Errorf("Failed to configure character rate: invalid limit %d per %v", chars, window)
+		}
+		c.characterRate = &characterWindow{limit: chars, window: window}
+		return nil
+	}
+}
+
+// characterWindow is a sliding window of spent characters.
+type characterWindow struct {
+	limit  int
+	window time.Duration
+
+	mu     sync.Mutex
+	spends []*spend
+	total  int
+}
+
+type spend struct {
+	at    time.Time
+	chars int
+	// expired is set once the spend left the window and total
+	expired bool
+}
+
+// reserve counts chars against the window or reports how long until they
+// fit.
+func (w *characterWindow) reserve(chars int, now time.Time) (*spend, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.expire(now)
+	if chars > w.limit {
+		return nil, &CharacterRateError{Characters: chars}
+	}
+	if w.total+chars > w.limit {
+		// wait for the oldest spends to leave the window
+		free := w.limit - w.total
+		for _, s := range w.spends {
+			free += s.chars
+			if free >= chars {
+				return nil, &CharacterRateError{Characters: chars, RetryAfter: s.at.Add(w.window).Sub(now)}
+			}
+		}
+	}
+	s := &spend{at: now, chars: chars}
+	w.spends = append(w.spends, s)
+	w.total += chars
+	return s, nil
+}
+
+// settle corrects the characters of s, for example to the billed count or
+// to zero for a failed request. A spend that already expired no longer
+// counts against the window.
+func (w *characterWindow) settle(s *spend, chars int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !s.expired {
+		w.total += chars - s.chars
+	}
+	s.chars = chars
+}
+
+// expire drops the spends that left the window. The caller holds w.mu.
+func (w *characterWindow) expire(now time.Time) {
+	n := 0
+	for n < len(w.spends) && !now.Before(w.spends[n].at.Add(w.window)) {
+		w.total -= w.spends[n].chars
+		w.spends[n].expired = true
+		n++
+	}
+	w.spends = w.spends[n:]
+}
+
+// reserveCharacters applies WithCharacterRate to texts. The returned func
+// settles the reservation with the result of the request.
+func (c *Client) reserveCharacters(texts []string) (func(*TranslateResult), error) {
+	if c.characterRate == nil {
+		return func(*TranslateResult) {}, nil
+	}
+	chars := 0
+	for _, text := range texts {
+		chars += utf8.RuneCountInString(text)
+	}
+	s, err := c.characterRate.reserve(chars, c.clock().Now())
+	if err != nil {
+		return nil, err
+	}
+	return func(result *TranslateResult) {
+		if result == nil {
+			c.characterRate.settle(s, 0)
+			return
+		}
+		billed := 0
+		for _, t := range result.Translations {
+			billed += t.BilledCharacters
+		}
+		if billed > 0 {
+			c.characterRate.settle(s, billed)
+		}
+	}, nil
+}
@@ -0,0 +1,170 @@
+package deepl
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+func TestCharacterWindow(t *testing.T) {
+	w := &characterWindow{limit: 10, window: time.Minute}
+	start := time.Now()
+
+	first, err := w.reserve(6, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.reserve(4, start.Add(10*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	_, err = w.reserve(3, start.Add(20*time.Second))
+	var rateErr *CharacterRateError
+	if !xerrors.As(err, &rateErr) || rateErr.RetryAfter != 40*time.Second || !xerrors.Is(err, ErrCharacterRateExceeded) {
+		t.Fatalf("expected to wait 40s, got %v", err)
+	}
+
+	// billed characters replace the estimate
+	w.settle(first, 3)
+	if _, err := w.reserve(3, start.Add(20*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.reserve(1, start.Add(time.Minute)); err != nil {
+		t.Fatalf("expected the first spend to expire, got %v", err)
+	}
+
+	_, err = w.reserve(11, start.Add(time.Hour))
+	if !xerrors.As(err, &rateErr) || rateErr.RetryAfter != 0 {
+		t.Fatalf("expected an error without retry, got %v", err)
+	}
+}
+
+func TestClient_CharacterRate(t *testing.T) {
+	var characters int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req TranslateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request body: %s", err.Error())
+		}
+		for _, text := range req.Text {
+			atomic.AddInt32(&characters, int32(len(text)))
+		}
+		json.NewEncoder(w).Encode(TranslateResult{Translations: prefixTranslations(req)})
+	}))
+	defer server.Close()
+	cli, err := New(server.URL, nil, WithAPIKey("test"), WithCharacterRate(100, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	var limited int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{strings.Repeat("a", 10)}, TargetLang: "DE"})
+			switch {
+			case xerrors.Is(err, ErrCharacterRateExceeded):
+				atomic.AddInt32(&limited, 1)
+			case err != nil:
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if limited != 10 || characters != 100 {
+		t.Fatalf("got %d limited calls and %d characters", limited, characters)
+	}
+}
+
+func TestClient_CharacterRateBilled(t *testing.T) {
+	cli, _, teardown := initTranslateServer(t, func(req TranslateRequest) []Translation {
+		return []Translation{{Text: "DE:" + req.Text[0], BilledCharacters: 1}}
+	})
+	defer teardown()
+	if err := WithCharacterRate(10, time.Hour)(cli); err != nil {
+		t.Fatal(err)
+	}
+
+	req := TranslateRequest{Text: []string{"0123456789"}, TargetLang: "DE"}
+	// failed requests are released
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cli.Translate(ctx, req); !xerrors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+	// billed characters replace the estimate
+	if _, err := cli.Translate(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	req.Text = []string{"012345678"}
+	if _, err := cli.Translate(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.Translate(context.Background(), req); !xerrors.Is(err, ErrCharacterRateExceeded) {
+		t.Fatalf("expected the limit to be reached, got %v", err)
+	}
+}
+
+func TestClient_CharacterRateSettleExpired(t *testing.T) {
+	clock := newFakeClock()
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req TranslateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request body: %s", err.Error())
+		}
+		billed := len(req.Text[0])
+		if req.Text[0] == "slow" {
+			<-release
+			billed = 1
+		}
+		json.NewEncoder(w).Encode(TranslateResult{Translations: []Translation{{Text: req.Text[0], BilledCharacters: billed}}})
+	}))
+	defer server.Close()
+	cli, err := New(server.URL, nil, WithAPIKey("test"), WithClock(clock), WithCharacterRate(10, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	translate := func(text string) error {
+		_, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{text}, TargetLang: "DE"})
+		return err
+	}
+	slow := make(chan error, 1)
+	go func() { slow <- translate("slow") }()
+	waitFor(t, func() bool {
+		cli.characterRate.mu.Lock()
+		defer cli.characterRate.mu.Unlock()
+		return cli.characterRate.total == 4
+	})
+
+	// the slow request leaves the window before it is settled
+	clock.Advance(time.Hour)
+	if err := translate("01234"); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	if err := <-slow; err != nil {
+		t.Fatal(err)
+	}
+	if err := translate("012345"); !xerrors.Is(err, ErrCharacterRateExceeded) {
+		t.Fatalf("expected the limit to be reached, got %v", err)
+	}
+}
+
+func TestWithCharacterRateInvalid(t *testing.T) {
+	for _, opt := range []Option{WithCharacterRate(0, time.Hour), WithCharacterRate(10, 0)} {
+		if _, err := New("https://api.deepl.com", nil, opt); err == nil {
+			t.Error("expected error")
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package deepl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// SubtitleCue is one block of an SRT file. Index and Timing hold the
+// original lines verbatim.
+type SubtitleCue struct {
+	Index  string
+	Timing string
+	Lines  []string
+}
+
+// SRT is a parsed SubRip subtitle file. Writing it back reproduces the line
+// ending style and byte order mark of the input.
+type SRT struct {
+	Cues []SubtitleCue
+	CRLF bool
+	BOM  bool
+}
+
+// ParseSRT reads an SRT file. Every cue must have an index line and a timing
+// line containing "-->"; caption lines are optional.
+func ParseSRT(r io.Reader) (*SRT, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to read SRT: %w", err)
+	}
+
+	s := &SRT{}
+	if bytes.HasPrefix(data, utf8BOM) {
+		s.BOM = true
+		data = data[len(utf8BOM):]
+	}
+	s.CRLF = bytes.Contains(data, []byte("\r\n"))
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	var block []string
+	lineNo, blockStart := 0, 1
+	flush := func() error {
+		if len(block) == 0 {
+			return nil
+		}
+		if len(block) < 2 || !strings.Contains(block[1], "-->") {
+			return xerrors.Errorf("Failed to parse SRT: cue at line %d has no timing line", blockStart)
+		}
+		s.Cues = append(s.Cues, SubtitleCue{Index: block[0], Timing: block[1], Lines: block[2:]})
+		block = nil
+		return nil
+	}
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if len(block) == 0 {
+			blockStart = lineNo
+		}
+		block = append(block, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("Failed to read SRT: %w", err)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// WriteTo writes s in SRT format, each cue followed by a blank line.
+func (s *SRT) WriteTo(w io.Writer) (int64, error) {
+	newline := "\n"
+	if s.CRLF {
+		newline = "\r\n"
+	}
+
+	var buf bytes.Buffer
+	if s.BOM {
+		buf.Write(utf8BOM)
+	}
+	for _, cue := range s.Cues {
+		buf.WriteString(cue.Index + newline)
+		buf.WriteString(cue.Timing + newline)
+		for _, line := range cue.Lines {
+			buf.WriteString(line + newline)
+		}
+		buf.WriteString(newline)
+	}
+	return buf.WriteTo(w)
+}
+
+// TranslateSRT translates the caption lines of s and returns a new SRT with
+// the same indexes and timings. Options other than Text are taken from req.
+// Each cue is sent as one text so that DeepL sees the whole caption; when the
+// translation has a different number of lines than the original it is
+// re-wrapped to the original line count. Captions containing tags such as
+// <i> are sent with XML tag handling unless req sets a tag handling. When
+// the quota runs out, cues that weren't translated keep their lines and a
+// *QuotaExceededError reports them by index.
+func (c *Client) TranslateSRT(ctx context.Context, s *SRT, req TranslateRequest) (*SRT, error) {
+	out := &SRT{Cues: make([]SubtitleCue, len(s.Cues)), CRLF: s.CRLF, BOM: s.BOM}
+
+	var texts, ids []string
+	var cueIndexes []int
+	hasTags := false
+	for i, cue := range s.Cues {
+		out.Cues[i] = SubtitleCue{Index: cue.Index, Timing: cue.Timing, Lines: append([]string(nil), cue.Lines...)}
+		if len(cue.Lines) == 0 {
+			continue
+		}
+		text := strings.Join(cue.Lines, "\n")
+		hasTags = hasTags || strings.Contains(text, "<")
+		texts = append(texts, text)
+		ids = append(ids, cue.Index)
+		cueIndexes = append(cueIndexes, i)
+	}
+
+	// captions are short fragments; splitting on newlines would cut a
+	// sentence that continues on the next line
+	req.SplitSentences = SplitSentencesNoNewlines
+	if hasTags && req.TagHandling == "" {
+		req.TagHandling = TagHandlingXML
+	}
+
+	err := c.runJob(ctx, req, texts, ids, func(start int, result *TranslateResult) error {
+		for j, t := range result.Translations {
+			cueIndex := cueIndexes[start+j]
+			out.Cues[cueIndex].Lines = wrapLines(t.Text, len(s.Cues[cueIndex].Lines))
+		}
+		return nil
+	})
+	var quotaErr *QuotaExceededError
+	if xerrors.As(err, &quotaErr) {
+		return out, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// wrapLines splits text into n lines. Existing line breaks are kept when
+// they already produce n lines; otherwise words are distributed so that
+// lines have similar lengths. Text without spaces is split by runes.
+func wrapLines(text string, n int) []string {
+	lines := strings.Split(text, "\n")
+	if len(lines) == n || n <= 0 {
+		return lines
+	}
+	flat := strings.Join(strings.Fields(text), " ")
+	if n == 1 {
+		return []string{flat}
+	}
+
+	words := strings.Fields(flat)
+	if len(words) < n {
+		// no usable word boundaries, e.g. Japanese
+		words = nil
+		for _, r := range strings.ReplaceAll(flat, " ", "") {
+			words = append(words, string(r))
+		}
+	}
+	sep := " "
+	if !strings.Contains(flat, " ") {
+		sep = ""
+	}
+
+	total := 0
+	for _, w := range words {
+		total += utf8.RuneCountInString(w)
+	}
+	target := total / n
+
+	out := make([]string, 0, n)
+	var current []string
+	currentLen := 0
+	for i, w := range words {
+		wordLen := utf8.RuneCountInString(w)
+		remainingLines := n - len(out) - 1
+		if len(current) > 0 && remainingLines > 0 {
+			// break where the line ends closest to the target length, and
+			// keep at least one word for every remaining line
+			over := currentLen + wordLen - target
+			under := target - currentLen
+			if over > under || len(words)-i == remainingLines {
+				out = append(out, strings.Join(current, sep))
+				current, currentLen = nil, 0
+			}
+		}
+		current = append(current, w)
+		currentLen += wordLen
+	}
+	if len(current) > 0 {
+		out = append(out, strings.Join(current, sep))
+	}
+	return out
+}
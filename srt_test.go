@@ -0,0 +1,137 @@
+package deepl
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestParseSRT_RoundTrip(t *testing.T) {
+	tt := []struct {
+		name string
+
+		inputFile string
+
+		expectedCues int
+		expectedCRLF bool
+		expectedBOM  bool
+	}{
+		{name: "multi-line captions and tags", inputFile: "testdata/SRT/multiline.srt", expectedCues: 3},
+		{name: "crlf with bom", inputFile: "testdata/SRT/crlf.srt", expectedCues: 2, expectedCRLF: true, expectedBOM: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			input, err := ioutil.ReadFile(tc.inputFile)
+			if err != nil {
+				t.Fatalf("failed to read '%s': %s", tc.inputFile, err.Error())
+			}
+
+			s, err := ParseSRT(bytes.NewReader(input))
+			if err != nil {
+				t.Fatalf("parse error should be nil. got=%s", err.Error())
+			}
+			if len(s.Cues) != tc.expectedCues || s.CRLF != tc.expectedCRLF || s.BOM != tc.expectedBOM {
+				t.Fatalf("parsed SRT wrong. got cues=%d crlf=%t bom=%t", len(s.Cues), s.CRLF, s.BOM)
+			}
+
+			var out bytes.Buffer
+			if _, err := s.WriteTo(&out); err != nil {
+				t.Fatalf("write error should be nil. got=%s", err.Error())
+			}
+			if out.String() != string(input) {
+				t.Fatalf("round trip wrong. want=%q, got=%q", input, out.String())
+			}
+		})
+	}
+}
+
+func TestParseSRT_Invalid(t *testing.T) {
+	_, err := ParseSRT(strings.NewReader("1\n00:00:01,000 --> 00:00:02,000\nok\n\n2\nno timing\n"))
+	if err == nil {
+		t.Fatalf("parse error should not be non-nil. got=nil")
+	}
+	if !strings.Contains(err.Error(), "line 5") {
+		t.Fatalf("parse error should name the line. got=%s", err.Error())
+	}
+}
+
+func TestClient_TranslateSRT(t *testing.T) {
+	tt := []struct {
+		name string
+
+		inputFile string
+
+		expectedFile        string
+		expectedTagHandling TagHandling
+	}{
+		{name: "multi-line captions and tags", inputFile: "testdata/SRT/multiline.srt", expectedFile: "testdata/SRT/multiline-translated.srt", expectedTagHandling: TagHandlingXML},
+		{name: "crlf with bom", inputFile: "testdata/SRT/crlf.srt", expectedFile: "testdata/SRT/crlf-translated.srt", expectedTagHandling: TagHandlingXML},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, received, teardown := initTranslateServer(t, prefixTranslations)
+			defer teardown()
+
+			input, err := ioutil.ReadFile(tc.inputFile)
+			if err != nil {
+				t.Fatalf("failed to read '%s': %s", tc.inputFile, err.Error())
+			}
+			expected, err := ioutil.ReadFile(tc.expectedFile)
+			if err != nil {
+				t.Fatalf("failed to read '%s': %s", tc.expectedFile, err.Error())
+			}
+
+			s, err := ParseSRT(bytes.NewReader(input))
+			if err != nil {
+				t.Fatalf("parse error should be nil. got=%s", err.Error())
+			}
+			translated, err := cli.TranslateSRT(context.Background(), s, TranslateRequest{TargetLang: "JA"})
+			if err != nil {
+				t.Fatalf("response error should be nil. got=%s", err.Error())
+			}
+
+			var out bytes.Buffer
+			translated.WriteTo(&out)
+			if out.String() != string(expected) {
+				t.Fatalf("translated SRT wrong. want=%q, got=%q", expected, out.String())
+			}
+			if len(*received) != 1 {
+				t.Fatalf("request count wrong. want=1, got=%d", len(*received))
+			}
+			if r := (*received)[0]; r.SplitSentences != SplitSentencesNoNewlines || r.TagHandling != tc.expectedTagHandling {
+				t.Fatalf("request options wrong. got split_sentences=%s tag_handling=%s", r.SplitSentences, r.TagHandling)
+			}
+		})
+	}
+}
+
+func TestWrapLines(t *testing.T) {
+	tt := []struct {
+		name string
+
+		inputText  string
+		inputLines int
+
+		expectedLines []string
+	}{
+		{name: "line count kept", inputText: "a b\nc d", inputLines: 2, expectedLines: []string{"a b", "c d"}},
+		{name: "merged lines split again", inputText: "one two three four", inputLines: 2, expectedLines: []string{"one two", "three four"}},
+		{name: "extra lines joined", inputText: "one\ntwo\nthree", inputLines: 1, expectedLines: []string{"one two three"}},
+		{name: "no spaces", inputText: "こんにちは世界", inputLines: 2, expectedLines: []string{"こんに", "ちは世界"}},
+		{name: "fewer words than lines", inputText: "hi", inputLines: 2, expectedLines: []string{"h", "i"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := wrapLines(tc.inputText, tc.inputLines); !reflect.DeepEqual(got, tc.expectedLines) {
+				t.Fatalf("lines wrong. want=%q, got=%q", tc.expectedLines, got)
+			}
+		})
+	}
+}
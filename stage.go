@@ -0,0 +1,222 @@
+package deepl
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Item is a unit of work of TranslateStage. Translation or Err is set by
+// the stage; Payload is passed through untouched.
+type Item struct {
+	Text string
+	// TargetLang defaults to the target of StageOptions.Request.
+	TargetLang string
+	Payload    interface{}
+
+	Translation *Translation
+	Err         error
+}
+
+// StageOptions configures TranslateStage.
+type StageOptions struct {
+	// BatchSize is the maximum number of items translated together,
+	// MaxTextsPerRequest by default. MaxWait is how long a batch waits to
+	// fill up; by default it takes the items already waiting.
+	BatchSize int
+	MaxWait   time.Duration
+	// Concurrency is the number of batches translated at once, 1 by
+	// default.
+	Concurrency int
+	// Unordered emits items as soon as their translation is decoded, even
+	// before the rest of their batch, instead of in input order.
+	Unordered bool
+	// Request supplies the options other than Text and TargetLang.
+	Request TranslateRequest
+}
+
+// TranslateStage returns a pipeline stage translating the items of its
+// input channel. Items of a batch are grouped by target language; failed
+// items carry their error in Err, retries follow the client's MaxRetries.
+// The stage reads no further than Concurrency batches ahead of its
+// consumer. Its output is closed once the input is closed and drained, or
+// ctx is canceled, in which case the items in flight are dropped. No
+// goroutine outlives the output channel.
+func TranslateStage(ctx context.Context, c *Client, opts StageOptions) func(<-chan Item) <-chan Item {
+	if opts.BatchSize < 1 || opts.BatchSize > maxTextsPerRequest {
+		opts.BatchSize = c.MaxTextsPerRequest
+		if opts.BatchSize < 1 || opts.BatchSize > maxTextsPerRequest {
+			opts.BatchSize = maxTextsPerRequest
+		}
+	}
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+
+	return func(in <-chan Item) <-chan Item {
+		out := make(chan Item)
+		go func() {
+			defer close(out)
+			var wg sync.WaitGroup
+			defer wg.Wait()
+
+			slots := make(chan struct{}, opts.Concurrency)
+			// prev is closed when the previous batch was emitted
+			prev := make(chan struct{})
+			close(prev)
+			for {
+				select {
+				case slots <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				items, ok := readBatch(ctx, c.clock(), in, opts.BatchSize, opts.MaxWait)
+				if len(items) > 0 {
+					next := make(chan struct{})
+					wg.Add(1)
+					go func(items []Item, prev, next chan struct{}) {
+						defer wg.Done()
+						defer func() { <-slots }()
+						defer close(next)
+						emitted := make([]bool, len(items))
+						emit := func(i int) bool {
+							select {
+							case out <- items[i]:
+								emitted[i] = true
+								return true
+							case <-ctx.Done():
+								return false
+							}
+						}
+						if opts.Unordered {
+							// translations are emitted as they are decoded
+							translateItems(ctx, c, opts.Request, items, func(i int) { emit(i) })
+						} else {
+							translateItems(ctx, c, opts.Request, items, nil)
+							select {
+							case <-prev:
+							case <-ctx.Done():
+								return
+							}
+						}
+						for i := range items {
+							if !emitted[i] && !emit(i) {
+								return
+							}
+						}
+					}(items, prev, next)
+					prev = next
+				} else {
+					<-slots
+				}
+				if !ok {
+					return
+				}
+			}
+		}()
+		return out
+	}
+}
+
+// readBatch reads up to size items, waiting up to maxWait after the first.
+// It reports false when in is closed or ctx is canceled.
+func readBatch(ctx context.Context, clock Clock, in <-chan Item, size int, maxWait time.Duration) ([]Item, bool) {
+	var items []Item
+	select {
+	case item, ok := <-in:
+		if !ok {
+			return nil, false
+		}
+		items = append(items, item)
+	case <-ctx.Done():
+		return nil, false
+	}
+
+	var timeout <-chan time.Time
+	if maxWait > 0 {
+		timer := clock.NewTimer(maxWait)
+		defer timer.Stop()
+		timeout = timer.C()
+	}
+	for len(items) < size {
+		if timeout == nil {
+			select {
+			case item, ok := <-in:
+				if !ok {
+					return items, false
+				}
+				items = append(items, item)
+				continue
+			default:
+				return items, true
+			}
+		}
+		select {
+		case item, ok := <-in:
+			if !ok {
+				return items, false
+			}
+			items = append(items, item)
+		case <-timeout:
+			return items, true
+		case <-ctx.Done():
+			return items, false
+		}
+	}
+	return items, true
+}
+
+// translateItems sets the translation or error of items, sending one
+// request per target language and chunk. When emit is not nil it is called
+// with the index of every item translated before its request completed.
+func translateItems(ctx context.Context, c *Client, req TranslateRequest, items []Item, emit func(i int)) {
+	var targets []string
+	byTarget := make(map[string][]int)
+	for i := range items {
+		target := items[i].TargetLang
+		if target == "" {
+			target = req.TargetLang
+		}
+		if _, ok := byTarget[target]; !ok {
+			targets = append(targets, target)
+		}
+		byTarget[target] = append(byTarget[target], i)
+	}
+
+	for _, target := range targets {
+		indexes := byTarget[target]
+		texts := make([]string, len(indexes))
+		for j, i := range indexes {
+			texts[j] = items[i].Text
+		}
+		for _, ch := range c.chunks(texts) {
+			r := req
+			r.TargetLang = target
+			r.Text = texts[ch.start:ch.end]
+			chunkIndexes := indexes[ch.start:ch.end]
+			var opts []TranslateOption
+			if emit != nil {
+				opts = append(opts, WithTranslationHandler(func(j int, t Translation) {
+					// a response with too many translations fails below
+					if j < len(chunkIndexes) {
+						items[chunkIndexes[j]].Translation = &t
+						emit(chunkIndexes[j])
+					}
+				}))
+			}
+			result, err := c.Translate(ctx, r, opts...)
+			if err == nil && len(result.Translations) != len(r.Text) {
+				err = xerrors.Errorf("Failed to translate items: expected %d translations, got %d", len(r.Text), len(result.Translations))
+			}
+			for j, i := range chunkIndexes {
+				if err != nil {
+					items[i].Err = err
+					continue
+				}
+				items[i].Translation = &result.Translations[j]
+			}
+		}
+	}
+}
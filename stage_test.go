@@ -0,0 +1,161 @@
+package deepl
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+// initStageServer translates like prefixTranslations, answers target XX
+// with 400 and delays requests whose first text is a number by that many
+// milliseconds.
+func initStageServer(t *testing.T) (*Client, func() int, func()) {
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var r TranslateRequest
+		if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+			t.Errorf("failed to decode request body: %s", err.Error())
+		}
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		if r.TargetLang == "XX" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":"Value for 'target_lang' not supported."}`))
+			return
+		}
+		if ms, err := strconv.Atoi(r.Text[0]); err == nil {
+			time.Sleep(time.Duration(ms) * time.Millisecond)
+		}
+		json.NewEncoder(w).Encode(TranslateResult{Translations: prefixTranslations(r)})
+	}))
+
+	cli, err := New(server.URL, nil, WithAPIKey("test"), WithRequestEncoding(RequestEncodingJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+	return cli, count, server.Close
+}
+
+func feed(items ...Item) <-chan Item {
+	in := make(chan Item)
+	go func() {
+		defer close(in)
+		for _, item := range items {
+			in <- item
+		}
+	}()
+	return in
+}
+
+func TestTranslateStage(t *testing.T) {
+	cli, requests, teardown := initStageServer(t)
+	defer teardown()
+
+	// later batches are answered first
+	var items []Item
+	for i, text := range []string{"40", "a", "30", "b", "0", "c"} {
+		items = append(items, Item{Text: text, Payload: i})
+	}
+	stage := TranslateStage(context.Background(), cli, StageOptions{BatchSize: 2, Concurrency: 3, Request: TranslateRequest{TargetLang: "DE"}})
+
+	i := 0
+	for item := range stage(feed(items...)) {
+		if item.Payload != i || item.Err != nil || item.Translation.Text != "DE:"+items[i].Text {
+			t.Fatalf("unexpected item %d: %+v", i, item)
+		}
+		i++
+	}
+	if i != len(items) {
+		t.Fatalf("got %d items, expected %d", i, len(items))
+	}
+	if requests() < 3 {
+		t.Fatalf("expected batches of at most 2 items, got %d requests", requests())
+	}
+}
+
+func TestTranslateStageErrors(t *testing.T) {
+	cli, requests, teardown := initStageServer(t)
+	defer teardown()
+
+	in := make(chan Item, 3)
+	in <- Item{Text: "a", TargetLang: "FR"}
+	in <- Item{Text: "b", TargetLang: "XX"}
+	in <- Item{Text: "c"}
+	close(in)
+	stage := TranslateStage(context.Background(), cli, StageOptions{MaxWait: time.Second, Request: TranslateRequest{TargetLang: "DE"}})
+
+	var got []Item
+	for item := range stage(in) {
+		got = append(got, item)
+	}
+	if len(got) != 3 || requests() != 3 {
+		t.Fatalf("unexpected items %+v after %d requests", got, requests())
+	}
+	if got[0].Translation.Text != "FR:a" || got[2].Translation.Text != "DE:c" {
+		t.Fatalf("unexpected translations %+v", got)
+	}
+	var apiErr *APIError
+	if !xerrors.As(got[1].Err, &apiErr) || got[1].Translation != nil {
+		t.Fatalf("expected *APIError, got %+v", got[1])
+	}
+}
+
+func TestTranslateStageUnordered(t *testing.T) {
+	cli, _, teardown := initStageServer(t)
+	defer teardown()
+
+	stage := TranslateStage(context.Background(), cli, StageOptions{BatchSize: 1, Concurrency: 2, Unordered: true, Request: TranslateRequest{TargetLang: "DE"}})
+	var texts []string
+	for item := range stage(feed(Item{Text: "50"}, Item{Text: "a"})) {
+		texts = append(texts, item.Text)
+	}
+	if len(texts) != 2 || texts[0] != "a" {
+		t.Fatalf("expected the fast item first, got %v", texts)
+	}
+}
+
+func TestTranslateStageNoLeak(t *testing.T) {
+	before := runtime.NumGoroutine()
+	cli, _, teardown := initStageServer(t)
+
+	// input closed
+	for range TranslateStage(context.Background(), cli, StageOptions{Concurrency: 4})(feed(Item{Text: "a", TargetLang: "DE"})) {
+	}
+
+	// canceled with a blocked consumer and an open input
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan Item)
+	out := TranslateStage(ctx, cli, StageOptions{BatchSize: 1, Concurrency: 4, Request: TranslateRequest{TargetLang: "DE"}})(in)
+	for i := 0; i < 3; i++ {
+		in <- Item{Text: "10"}
+	}
+	cancel()
+	deadline := time.After(time.Second)
+	for closed := false; !closed; {
+		select {
+		case _, ok := <-out:
+			closed = !ok
+		case <-deadline:
+			t.Fatal("output not closed after cancel")
+		}
+	}
+
+	teardown()
+	cli.HTTPClient.CloseIdleConnections()
+	waitFor(t, func() bool { return runtime.NumGoroutine() <= before })
+}
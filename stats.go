@@ -0,0 +1,88 @@
+package deepl
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// LanguageStats summarizes how much longer or shorter translations into one
+// target language are than their sources. Lengths are counted in
+// characters.
+type LanguageStats struct {
+	Texts       int
+	SourceChars int
+	TargetChars int
+	// ExpansionRatio is TargetChars / SourceChars.
+	ExpansionRatio float64
+	// Largest holds the texts with the largest expansion, largest first.
+	Largest []Expansion
+}
+
+// Expansion is the length change of a single translated text.
+type Expansion struct {
+	// Index is the position of the text in its request.
+	Index       int
+	Source      string
+	Target      string
+	SourceChars int
+	TargetChars int
+	Ratio       float64
+}
+
+// Stats computes length statistics for r keyed by target language. It keeps
+// the top texts with the largest expansion per language.
+func (r *TranslateResult) Stats(top int) map[string]LanguageStats {
+	return Stats(top, r)
+}
+
+// Stats computes length statistics over several results, for example one
+// per target language, keyed by target language.
+func Stats(top int, results ...*TranslateResult) map[string]LanguageStats {
+	stats := make(map[string]LanguageStats)
+	for _, r := range results {
+		s := stats[r.targetLang]
+		for i, t := range r.Translations {
+			if i >= len(r.sourceTexts) {
+				break
+			}
+			e := Expansion{
+				Index:       i,
+				Source:      r.sourceTexts[i],
+				Target:      t.Text,
+				SourceChars: utf8.RuneCountInString(r.sourceTexts[i]),
+				TargetChars: utf8.RuneCountInString(t.Text),
+			}
+			e.Ratio = ratio(e.TargetChars, e.SourceChars)
+			s.Texts++
+			s.SourceChars += e.SourceChars
+			s.TargetChars += e.TargetChars
+			if top > 0 {
+				s.Largest = insertExpansion(s.Largest, e, top)
+			}
+		}
+		s.ExpansionRatio = ratio(s.TargetChars, s.SourceChars)
+		stats[r.targetLang] = s
+	}
+	return stats
+}
+
+func ratio(target, source int) float64 {
+	if source == 0 {
+		return 0
+	}
+	return float64(target) / float64(source)
+}
+
+// insertExpansion adds e to the sorted list, keeping at most top entries.
+func insertExpansion(list []Expansion, e Expansion, top int) []Expansion {
+	i := sort.Search(len(list), func(i int) bool { return list[i].Ratio < e.Ratio })
+	if i >= top {
+		return list
+	}
+	if len(list) < top {
+		list = append(list, Expansion{})
+	}
+	copy(list[i+1:], list[i:])
+	list[i] = e
+	return list
+}
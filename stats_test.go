@@ -0,0 +1,46 @@
+package deepl
+
+import (
+	"math"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestTranslateResult_Stats(t *testing.T) {
+	cli, _, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+
+	de, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"abcd", "ab", "abcdefgh"}, TargetLang: "de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ja, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"ab"}, TargetLang: "JA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// translations are "DE:" + source, so each one is 3 characters longer
+	stats := de.Stats(2)
+	s, ok := stats["DE"]
+	if !ok || len(stats) != 1 {
+		t.Fatalf("unexpected languages: %v", stats)
+	}
+	if s.Texts != 3 || s.SourceChars != 14 || s.TargetChars != 23 {
+		t.Fatalf("unexpected totals: %+v", s)
+	}
+	if math.Abs(s.ExpansionRatio-23.0/14.0) > 1e-9 {
+		t.Fatalf("ExpansionRatio = %v", s.ExpansionRatio)
+	}
+	if len(s.Largest) != 2 || s.Largest[0].Index != 1 || s.Largest[1].Index != 0 {
+		t.Fatalf("unexpected largest expansions: %+v", s.Largest)
+	}
+	if s.Largest[0].Source != "ab" || s.Largest[0].Target != "DE:ab" || s.Largest[0].Ratio != 2.5 {
+		t.Fatalf("unexpected largest expansion: %+v", s.Largest[0])
+	}
+
+	all := Stats(0, de, ja)
+	if len(all) != 2 || all["JA"].TargetChars != 5 || all["DE"].Largest != nil {
+		t.Fatalf("unexpected merged stats: %+v", all)
+	}
+}
@@ -0,0 +1,192 @@
+package deepl
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// WithTranslationHandler calls f with every translation of the call, in
+// order, before Translate returns. When nothing but the entity and newline
+// handling of the client has to process the response, translations are
+// passed on as soon as they are decoded, so f may see translations of a
+// call that fails later on. f is called from the goroutine of Translate.
+func WithTranslationHandler(f func(index int, t Translation)) TranslateOption {
+	return func(c *translateCall) { c.onTranslation = f }
+}
+
+type translationHandlerKey struct{}
+
+// translationHandler counts the translations passed to f.
+type translationHandler struct {
+	f         func(index int, t Translation)
+	delivered int
+}
+
+func (h *translationHandler) deliver(translations []Translation) {
+	for ; h.delivered < len(translations); h.delivered++ {
+		h.f(h.delivered, translations[h.delivered])
+	}
+}
+
+func translationHandlerFrom(ctx context.Context) *translationHandler {
+	h, _ := ctx.Value(translationHandlerKey{}).(*translationHandler)
+	return h
+}
+
+// streamDecoder is implemented by destinations that decode a successful
+// response token by token.
+type streamDecoder interface {
+	decodeJSON(dec *json.Decoder) error
+}
+
+// translationStream decodes a translate response into result, calling each,
+// if set, with every translation as soon as it was read.
+type translationStream struct {
+	result *TranslateResult
+	each   func(i int, t *Translation) error
+}
+
+func (s *translationStream) decodeJSON(dec *json.Decoder) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key, _ := tok.(string); !strings.EqualFold(key, "translations") {
+			var skipped json.RawMessage
+			if err := dec.Decode(&skipped); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if tok, err = dec.Token(); err != nil {
+			return err
+		}
+		if tok == nil {
+			s.result.Translations = nil
+			continue
+		}
+		if tok != json.Delim('[') {
+			return xerrors.Errorf("cannot unmarshal %v into translations", tok)
+		}
+		s.result.Translations = []Translation{}
+		for dec.More() {
+			var t Translation
+			if err := dec.Decode(&t); err != nil {
+				return err
+			}
+			if s.each != nil {
+				if err := s.each(len(s.result.Translations), &t); err != nil {
+					return err
+				}
+			}
+			s.result.Translations = append(s.result.Translations, t)
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return xerrors.Errorf("expected %v, got %v", delim, tok)
+	}
+	return nil
+}
+
+// readErrorRecorder keeps the first read error other than io.EOF, to tell
+// transport failures from malformed JSON.
+type readErrorRecorder struct {
+	r   io.Reader
+	err error
+}
+
+func (r *readErrorRecorder) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
+
+// decodeStream decodes a successful response while reading it, instead of
+// buffering the whole body first.
+func decodeStream(body io.Reader, outStruct interface{}) error {
+	recorder := &readErrorRecorder{r: body}
+	br := bufio.NewReader(recorder)
+	first, err := skipSpace(br)
+	if recorder.err != nil {
+		return xerrors.Errorf("Failed to read response: %w", recorder.err)
+	}
+	// a body starting with n can only be valid JSON as null
+	if err == io.EOF || first == 'n' {
+		return xerrors.New("Failed to parse Json: empty response body")
+	}
+	if outStruct == nil {
+		if _, err := io.Copy(ioutil.Discard, br); err != nil {
+			return xerrors.Errorf("Failed to read response: %w", err)
+		}
+		return nil
+	}
+	if err := checkDestination(outStruct); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(br)
+	switch out := outStruct.(type) {
+	case streamDecoder:
+		err = out.decodeJSON(dec)
+	case *TranslateResult:
+		// json.Decoder buffers whole values, so large batches are decoded
+		// one translation at a time
+		err = (&translationStream{result: out}).decodeJSON(dec)
+	default:
+		err = dec.Decode(outStruct)
+	}
+	if err == nil {
+		if _, err = dec.Token(); err == io.EOF {
+			err = nil
+		} else if err == nil {
+			err = xerrors.New("invalid character after top-level value")
+		}
+	}
+	if recorder.err != nil {
+		return xerrors.Errorf("Failed to read response: %w", recorder.err)
+	}
+	if err != nil {
+		return xerrors.Errorf("Failed to parse Json: %w", err)
+	}
+	return validateDestination(outStruct)
+}
+
+// skipSpace returns the first byte of r that isn't JSON whitespace, leaving
+// it unread.
+func skipSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, r.UnreadByte()
+	}
+}
@@ -0,0 +1,174 @@
+package deepl
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+type failingReader struct {
+	r io.Reader
+}
+
+func (r failingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err == io.EOF {
+		return n, io.ErrClosedPipe
+	}
+	return n, err
+}
+
+func TestDecodeStream(t *testing.T) {
+	tt := []struct {
+		name string
+
+		body io.Reader
+
+		expected      []string
+		expectedError string
+	}{
+		{name: "translations", body: strings.NewReader(` {"translations":[{"text":"a"},{"text":"b"}],"extra":{"x":[1]}} `), expected: []string{"a", "b"}},
+		{name: "case-insensitive keys", body: strings.NewReader(`{"Translations":[{"text":"a"}]}`), expected: []string{"a"}},
+		{name: "empty", body: strings.NewReader("  \n"), expectedError: "Failed to parse Json: empty response body"},
+		{name: "null", body: strings.NewReader("null"), expectedError: "Failed to parse Json: empty response body"},
+		{name: "trailing data", body: strings.NewReader(`{"translations":[]} {}`), expectedError: "Failed to parse Json: invalid character after top-level value"},
+		{name: "array", body: strings.NewReader(`[]`), expectedError: "Failed to parse Json: expected {, got ["},
+		{name: "truncated", body: strings.NewReader(`{"translations":[{"text":"a"}`), expectedError: "Failed to parse Json: unexpected end of JSON input"},
+		{name: "read error", body: failingReader{strings.NewReader(`{"translations":[`)}, expectedError: "Failed to read response: io: read/write on closed pipe"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var result TranslateResult
+			var seen []string
+			stream := &translationStream{result: &result, each: func(i int, t *Translation) error {
+				seen = append(seen, t.Text)
+				return nil
+			}}
+			err := decodeStream(tc.body, stream)
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Fatalf("got error %v, expected %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(seen, ",") != strings.Join(tc.expected, ",") || len(result.Translations) != len(tc.expected) {
+				t.Fatalf("got %v and %+v, expected %v", seen, result.Translations, tc.expected)
+			}
+		})
+	}
+}
+
+func TestResponseParse_StreamMatchesBuffered(t *testing.T) {
+	body := `{"translations":[{"detected_source_language":"EN","text":"Hallo","billed_characters":5}]}`
+	var streamed, buffered TranslateResult
+	if err := responseParse(&http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, &streamed, stdJSON{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := responseParse(&http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, &buffered, &countingJSON{}); err != nil {
+		t.Fatal(err)
+	}
+	if streamed.Translations[0] != buffered.Translations[0] {
+		t.Fatalf("streamed %+v, buffered %+v", streamed.Translations[0], buffered.Translations[0])
+	}
+	var response TranslateResponse
+	err := responseParse(&http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{"translations":[]}`))}, &response, stdJSON{})
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+}
+
+// initSplitServer answers with the first translation, then waits for
+// release before sending the rest of the response.
+func initSplitServer(t *testing.T) (*Client, chan struct{}, func()) {
+	if _, ok := defaultJSONCodec.(stdJSON); !ok {
+		t.Skip("other codecs decode buffered responses")
+	}
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"translations":[{"text":"first"},`))
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		w.Write([]byte(`{"text":"second"}]}`))
+	}))
+	cli, err := New(server.URL, nil, WithAPIKey("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cli, release, server.Close
+}
+
+func TestClient_TranslationHandler(t *testing.T) {
+	cli, release, teardown := initSplitServer(t)
+	defer teardown()
+
+	var texts []string
+	result, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"a", "b"}, TargetLang: "DE"}, WithTranslationHandler(func(i int, t Translation) {
+		texts = append(texts, t.Text)
+		if i == 0 {
+			// the second translation hasn't been sent yet
+			close(release)
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(texts, ",") != "first,second" || result.Translations[1].Text != "second" || result.Translations[0].Source != SourceAPI {
+		t.Fatalf("got %v and %+v", texts, result.Translations)
+	}
+}
+
+func TestClient_TranslationHandlerAfterProcessing(t *testing.T) {
+	cli, _, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	cli.ProtectedPatterns = []*regexp.Regexp{regexp.MustCompile(`\d+`)}
+
+	var texts []string
+	_, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"1 apple"}, TargetLang: "DE"}, WithTranslationHandler(func(i int, t Translation) {
+		texts = append(texts, t.Text)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(texts) != 1 || !strings.Contains(texts[0], "1 apple") {
+		t.Fatalf("handler got %q", texts)
+	}
+}
+
+func TestTranslateStageStreamsUnordered(t *testing.T) {
+	cli, release, teardown := initSplitServer(t)
+	defer teardown()
+
+	in := make(chan Item, 2)
+	in <- Item{Text: "a"}
+	in <- Item{Text: "b"}
+	close(in)
+	out := TranslateStage(context.Background(), cli, StageOptions{Unordered: true, Request: TranslateRequest{TargetLang: "DE"}})(in)
+	// the batch of both items is still waiting for its second translation
+	first := <-out
+	if first.Translation == nil || first.Translation.Text != "first" {
+		t.Fatalf("unexpected first item %+v", first)
+	}
+	close(release)
+	var buf bytes.Buffer
+	for item := range out {
+		buf.WriteString(item.Translation.Text)
+	}
+	if buf.String() != "second" {
+		t.Fatalf("unexpected remaining items %q", buf.String())
+	}
+}
@@ -0,0 +1,85 @@
+package deepl
+
+import (
+	"unicode"
+
+	"golang.org/x/xerrors"
+)
+
+// xmlNameStart and xmlNameChar are the NameStartChar and NameChar ranges of
+// the XML 1.0 specification, section 2.3.
+var (
+	xmlNameStart = &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{Lo: ':', Hi: ':', Stride: 1},
+			{Lo: 'A', Hi: 'Z', Stride: 1},
+			{Lo: '_', Hi: '_', Stride: 1},
+			{Lo: 'a', Hi: 'z', Stride: 1},
+			{Lo: 0xC0, Hi: 0xD6, Stride: 1},
+			{Lo: 0xD8, Hi: 0xF6, Stride: 1},
+			{Lo: 0xF8, Hi: 0x2FF, Stride: 1},
+			{Lo: 0x370, Hi: 0x37D, Stride: 1},
+			{Lo: 0x37F, Hi: 0x1FFF, Stride: 1},
+			{Lo: 0x200C, Hi: 0x200D, Stride: 1},
+			{Lo: 0x2070, Hi: 0x218F, Stride: 1},
+			{Lo: 0x2C00, Hi: 0x2FEF, Stride: 1},
+			{Lo: 0x3001, Hi: 0xD7FF, Stride: 1},
+			{Lo: 0xF900, Hi: 0xFDCF, Stride: 1},
+			{Lo: 0xFDF0, Hi: 0xFFFD, Stride: 1},
+		},
+		R32: []unicode.Range32{
+			{Lo: 0x10000, Hi: 0xEFFFF, Stride: 1},
+		},
+	}
+	xmlNameChar = &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{Lo: '-', Hi: '.', Stride: 1},
+			{Lo: '0', Hi: '9', Stride: 1},
+			{Lo: 0xB7, Hi: 0xB7, Stride: 1},
+			{Lo: 0x300, Hi: 0x36F, Stride: 1},
+			{Lo: 0x203F, Hi: 0x2040, Stride: 1},
+		},
+	}
+)
+
+// ValidateTagName checks that name is an XML name that can be sent in a
+// comma-separated tag list such as ignore_tags.
+func ValidateTagName(name string) error {
+	if name == "" {
+		return xerrors.New("Invalid tag name: empty")
+	}
+	for i, r := range name {
+		switch {
+		case r == ',':
+			return xerrors.Errorf("Invalid tag name %q: comma at byte %d would split it into two tags", name, i)
+		case unicode.IsSpace(r):
+			return xerrors.Errorf("Invalid tag name %q: whitespace at byte %d", name, i)
+		case unicode.Is(xmlNameStart, r):
+		case i > 0 && unicode.Is(xmlNameChar, r):
+		default:
+			return xerrors.Errorf("Invalid tag name %q: %q at byte %d is not allowed in XML names", name, r, i)
+		}
+	}
+	return nil
+}
+
+// validateTags checks the tag lists of r. They are sent as arrays in JSON
+// bodies and comma-separated in forms, so the same rules apply to both.
+func (r *TranslateRequest) validateTags() error {
+	lists := []struct {
+		param string
+		tags  []string
+	}{
+		{"non_splitting_tags", r.NonSplittingTags},
+		{"splitting_tags", r.SplittingTags},
+		{"ignore_tags", r.IgnoreTags},
+	}
+	for _, list := range lists {
+		for _, tag := range list.tags {
+			if err := ValidateTagName(tag); err != nil {
+				return xerrors.Errorf("Failed to validate %s: %w", list.param, err)
+			}
+		}
+	}
+	return nil
+}
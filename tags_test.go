@@ -0,0 +1,60 @@
+package deepl
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestValidateTagName(t *testing.T) {
+	tt := []struct {
+		name string
+
+		expectedErrMessage string
+	}{
+		{name: "x"},
+		{name: "ns:keep-me.v2"},
+		{name: "_private"},
+		{name: "übersetzung"},
+		{name: "訳"},
+		{name: "", expectedErrMessage: "empty"},
+		{name: "a,b", expectedErrMessage: "comma at byte 1"},
+		{name: "a b", expectedErrMessage: "whitespace at byte 1"},
+		{name: "x\t", expectedErrMessage: "whitespace at byte 1"},
+		{name: "1st", expectedErrMessage: `'1' at byte 0`},
+		{name: "-x", expectedErrMessage: `'-' at byte 0`},
+		{name: "a<b", expectedErrMessage: `'<' at byte 1`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTagName(tc.name)
+			if tc.expectedErrMessage == "" {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErrMessage) {
+				t.Fatalf("expected error containing %q, got %v", tc.expectedErrMessage, err)
+			}
+		})
+	}
+}
+
+func TestClient_TranslateValidatesTags(t *testing.T) {
+	cli, received, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+
+	for _, encoding := range []RequestEncoding{RequestEncodingJSON, RequestEncodingForm} {
+		cli.RequestEncoding = encoding
+		_, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE", IgnoreTags: []string{"keep", "a,b"}})
+		if err == nil || !strings.Contains(err.Error(), "Failed to validate ignore_tags") {
+			t.Fatalf("expected ignore_tags error, got %v", err)
+		}
+	}
+	if len(*received) != 0 {
+		t.Fatalf("invalid request was sent: %+v", *received)
+	}
+}
@@ -0,0 +1,84 @@
+package deepl
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// Tasks runs translations concurrently under the client's rate limit and
+// concurrency limit and collects the results in submission order. Create it
+// with Client.Go.
+type Tasks struct {
+	// ContinueOnError keeps the remaining tasks running after a failure.
+	// By default the first error cancels the tasks still outstanding.
+	ContinueOnError bool
+
+	client *Client
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	results  []TaskResult
+	firstErr error
+}
+
+// TaskResult is the outcome of one submitted text.
+type TaskResult struct {
+	Translation *Translation
+	Err         error
+}
+
+// Go returns a task runner bound to ctx, similar to errgroup.WithContext.
+func (c *Client) Go(ctx context.Context) *Tasks {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Tasks{client: c, ctx: ctx, cancel: cancel}
+}
+
+// Submit starts translating text and returns its index in the results of
+// Wait.
+func (t *Tasks) Submit(text, sourceLang, targetLang string, opts ...TranslateOption) int {
+	t.mu.Lock()
+	index := len(t.results)
+	t.results = append(t.results, TaskResult{})
+	t.mu.Unlock()
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		var res TaskResult
+		result, err := t.client.Translate(t.ctx, TranslateRequest{Text: []string{text}, SourceLang: sourceLang, TargetLang: targetLang}, opts...)
+		if err == nil {
+			res.Translation = &result.Translations[0]
+		} else {
+			res.Err = err
+		}
+
+		t.mu.Lock()
+		t.results[index] = res
+		if err != nil && t.firstErr == nil {
+			t.firstErr = err
+			if !t.ContinueOnError {
+				t.cancel()
+			}
+		}
+		t.mu.Unlock()
+	}()
+	return index
+}
+
+// Wait waits for all submitted texts and returns their results in
+// submission order together with the first error.
+func (t *Tasks) Wait() ([]TaskResult, error) {
+	t.wg.Wait()
+	t.cancel()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.firstErr != nil {
+		return t.results, xerrors.Errorf("Failed to translate texts: %w", t.firstErr)
+	}
+	return t.results, nil
+}
@@ -0,0 +1,98 @@
+package deepl
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func initTasksServer(t *testing.T, failText string) (*Client, *int32, func()) {
+	var inFlight, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+
+		var r TranslateRequest
+		if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+			t.Errorf("failed to decode request body: %s", err.Error())
+		}
+		if r.Text[0] == failText {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+		json.NewEncoder(w).Encode(TranslateResult{Translations: prefixTranslations(r)})
+	}))
+
+	cli, err := New(server.URL, nil, WithAPIKey(testAPIKey), WithPlanSettings(PlanDefaults{MaxConcurrency: 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cli, &peak, server.Close
+}
+
+func TestClient_Go(t *testing.T) {
+	cli, peak, teardown := initTasksServer(t, "")
+	defer teardown()
+
+	tasks := cli.Go(context.Background())
+	texts := []string{"a", "b", "c", "d", "e"}
+	for i, text := range texts {
+		if index := tasks.Submit(text, "EN", "DE"); index != i {
+			t.Fatalf("Submit returned index %d, expected %d", index, i)
+		}
+	}
+	results, err := tasks.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, res := range results {
+		if res.Err != nil || res.Translation.Text != "DE:"+texts[i] {
+			t.Fatalf("result %d wrong: %+v", i, res)
+		}
+	}
+	if atomic.LoadInt32(peak) > 2 {
+		t.Fatalf("peak concurrency %d exceeds the client limit", atomic.LoadInt32(peak))
+	}
+}
+
+func TestClient_GoErrors(t *testing.T) {
+	cli, _, teardown := initTasksServer(t, "bad")
+	defer teardown()
+
+	tasks := cli.Go(context.Background())
+	tasks.Submit("bad", "EN", "DE")
+	time.Sleep(20 * time.Millisecond)
+	tasks.Submit("late", "EN", "DE")
+	results, err := tasks.Wait()
+	if !IsInvalidRequest(err) {
+		t.Fatalf("expected invalid request error, got %v", err)
+	}
+	if results[1].Err == nil {
+		t.Fatal("expected outstanding task to be canceled after the first error")
+	}
+
+	tasks = cli.Go(context.Background())
+	tasks.ContinueOnError = true
+	tasks.Submit("bad", "EN", "DE")
+	time.Sleep(20 * time.Millisecond)
+	tasks.Submit("late", "EN", "DE")
+	results, err = tasks.Wait()
+	if err == nil || results[0].Err == nil {
+		t.Fatal("expected first task to fail")
+	}
+	if results[1].Err != nil || results[1].Translation.Text != "DE:late" {
+		t.Fatalf("expected second task to finish, got %+v", results[1])
+	}
+}
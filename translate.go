@@ -0,0 +1,518 @@
+package deepl
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+type TranslateResponse struct {
+	Translations []Translation `json:"translations"`
+}
+
+// Validate checks that r holds at least one translation, so that a
+// response without translations isn't mistaken for an empty batch. The
+// methods of Client also check that there is one translation per text.
+func (r *TranslateResponse) Validate() error {
+	return r.validate(-1)
+}
+
+func (r *TranslateResponse) validate(texts int) error {
+	return validateTranslations(r.Translations, texts)
+}
+
+// validateTranslations checks that there is one translation per text, or at
+// least one when expected is negative.
+func validateTranslations(translations []Translation, expected int) error {
+	if expected < 0 && len(translations) == 0 {
+		return unexpectedResponse(xerrors.New("response has no translations"))
+	}
+	if expected >= 0 && len(translations) != expected {
+		return &TranslationCountError{Expected: expected, Got: len(translations)}
+	}
+	return nil
+}
+
+type Translation struct {
+	DetectedSourceLanguage string `json:"detected_source_language"`
+	Text                   string `json:"text"`
+	BilledCharacters       int    `json:"billed_characters,omitempty"`
+	ModelTypeUsed          string `json:"model_type_used,omitempty"`
+	// Source tells where the translation came from: SourceAPI, SourceTM,
+	// SourceTable or SourceUntranslated.
+	Source string `json:"-"`
+}
+
+type Formality string
+
+const (
+	FormalityDefault    Formality = "default"
+	FormalityMore       Formality = "more"
+	FormalityLess       Formality = "less"
+	FormalityPreferMore Formality = "prefer_more"
+	FormalityPreferLess Formality = "prefer_less"
+)
+
+type SplitSentences string
+
+// Texts are sent with their newlines unchanged in every mode, as JSON or
+// form bodies. How DeepL treats them depends on the mode:
+//
+//   - SplitSentencesOn, DeepL's default without tag handling, splits on
+//     punctuation and on newlines, so every line is translated separately.
+//   - SplitSentencesNoNewlines splits on punctuation only, so a sentence may
+//     continue across a newline. It is DeepL's default with TagHandlingHTML.
+//   - SplitSentencesOff translates each text as a single sentence.
+//
+// None of them guarantees that a translation has as many lines as its
+// source; use TranslateLines for a 1:1 line mapping.
+const (
+	SplitSentencesOff        SplitSentences = "0"
+	SplitSentencesOn         SplitSentences = "1"
+	SplitSentencesNoNewlines SplitSentences = "nonewlines"
+)
+
+type TagHandling string
+
+const (
+	TagHandlingXML  TagHandling = "xml"
+	TagHandlingHTML TagHandling = "html"
+)
+
+type ModelType string
+
+const (
+	ModelTypeQualityOptimized       ModelType = "quality_optimized"
+	ModelTypePreferQualityOptimized ModelType = "prefer_quality_optimized"
+	ModelTypeLatencyOptimized       ModelType = "latency_optimized"
+)
+
+// TranslateRequest is the wire format of a /v2/translate request. Zero
+// values are omitted from the request. Tag lists must hold XML names, see
+// ValidateTagName.
+type TranslateRequest struct {
+	Text                 []string       `json:"text"`
+	SourceLang           string         `json:"source_lang,omitempty"`
+	TargetLang           string         `json:"target_lang"`
+	Context              string         `json:"context,omitempty"`
+	SplitSentences       SplitSentences `json:"split_sentences,omitempty"`
+	PreserveFormatting   bool           `json:"preserve_formatting,omitempty"`
+	Formality            Formality      `json:"formality,omitempty"`
+	GlossaryID           string         `json:"glossary_id,omitempty"`
+	TagHandling          TagHandling    `json:"tag_handling,omitempty"`
+	NonSplittingTags     []string       `json:"non_splitting_tags,omitempty"`
+	SplittingTags        []string       `json:"splitting_tags,omitempty"`
+	IgnoreTags           []string       `json:"ignore_tags,omitempty"`
+	ModelType            ModelType      `json:"model_type,omitempty"`
+	ShowBilledCharacters bool           `json:"show_billed_characters,omitempty"`
+}
+
+// values encodes r for form bodies, where tag lists are comma-separated and
+// booleans are sent as "1".
+func (r *TranslateRequest) values() url.Values {
+	params := make(url.Values, 14)
+	params["text"] = append([]string(nil), r.Text...)
+	params.Add("target_lang", r.TargetLang)
+
+	addString := func(key, value string) {
+		if value != "" {
+			params.Add(key, value)
+		}
+	}
+	addBool := func(key string, value bool) {
+		if value {
+			params.Add(key, "1")
+		}
+	}
+	addString("source_lang", r.SourceLang)
+	addString("context", r.Context)
+	addString("split_sentences", string(r.SplitSentences))
+	addBool("preserve_formatting", r.PreserveFormatting)
+	addString("formality", string(r.Formality))
+	addString("glossary_id", r.GlossaryID)
+	addString("tag_handling", string(r.TagHandling))
+	addString("non_splitting_tags", strings.Join(r.NonSplittingTags, ","))
+	addString("splitting_tags", strings.Join(r.SplittingTags, ","))
+	addString("ignore_tags", strings.Join(r.IgnoreTags, ","))
+	addString("model_type", string(r.ModelType))
+	addBool("show_billed_characters", r.ShowBilledCharacters)
+	return params
+}
+
+// TranslateResult is returned by Translate.
+type TranslateResult struct {
+	Translations []Translation `json:"translations"`
+	Metadata     Metadata      `json:"-"`
+	// Audit is set when the client was created with WithAuditRecord.
+	Audit *AuditRecord `json:"-"`
+
+	// request data kept for Stats
+	sourceTexts []string
+	targetLang  string
+	// cacheKey, when set, is the TranslationCache key of the translations
+	// at cacheIndexes, or of all of them when cacheIndexes is nil
+	cacheKey     string
+	cacheIndexes []int
+}
+
+// cacheEntry returns the translations to store under r.cacheKey.
+func (r *TranslateResult) cacheEntry() []Translation {
+	if r.cacheIndexes == nil {
+		return append([]Translation(nil), r.Translations...)
+	}
+	entry := make([]Translation, len(r.cacheIndexes))
+	for j, i := range r.cacheIndexes {
+		entry[j] = r.Translations[i]
+	}
+	return entry
+}
+
+// Metadata describes how a result was produced.
+type Metadata struct {
+	// RequestID identifies the call in logs, CallObserver events, audit
+	// records and errors. It is the same for all retries.
+	RequestID string
+	// Attempts is the number of HTTP requests sent, retries and fallback
+	// included.
+	Attempts int
+	// Endpoint is the base URL that served the request.
+	Endpoint string
+	// FellBack reports whether the request was served by FallbackBaseURL.
+	FellBack bool
+	// IdempotencyKey is the Idempotency-Key header sent with the request,
+	// if any.
+	IdempotencyKey string
+	// ClockSkew is the server clock minus the local clock according to the
+	// Date header of the response, or zero without one.
+	ClockSkew time.Duration
+	// Cached is set when the result came from the TranslationCache.
+	Cached bool
+	// Failed is set when soft-fail mode returned the source texts because
+	// of Err, a retryable error.
+	Failed bool
+	Err    error
+	// ShortInputs lists the texts handled by WithShortInputPolicy.
+	ShortInputs []ShortInput
+	// FormalityDropped is set when DeepL rejected the requested formality
+	// and the request was resent with a weaker one, see WithFormalityRetry.
+	FormalityDropped bool
+	// SuspectedTruncations lists translations that look truncated, see
+	// WithTruncationCheck.
+	SuspectedTruncations []SuspectedTruncation
+}
+
+// Texts returns the translated texts in request order.
+func (r *TranslateResult) Texts() []string {
+	texts := make([]string, len(r.Translations))
+	for i, t := range r.Translations {
+		texts[i] = t.Text
+	}
+	return texts
+}
+
+// translateBody encodes r as JSON unless form encoding is requested, either
+// by the client or because the caller is the legacy single-text method.
+func (c *Client) translateBody(r *TranslateRequest, legacy bool) RequestBody {
+	canonical := r.canonical()
+
+	switch c.RequestEncoding {
+	case RequestEncodingJSON:
+		return JSONBody{Value: &canonical}
+	case RequestEncodingForm:
+		return FormBody(canonical.values())
+	default:
+		if legacy {
+			return FormBody(canonical.values())
+		}
+		return JSONBody{Value: &canonical}
+	}
+}
+
+// translate sends r. When raw is not nil it receives the response body.
+func (c *Client) translate(ctx context.Context, r *TranslateRequest, legacy bool, raw *json.RawMessage) (*TranslateResult, error) {
+	if err := r.validateTags(); err != nil {
+		return nil, err
+	}
+	if err := r.validateEnums(); err != nil {
+		return nil, &ConfigError{Code: ConfigInvalidOption, Err: err}
+	}
+	if c.shortInputs != nil {
+		return c.translateShortInputs(ctx, r, legacy, raw)
+	}
+	return c.translateSent(ctx, r, legacy, raw)
+}
+
+// translateSent sends r after checking its source language, resending it
+// once with WithFormalityRetry.
+func (c *Client) translateSent(ctx context.Context, r *TranslateRequest, legacy bool, raw *json.RawMessage) (*TranslateResult, error) {
+	if err := c.sourcePolicy.check(r); err != nil {
+		return nil, err
+	}
+	result, err := c.translateOnce(ctx, r, legacy, raw)
+	// one adjusted retry, never more
+	if err != nil && c.FormalityRetry && r.Formality != "" && isFormalityError(err) {
+		c.logf(ctx, "Retrying with formality %q instead of %q for %s", retryFormality(r.Formality), r.Formality, r.TargetLang)
+		r.Formality = retryFormality(r.Formality)
+		result, err = c.translateOnce(withResendKey(ctx, "formality"), r, legacy, raw)
+		if err == nil {
+			result.Metadata.FormalityDropped = true
+		}
+	}
+	return result, err
+}
+
+func (c *Client) translateOnce(ctx context.Context, r *TranslateRequest, legacy bool, raw *json.RawMessage) (*TranslateResult, error) {
+	if len(c.ProtectedPatterns) > 0 {
+		return c.translateProtected(ctx, r, legacy, raw)
+	}
+	return c.translateUnprotected(ctx, r, legacy, raw)
+}
+
+func (c *Client) translateUnprotected(ctx context.Context, r *TranslateRequest, legacy bool, raw *json.RawMessage) (*TranslateResult, error) {
+	var result TranslateResult
+	// finished counts the translations processed while being decoded
+	finished := 0
+
+	sent := r
+	if c.NormalizeNewlines {
+		normalized := *r
+		normalized.Text = make([]string, len(r.Text))
+		for i, text := range r.Text {
+			normalized.Text[i] = normalizeNewlines(text)
+		}
+		sent = &normalized
+	}
+
+	if c.pseudo != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result = *c.pseudo.translate(sent)
+	} else {
+		var out interface{} = &result
+		if raw != nil {
+			out = &rawCapture{out: &result, raw: raw}
+		} else if h := translationHandlerFrom(ctx); h != nil {
+			out = &translationStream{result: &result, each: func(i int, t *Translation) error {
+				if err := c.finishTranslation(r, i, t); err != nil {
+					return err
+				}
+				finished++
+				h.f(i, *t)
+				h.delivered++
+				return nil
+			}}
+		}
+		settle, err := c.reserveCharacters(sent.Text)
+		if err != nil {
+			return nil, err
+		}
+		body := c.translateBody(sent, legacy)
+		meta, err := c.do(ctx, http.MethodPost, "/v2/translate", body, out)
+		if err != nil {
+			settle(nil)
+			if status, _ := statusOf(err); status == http.StatusRequestEntityTooLarge {
+				_, encoded, _ := body.Encode()
+				return nil, newRequestTooLargeError(sent.Text, len(encoded), err)
+			}
+			return nil, err
+		}
+		settle(&result)
+		result.Metadata = meta
+	}
+	if err := validateTranslations(result.Translations, len(sent.Text)); err != nil {
+		return nil, err
+	}
+	if result.Translations == nil {
+		result.Translations = []Translation{}
+	}
+	for i := finished; i < len(result.Translations); i++ {
+		if err := c.finishTranslation(r, i, &result.Translations[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return &result, nil
+}
+
+// finishTranslation applies the client-side processing of the i-th
+// translation of r.
+func (c *Client) finishTranslation(r *TranslateRequest, i int, t *Translation) error {
+	t.Source = SourceAPI
+	if c.HTMLEntities != HTMLEntitiesKeep && r.TagHandling == "" && i < len(r.Text) {
+		text, err := c.handleEntities(i, r.Text[i], t.Text)
+		if err != nil {
+			return err
+		}
+		t.Text = text
+	}
+	if c.RestoreNewlines && i < len(r.Text) {
+		t.Text = restoreNewlines(r.Text[i], t.Text)
+	}
+	return nil
+}
+
+// Translate translates req.Text into req.TargetLang. Translations are
+// returned in the same order as req.Text. opts are applied to req after
+// the pair profile, if any.
+func (c *Client) Translate(ctx context.Context, req TranslateRequest, opts ...TranslateOption) (*TranslateResult, error) {
+	ctx, id := withRequestID(ctx)
+	result, err := c.translateCall(ctx, req, opts)
+	if err != nil {
+		return nil, withRequestIDError(err, id)
+	}
+	result.Metadata.RequestID = id
+	if result.Audit != nil {
+		result.Audit.RequestID = id
+	}
+	return result, nil
+}
+
+func (c *Client) translateCall(ctx context.Context, req TranslateRequest, opts []TranslateOption) (*TranslateResult, error) {
+	call := c.resolveCall(&req, opts)
+	if call.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, call.timeout)
+		defer cancel()
+	}
+	var handler *translationHandler
+	if call.onTranslation != nil {
+		handler = &translationHandler{f: call.onTranslation}
+		// protected placeholders, translation memory, short inputs and
+		// post-processors rewrite results after decoding
+		if len(c.ProtectedPatterns) == 0 && c.TranslationMemory == nil && c.shortInputs == nil && len(c.PostProcessors) == 0 {
+			ctx = context.WithValue(ctx, translationHandlerKey{}, handler)
+		}
+	}
+	if c.DowngradeFormality {
+		c.downgradeFormality(ctx, &req)
+	}
+	c.warnBareTarget(ctx, req.TargetLang)
+	if call.idempotencyKey != "" {
+		ctx = contextWithIdempotencyKey(ctx, call.idempotencyKey)
+	}
+
+	var result *TranslateResult
+	var err error
+	var cacheKey string
+	if c.duplicates != nil {
+		cacheKey = CanonicalRequestHash(req)
+		c.checkDuplicate(ctx, cacheKey)
+	}
+	useCache := c.TranslationCache != nil && call.raw == nil
+	if useCache && c.TranslationMemory == nil {
+		if cacheKey == "" {
+			cacheKey = CanonicalRequestHash(req)
+		}
+		if translations, ok := c.cachedTranslations(cacheKey, len(req.Text)); ok {
+			result = &TranslateResult{Translations: translations}
+			result.Metadata.Cached = true
+		}
+	}
+	switch {
+	case result != nil:
+	case c.TranslationMemory != nil:
+		// the memory wins over the cache, which only sees the texts it
+		// doesn't know
+		result, err = c.translateWithMemory(ctx, req, call.raw)
+	default:
+		result, err = c.translate(ctx, &req, false, call.raw)
+		if err == nil && useCache {
+			result.cacheKey = cacheKey
+		}
+	}
+	if err == nil && c.truncationCheck != nil {
+		if suspects := c.truncationCheck.check(&req, result.Translations); len(suspects) > 0 {
+			if c.truncationCheck.Strict {
+				return nil, &TruncationError{Suspects: suspects}
+			}
+			result.Metadata.SuspectedTruncations = suspects
+		}
+	}
+	if err == nil && result.cacheKey != "" {
+		c.TranslationCache.Set(result.cacheKey, result.cacheEntry())
+	}
+	if err != nil {
+		if !(c.SoftFail || call.softFail) || !IsRetryable(err) {
+			return nil, err
+		}
+		result = softFailResult(req.Text, err)
+	}
+	result.sourceTexts = req.Text
+	result.targetLang = strings.ToUpper(req.TargetLang)
+	if c.UsageTracker != nil && !result.Metadata.Cached && !result.Metadata.Failed {
+		c.UsageTracker.Add(CostTags(ctx), billedCharacters(req.Text, result))
+	}
+	if c.AuditRecords {
+		result.Audit = newAuditRecord(req)
+		result.Audit.CostTags = CostTags(ctx)
+	}
+	if len(c.PostProcessors) > 0 {
+		c.postProcess(&req, result.Translations)
+	}
+	if handler != nil {
+		handler.deliver(result.Translations)
+	}
+	return result, nil
+}
+
+// softFailResult returns texts untranslated in place of a failed call.
+func softFailResult(texts []string, err error) *TranslateResult {
+	result := &TranslateResult{Translations: make([]Translation, len(texts))}
+	for i, text := range texts {
+		result.Translations[i] = Translation{Text: text, Source: SourceUntranslated}
+	}
+	result.Metadata.Failed = true
+	result.Metadata.Err = err
+	return result
+}
+
+// TranslateSentence translates a single text with a form request.
+//
+// Deprecated: Use Translate or TranslateText.
+func (c *Client) TranslateSentence(ctx context.Context, text string, sourceLang string, targetLang string) (*TranslateResponse, error) {
+	c.warnDeprecated(ctx, DeprecatedTranslateSentence, "TranslateSentence is deprecated, use Translate or TranslateText")
+	req := &TranslateRequest{
+		Text:       []string{text},
+		SourceLang: sourceLang,
+		TargetLang: targetLang,
+	}
+	if call := c.resolveCall(req, nil); call.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, call.timeout)
+		defer cancel()
+	}
+	c.warnBareTarget(ctx, req.TargetLang)
+	result, err := c.translate(ctx, req, true, nil)
+	if err != nil {
+		return nil, err
+	}
+	response := &TranslateResponse{Translations: result.Translations}
+	if err := response.validate(len(req.Text)); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// TranslateTexts translates several texts in one request. Translations are
+// returned in the same order as texts.
+func (c *Client) TranslateTexts(ctx context.Context, texts []string, sourceLang string, targetLang string) (*TranslateResponse, error) {
+	result, err := c.Translate(ctx, TranslateRequest{
+		Text:       texts,
+		SourceLang: sourceLang,
+		TargetLang: targetLang,
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := &TranslateResponse{Translations: result.Translations}
+	if err := response.validate(len(texts)); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,443 @@
+package deepl
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+func TestClient_TranslateTexts(t *testing.T) {
+	tt := []struct {
+		name string
+
+		inputTexts      []string
+		inputSourceLang string
+		inputTargetLang string
+		inputEncoding   RequestEncoding
+
+		mockResponseHeaderFile string
+		mockResponseBodyFile   string
+
+		expectedBody       string
+		expectedResponse   *TranslateResponse
+		expectedErrMessage string
+	}{
+		{
+			name: "json by default",
+
+			inputTexts:      []string{"hello", "goodbye"},
+			inputSourceLang: "EN",
+			inputTargetLang: "JA",
+
+			mockResponseHeaderFile: "testdata/TranslateTexts/success-header",
+			mockResponseBodyFile:   "testdata/TranslateTexts/success-body",
+
+			expectedBody: `{"text":["hello","goodbye"],"source_lang":"EN","target_lang":"JA"}`,
+			expectedResponse: &TranslateResponse{[]Translation{
+				{DetectedSourceLanguage: "EN", Text: "こんにちわ"},
+				{DetectedSourceLanguage: "EN", Text: "さようなら"},
+			}},
+		},
+		{
+			name: "json omits empty source_lang",
+
+			inputTexts:      []string{"hello", "goodbye"},
+			inputTargetLang: "JA",
+
+			mockResponseHeaderFile: "testdata/TranslateTexts/success-header",
+			mockResponseBodyFile:   "testdata/TranslateTexts/success-body",
+
+			expectedBody: `{"text":["hello","goodbye"],"target_lang":"JA"}`,
+			expectedResponse: &TranslateResponse{[]Translation{
+				{DetectedSourceLanguage: "EN", Text: "こんにちわ"},
+				{DetectedSourceLanguage: "EN", Text: "さようなら"},
+			}},
+		},
+		{
+			name: "form encoding option",
+
+			inputTexts:      []string{"hello", "goodbye"},
+			inputSourceLang: "EN",
+			inputTargetLang: "JA",
+			inputEncoding:   RequestEncodingForm,
+
+			mockResponseHeaderFile: "testdata/TranslateTexts/success-header",
+			mockResponseBodyFile:   "testdata/TranslateTexts/success-body",
+
+			expectedBody: "source_lang=EN&target_lang=JA&text=hello&text=goodbye",
+			expectedResponse: &TranslateResponse{[]Translation{
+				{DetectedSourceLanguage: "EN", Text: "こんにちわ"},
+				{DetectedSourceLanguage: "EN", Text: "さようなら"},
+			}},
+		},
+		{
+			name: "bad request",
+
+			inputTexts:      []string{"hello"},
+			inputSourceLang: "EN",
+			inputTargetLang: "AA",
+
+			mockResponseHeaderFile: "testdata/TranslateText/unsuport-target_lang-header",
+			mockResponseBodyFile:   "testdata/TranslateText/unsuport-target_lang-body",
+
+			expectedBody:       `{"text":["hello"],"source_lang":"EN","target_lang":"AA"}`,
+			expectedErrMessage: "Bad request.",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, teardown := initTestServer(t, tc.mockResponseHeaderFile, tc.mockResponseBodyFile, http.MethodPost, "/v2/translate", "", tc.expectedBody)
+			defer teardown()
+			cli.RequestEncoding = tc.inputEncoding
+
+			correctResponse, err := cli.TranslateTexts(context.Background(), tc.inputTexts, tc.inputSourceLang, tc.inputTargetLang)
+			if tc.expectedErrMessage == "" {
+				if err != nil {
+					t.Fatalf("response error should be nil. got=%s", err.Error())
+				}
+				if len(correctResponse.Translations) != len(tc.expectedResponse.Translations) {
+					t.Fatalf("response items wrong. want=%+v, got=%+v", tc.expectedResponse, correctResponse)
+				}
+				for i, v := range correctResponse.Translations {
+					if v.DetectedSourceLanguage != tc.expectedResponse.Translations[i].DetectedSourceLanguage || v.Text != tc.expectedResponse.Translations[i].Text {
+						t.Fatalf("response items wrong. want=%+v, got=%+v", tc.expectedResponse, correctResponse)
+					}
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("response error should not be non-nil. got=nil")
+				}
+				if !strings.Contains(err.Error(), tc.expectedErrMessage) {
+					t.Fatalf("reponse error message wrong. '%s' is expected to contain '%s'", err.Error(), tc.expectedErrMessage)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_TranslateSentenceEncoding(t *testing.T) {
+	tt := []struct {
+		name string
+
+		inputEncoding RequestEncoding
+
+		expectedBody string
+	}{
+		{
+			name: "form by default",
+
+			inputEncoding: RequestEncodingAuto,
+
+			expectedBody: "source_lang=EN&target_lang=JA&text=hello",
+		},
+		{
+			name: "json option",
+
+			inputEncoding: RequestEncodingJSON,
+
+			expectedBody: `{"text":["hello"],"source_lang":"EN","target_lang":"JA"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, teardown := initTestServer(t, "testdata/TranslateText/success-header", "testdata/TranslateText/success-body", http.MethodPost, "/v2/translate", "", tc.expectedBody)
+			defer teardown()
+			if err := WithRequestEncoding(tc.inputEncoding)(cli); err != nil {
+				t.Fatalf("option error should be nil. got=%s", err.Error())
+			}
+
+			if _, err := cli.TranslateSentence(context.Background(), "hello", "EN", "JA"); err != nil {
+				t.Fatalf("response error should be nil. got=%s", err.Error())
+			}
+		})
+	}
+}
+
+func TestClient_Translate(t *testing.T) {
+	tt := []struct {
+		name string
+
+		inputRequest  TranslateRequest
+		inputEncoding RequestEncoding
+
+		expectedBody     string
+		expectedResponse *TranslateResult
+	}{
+		{
+			name: "zero values omitted",
+
+			inputRequest: TranslateRequest{Text: []string{"hello"}, TargetLang: "JA"},
+
+			expectedBody:     `{"text":["hello"],"target_lang":"JA"}`,
+			expectedResponse: &TranslateResult{Translations: []Translation{{DetectedSourceLanguage: "EN", Text: "こんにちは", BilledCharacters: 5, ModelTypeUsed: "quality_optimized", Source: SourceAPI}}},
+		},
+		{
+			name: "all options as json",
+
+			inputRequest: TranslateRequest{
+				Text:                 []string{"hello"},
+				SourceLang:           "EN",
+				TargetLang:           "JA",
+				Context:              "greeting",
+				SplitSentences:       SplitSentencesNoNewlines,
+				PreserveFormatting:   true,
+				Formality:            FormalityMore,
+				GlossaryID:           "g1",
+				TagHandling:          TagHandlingXML,
+				NonSplittingTags:     []string{"a", "b"},
+				SplittingTags:        []string{"p"},
+				IgnoreTags:           []string{"x"},
+				ModelType:            ModelTypeQualityOptimized,
+				ShowBilledCharacters: true,
+			},
+
+			expectedBody:     `{"text":["hello"],"source_lang":"EN","target_lang":"JA","context":"greeting","split_sentences":"nonewlines","preserve_formatting":true,"formality":"more","glossary_id":"g1","tag_handling":"xml","non_splitting_tags":["a","b"],"splitting_tags":["p"],"ignore_tags":["x"],"model_type":"quality_optimized","show_billed_characters":true}`,
+			expectedResponse: &TranslateResult{Translations: []Translation{{DetectedSourceLanguage: "EN", Text: "こんにちは", BilledCharacters: 5, ModelTypeUsed: "quality_optimized", Source: SourceAPI}}},
+		},
+		{
+			name: "all options as form",
+
+			inputRequest: TranslateRequest{
+				Text:                 []string{"hello"},
+				SourceLang:           "EN",
+				TargetLang:           "JA",
+				Context:              "greeting",
+				SplitSentences:       SplitSentencesNoNewlines,
+				PreserveFormatting:   true,
+				Formality:            FormalityMore,
+				GlossaryID:           "g1",
+				TagHandling:          TagHandlingXML,
+				NonSplittingTags:     []string{"a", "b"},
+				SplittingTags:        []string{"p"},
+				IgnoreTags:           []string{"x"},
+				ModelType:            ModelTypeQualityOptimized,
+				ShowBilledCharacters: true,
+			},
+			inputEncoding: RequestEncodingForm,
+
+			expectedBody:     "context=greeting&formality=more&glossary_id=g1&ignore_tags=x&model_type=quality_optimized&non_splitting_tags=a%2Cb&preserve_formatting=1&show_billed_characters=1&source_lang=EN&split_sentences=nonewlines&splitting_tags=p&tag_handling=xml&target_lang=JA&text=hello",
+			expectedResponse: &TranslateResult{Translations: []Translation{{DetectedSourceLanguage: "EN", Text: "こんにちは", BilledCharacters: 5, ModelTypeUsed: "quality_optimized", Source: SourceAPI}}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, teardown := initTestServer(t, "testdata/Translate/success-header", "testdata/Translate/success-body", http.MethodPost, "/v2/translate", "", tc.expectedBody)
+			defer teardown()
+			cli.RequestEncoding = tc.inputEncoding
+
+			correctResponse, err := cli.Translate(context.Background(), tc.inputRequest)
+			if err != nil {
+				t.Fatalf("response error should be nil. got=%s", err.Error())
+			}
+			if len(correctResponse.Translations) != len(tc.expectedResponse.Translations) {
+				t.Fatalf("response items wrong. want=%+v, got=%+v", tc.expectedResponse, correctResponse)
+			}
+			for i, v := range correctResponse.Translations {
+				if v != tc.expectedResponse.Translations[i] {
+					t.Fatalf("response items wrong. want=%+v, got=%+v", tc.expectedResponse, correctResponse)
+				}
+			}
+			if got := correctResponse.Texts(); len(got) != 1 || got[0] != "こんにちは" {
+				t.Fatalf("texts wrong. got=%v", got)
+			}
+		})
+	}
+}
+
+// initTranslateServer starts a mock /v2/translate endpoint that decodes JSON
+// requests and answers with translate(req). Received requests are appended to
+// the returned slice.
+func initTranslateServer(t *testing.T, translate func(req TranslateRequest) []Translation) (*Client, *[]TranslateRequest, func()) {
+	var received []TranslateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var r TranslateRequest
+		if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+			t.Fatalf("failed to decode request body: %s", err.Error())
+		}
+		received = append(received, r)
+		if err := json.NewEncoder(w).Encode(TranslateResult{Translations: translate(r)}); err != nil {
+			t.Fatalf("failed to encode response body: %s", err.Error())
+		}
+	}))
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to get mock server URL: %s", err.Error())
+	}
+	cli := &Client{BaseURL: serverURL, HTTPClient: server.Client(), APIKey: testAPIKey}
+	return cli, &received, server.Close
+}
+
+// prefixTranslations translates every text by prefixing the target language.
+func prefixTranslations(req TranslateRequest) []Translation {
+	translations := make([]Translation, len(req.Text))
+	for i, text := range req.Text {
+		translations[i] = Translation{DetectedSourceLanguage: "EN", Text: req.TargetLang + ":" + text}
+	}
+	return translations
+}
+
+func TestClient_TranslateRawResponse(t *testing.T) {
+	body := `{"translations":[{"detected_source_language":"EN","text":"Hallo","new_field":42}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	cli, err := New(server.URL, nil, WithAPIKey(testAPIKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var raw json.RawMessage
+	res, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"}, WithRawResponse(&raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != body {
+		t.Fatalf("raw response wrong. want=%s, got=%s", body, raw)
+	}
+	if res.Translations[0].Text != "Hallo" {
+		t.Fatalf("decoded response wrong: %+v", res.Translations)
+	}
+
+	body = `{"translations":[{"text":"` + strings.Repeat("a", maxRawResponse) + `"}]}`
+	if _, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"Hello"}, TargetLang: "DE"}, WithRawResponse(&raw)); err != nil {
+		t.Fatal(err)
+	}
+	if raw != nil {
+		t.Fatalf("oversized body was kept (%d bytes)", len(raw))
+	}
+}
+
+func TestClient_TranslateValidatesTranslations(t *testing.T) {
+	tt := []struct {
+		name string
+
+		body  string
+		texts []string
+
+		expectedErrMessage string
+	}{
+		{name: "null translations", body: `{"translations":null}`, texts: []string{"a"}, expectedErrMessage: "expected 1 translations, got 0"},
+		{name: "missing translations", body: `{}`, texts: []string{"a"}, expectedErrMessage: "expected 1 translations, got 0"},
+		{name: "too few translations", body: `{"translations":[{"text":"A"}]}`, texts: []string{"a", "b"}, expectedErrMessage: "expected 2 translations, got 1"},
+		{name: "matching count", body: `{"translations":[{"text":"A"},{"text":"B"}]}`, texts: []string{"a", "b"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+			cli, err := New(server.URL, nil, WithAPIKey(testAPIKey))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := cli.Translate(context.Background(), TranslateRequest{Text: tc.texts, TargetLang: "DE"})
+			if tc.expectedErrMessage != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErrMessage) {
+					t.Fatalf("expected error containing %q, got %v", tc.expectedErrMessage, err)
+				}
+				if !xerrors.Is(err, ErrUnexpectedResponse) {
+					t.Fatalf("expected ErrUnexpectedResponse, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res.Translations) != len(tc.texts) {
+				t.Fatalf("got %d translations", len(res.Translations))
+			}
+		})
+	}
+}
+
+func TestTranslateResponse_Validate(t *testing.T) {
+	if err := (&TranslateResponse{}).Validate(); err == nil {
+		t.Fatal("expected error for response without translations")
+	}
+	if err := (&TranslateResponse{Translations: []Translation{{Text: "Hallo"}}}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// calls of the client know the number of texts
+	r := &TranslateResponse{Translations: []Translation{{Text: "Hallo"}}}
+	if err := r.validate(1); err != nil {
+		t.Fatal(err)
+	}
+	var countErr *TranslationCountError
+	if err := r.validate(2); !xerrors.As(err, &countErr) || countErr.Expected != 2 || countErr.Got != 1 {
+		t.Fatalf("expected *TranslationCountError, got %v", err)
+	}
+}
+
+func TestClient_TranslateSoftFail(t *testing.T) {
+	tt := []struct {
+		name string
+
+		statusCode int
+		client     bool
+		call       bool
+
+		expectedSoftFail bool
+	}{
+		{name: "outage with client option", statusCode: http.StatusServiceUnavailable, client: true, expectedSoftFail: true},
+		{name: "rate limited with call option", statusCode: http.StatusTooManyRequests, call: true, expectedSoftFail: true},
+		{name: "outage without soft fail", statusCode: http.StatusServiceUnavailable},
+		{name: "auth error fails hard", statusCode: http.StatusForbidden, client: true},
+		{name: "invalid request fails hard", statusCode: http.StatusBadRequest, client: true},
+		{name: "quota error fails hard", statusCode: StatusQuotaExceeded, call: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer server.Close()
+			cli, err := New(server.URL, nil, WithAPIKey(testAPIKey))
+			if err != nil {
+				t.Fatal(err)
+			}
+			cli.MaxRetries = 0
+			cli.SoftFail = tc.client
+			var opts []TranslateOption
+			if tc.call {
+				opts = append(opts, WithCallSoftFail())
+			}
+
+			res, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"Hello", "World"}, TargetLang: "DE"}, opts...)
+			if !tc.expectedSoftFail {
+				if err == nil {
+					t.Fatal("expected hard error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !res.Metadata.Failed || !IsRetryable(res.Metadata.Err) {
+				t.Fatalf("unexpected metadata %+v", res.Metadata)
+			}
+			if texts := res.Texts(); len(texts) != 2 || texts[0] != "Hello" || texts[1] != "World" {
+				t.Fatalf("expected source texts, got %q", texts)
+			}
+			if res.Translations[0].Source != SourceUntranslated {
+				t.Fatalf("unexpected source %q", res.Translations[0].Source)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package deepl
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+// Default thresholds of WithTruncationCheck. Translations into Chinese,
+// Japanese and Korean are legitimately much shorter in characters.
+const (
+	defaultTruncationRatio    = 0.2
+	defaultCJKTruncationRatio = 0.08
+	defaultTruncationMinChars = 200
+)
+
+// ErrSuspectedTruncation matches the *TruncationError returned in strict
+// mode of WithTruncationCheck.
+var ErrSuspectedTruncation = xerrors.New("Translation looks truncated")
+
+// TruncationCheck configures WithTruncationCheck.
+type TruncationCheck struct {
+	// MinRatio is the lowest accepted ratio of translation to source
+	// characters, 0.2 by default and 0.08 for targets ZH, JA and KO.
+	MinRatio float64
+	// Pairs override MinRatio for language pairs.
+	Pairs []TruncationPair
+	// MinSourceChars is the length below which texts are not checked, 200
+	// by default.
+	MinSourceChars int
+	// Strict fails calls with a *TruncationError instead of reporting
+	// suspects in Metadata.SuspectedTruncations.
+	Strict bool
+}
+
+// TruncationPair sets the minimum ratio from Source to Target. Source may
+// be "*" to match any source language and a pair for the exact source
+// takes precedence. Regional variants match their base language.
+type TruncationPair struct {
+	Source, Target string
+	MinRatio       float64
+}
+
+// SuspectedTruncation describes a translation that is much shorter than
+// its source.
+type SuspectedTruncation struct {
+	Index            int
+	SourceChars      int
+	TranslationChars int
+	Ratio            float64
+	MinRatio         float64
+}
+
+// TruncationError is returned in strict mode of WithTruncationCheck.
+type TruncationError struct {
+	Suspects []SuspectedTruncation
+}
+
+func (e *TruncationError) Error() string {
+	s := e.Suspects[0]
+	return fmt.Sprintf("%s: text %d has %d characters, its translation %d, a ratio of %.2f below %.2f",
+		ErrSuspectedTruncation.Error(), s.Index, s.SourceChars, s.TranslationChars, s.Ratio, s.MinRatio)
+}
+
+func (e *TruncationError) Is(target error) bool {
+	return target == ErrSuspectedTruncation
+}
+
+// WithTruncationCheck flags translations whose length compared to their
+// source falls below a ratio, which suggests DeepL stopped early. The check
+// is a heuristic, so the defaults only catch drastic cases.
+func WithTruncationCheck(check TruncationCheck) Option {
+	return func(c *Client) error {
+		if check.MinRatio < 0 || check.MinSourceChars < 0 {
+			return xerrors.Errorf("Failed to configure truncation check: invalid ratio %v or length %d", check.MinRatio, check.MinSourceChars)
+		}
+		if check.MinSourceChars == 0 {
+			check.MinSourceChars = defaultTruncationMinChars
+		}
+		pairs := make(map[pairKey]float64, len(check.Pairs))
+		for _, p := range check.Pairs {
+			if p.MinRatio < 0 {
+				return xerrors.Errorf("Failed to configure truncation check: invalid ratio %v for %s to %s", p.MinRatio, p.Source, p.Target)
+			}
+			pairs[newPairKey(baseLanguage(p.Source), baseLanguage(p.Target))] = p.MinRatio
+		}
+		c.truncationCheck = &truncationChecker{TruncationCheck: check, pairs: pairs}
+		return nil
+	}
+}
+
+type truncationChecker struct {
+	TruncationCheck
+	pairs map[pairKey]float64
+}
+
+func baseLanguage(lang string) string {
+	if i := strings.IndexByte(lang, '-'); i >= 0 {
+		return lang[:i]
+	}
+	return lang
+}
+
+// minRatio returns the threshold from src to dst.
+func (t *truncationChecker) minRatio(src, dst string) float64 {
+	key := newPairKey(baseLanguage(src), baseLanguage(dst))
+	if ratio, ok := t.pairs[key]; ok {
+		return ratio
+	}
+	key.src = "*"
+	if ratio, ok := t.pairs[key]; ok {
+		return ratio
+	}
+	if t.MinRatio > 0 {
+		return t.MinRatio
+	}
+	switch key.dst {
+	case "ZH", "JA", "KO":
+		return defaultCJKTruncationRatio
+	}
+	return defaultTruncationRatio
+}
+
+// check returns the translations of req that look truncated.
+func (t *truncationChecker) check(req *TranslateRequest, translations []Translation) []SuspectedTruncation {
+	var suspects []SuspectedTruncation
+	for i, tr := range translations {
+		if i >= len(req.Text) || tr.Source != SourceAPI {
+			continue
+		}
+		sourceChars := utf8.RuneCountInString(req.Text[i])
+		if sourceChars < t.MinSourceChars {
+			continue
+		}
+		src := req.SourceLang
+		if src == "" {
+			src = tr.DetectedSourceLanguage
+		}
+		minRatio := t.minRatio(src, req.TargetLang)
+		translationChars := utf8.RuneCountInString(tr.Text)
+		if ratio := float64(translationChars) / float64(sourceChars); ratio < minRatio {
+			suspects = append(suspects, SuspectedTruncation{
+				Index:            i,
+				SourceChars:      sourceChars,
+				TranslationChars: translationChars,
+				Ratio:            ratio,
+				MinRatio:         minRatio,
+			})
+		}
+	}
+	return suspects
+}
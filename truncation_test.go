@@ -0,0 +1,111 @@
+package deepl
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
+)
+
+func TestTruncationChecker(t *testing.T) {
+	long := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 10)
+	tt := []struct {
+		name string
+
+		check       TruncationCheck
+		req         TranslateRequest
+		translation Translation
+
+		expected []SuspectedTruncation
+	}{
+		{
+			name: "truncated",
+
+			req:         TranslateRequest{Text: []string{long}, SourceLang: "EN", TargetLang: "DE"},
+			translation: Translation{Text: "Der schnelle braune", Source: SourceAPI},
+
+			expected: []SuspectedTruncation{{SourceChars: 450, TranslationChars: 19, Ratio: 19.0 / 450, MinRatio: 0.2}},
+		},
+		{
+			name: "shorter chinese translation",
+
+			req:         TranslateRequest{Text: []string{long}, TargetLang: "ZH-HANS"},
+			translation: Translation{DetectedSourceLanguage: "EN", Text: strings.Repeat("敏捷的棕色狐狸跳过了懒狗。", 4), Source: SourceAPI},
+		},
+		{
+			name: "short source not checked",
+
+			req:         TranslateRequest{Text: []string{"A long sentence that is still below the minimum length."}, TargetLang: "DE"},
+			translation: Translation{Text: "Kurz", Source: SourceAPI},
+		},
+		{
+			name: "translation memory not checked",
+
+			req:         TranslateRequest{Text: []string{long}, TargetLang: "DE"},
+			translation: Translation{Text: "x", Source: SourceTM},
+		},
+		{
+			name: "pair threshold",
+
+			check:       TruncationCheck{Pairs: []TruncationPair{{Source: "*", Target: "DE", MinRatio: 0.5}, {Source: "EN-GB", Target: "DE", MinRatio: 0.9}}},
+			req:         TranslateRequest{Text: []string{long}, SourceLang: "EN", TargetLang: "DE"},
+			translation: Translation{Text: long[:360], Source: SourceAPI},
+
+			expected: []SuspectedTruncation{{SourceChars: 450, TranslationChars: 360, Ratio: 0.8, MinRatio: 0.9}},
+		},
+		{
+			name: "wildcard pair",
+
+			check:       TruncationCheck{Pairs: []TruncationPair{{Source: "*", Target: "DE", MinRatio: 0.5}}},
+			req:         TranslateRequest{Text: []string{long}, SourceLang: "FR", TargetLang: "DE"},
+			translation: Translation{Text: long[:180], Source: SourceAPI},
+
+			expected: []SuspectedTruncation{{SourceChars: 450, TranslationChars: 180, Ratio: 0.4, MinRatio: 0.5}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := &Client{}
+			if err := WithTruncationCheck(tc.check)(cli); err != nil {
+				t.Fatal(err)
+			}
+			got := cli.truncationCheck.check(&tc.req, []Translation{tc.translation})
+			if len(got) != len(tc.expected) || (len(got) > 0 && got[0] != tc.expected[0]) {
+				t.Fatalf("got %+v, expected %+v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestClient_TruncationCheck(t *testing.T) {
+	cli, _, teardown := initTranslateServer(t, func(req TranslateRequest) []Translation {
+		translations := prefixTranslations(req)
+		translations[1].Text = "Es war einmal"
+		return translations
+	})
+	defer teardown()
+	if err := WithTruncationCheck(TruncationCheck{MinSourceChars: 20})(cli); err != nil {
+		t.Fatal(err)
+	}
+
+	texts := []string{"Short", "Once upon a time there was a very long story that went on and on. Really."}
+	result, err := cli.Translate(context.Background(), TranslateRequest{Text: texts, TargetLang: "DE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	suspects := result.Metadata.SuspectedTruncations
+	if len(suspects) != 1 || suspects[0].Index != 1 || suspects[0].MinRatio != defaultTruncationRatio {
+		t.Fatalf("unexpected suspects %+v", suspects)
+	}
+
+	cli.truncationCheck.Strict = true
+	_, err = cli.Translate(ContextWithRequestID(context.Background(), "r1"), TranslateRequest{Text: texts, TargetLang: "DE"})
+	if !xerrors.Is(err, ErrSuspectedTruncation) {
+		t.Fatalf("expected ErrSuspectedTruncation, got %v", err)
+	}
+	if msg := err.Error(); msg != "Translation looks truncated: text 1 has 73 characters, its translation 13, a ratio of 0.18 below 0.20 (request r1)" {
+		t.Fatalf("unexpected message %q", msg)
+	}
+}
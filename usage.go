@@ -0,0 +1,85 @@
+package deepl
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// maxWatchBackoff caps the poll interval of WatchUsage after failures, as a
+// multiple of the interval.
+const maxWatchBackoff = 16
+
+// UsageEvent is one poll of WatchUsage. Either Status or Err is set.
+type UsageEvent struct {
+	Time   time.Time
+	Status *AccountStatus
+	Err    error
+	// LastStatus is the last status received and LastSuccess when it was
+	// received, for failed polls as well, so that consumers can go on with
+	// a stale status and alert on its age. Both are zero before the first
+	// successful poll.
+	LastStatus  *AccountStatus
+	LastSuccess time.Time
+}
+
+// WatchUsage polls /v2/usage every interval, starting immediately, and
+// sends the results until ctx is done, then closes the channel. With
+// skipUnchanged, statuses equal to the last one sent are dropped. Failed
+// polls are logged and sent as events with Err and don't stop the watcher;
+// the interval doubles with each consecutive failure, up to 16 times.
+func (c *Client) WatchUsage(ctx context.Context, interval time.Duration, skipUnchanged bool) (<-chan UsageEvent, error) {
+	if interval <= 0 {
+		return nil, xerrors.Errorf("Failed to watch usage: invalid interval %v", interval)
+	}
+
+	events := make(chan UsageEvent, 1)
+	go func() {
+		defer close(events)
+		var last *AccountStatus
+		var lastSuccess time.Time
+		failures := 0
+		for {
+			status, err := c.GetAccountStatus(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			now := c.clock().Now()
+
+			delay := interval
+			if err != nil {
+				c.logf(ctx, "Failed to poll usage: %v", err)
+				failures++
+				backoff := 1 << uint(failures)
+				if backoff > maxWatchBackoff {
+					backoff = maxWatchBackoff
+				}
+				delay = interval * time.Duration(backoff)
+			} else {
+				failures = 0
+				lastSuccess = now
+			}
+
+			if err != nil || !skipUnchanged || last == nil || *status != *last {
+				if err == nil {
+					last = status
+				}
+				select {
+				case events <- UsageEvent{Time: now, Status: status, Err: err, LastStatus: last, LastSuccess: lastSuccess}:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			timer := c.clock().NewTimer(delay)
+			select {
+			case <-timer.C():
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	}()
+	return events, nil
+}
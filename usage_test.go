@@ -0,0 +1,116 @@
+package deepl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestClient_WatchUsage(t *testing.T) {
+	responses := []string{
+		`{"character_count":1,"character_limit":10}`,
+		`{"character_count":1,"character_limit":10}`,
+		"",
+		`{"character_count":2,"character_limit":10}`,
+	}
+	var polls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&polls, 1)) - 1
+		if n >= len(responses) {
+			n = len(responses) - 1
+		}
+		if responses[n] == "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(responses[n]))
+	}))
+	defer ts.Close()
+
+	cli, err := New(ts.URL, nil, WithAPIKey(testAPIKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.MaxRetries = 0
+	if _, err := cli.WatchUsage(context.Background(), 0, false); err == nil {
+		t.Fatal("expected error for a zero interval")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := cli.WatchUsage(ctx, time.Millisecond, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []UsageEvent
+	for event := range events {
+		got = append(got, event)
+		if len(got) == 3 {
+			cancel()
+		}
+	}
+	if len(got) < 3 {
+		t.Fatalf("got %d events", len(got))
+	}
+	if got[0].Status == nil || got[0].Status.CharacterCount != 1 {
+		t.Fatalf("first event wrong: %+v", got[0])
+	}
+	if got[1].Err == nil || !IsRetryable(got[1].Err) {
+		t.Fatalf("unchanged status not skipped or failure not reported: %+v", got[1])
+	}
+	if got[1].LastStatus == nil || got[1].LastStatus.CharacterCount != 1 || got[1].LastSuccess.Before(got[0].Time) {
+		t.Fatalf("stale status missing from failure: %+v", got[1])
+	}
+	if got[2].Status == nil || got[2].Status.CharacterCount != 2 {
+		t.Fatalf("watcher did not recover: %+v", got[2])
+	}
+}
+
+func TestClient_WatchUsageBackoff(t *testing.T) {
+	var polls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&polls, 1) <= 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"character_count":1,"character_limit":10}`))
+	}))
+	defer ts.Close()
+
+	clock := newFakeClock()
+	start := clock.Now()
+	cli, err := New(ts.URL, nil, WithAPIKey(testAPIKey), WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.MaxRetries = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := cli.WatchUsage(ctx, time.Minute, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// two failures wait two and four minutes
+	for _, delay := range []time.Duration{2 * time.Minute, 4 * time.Minute} {
+		if event := <-events; event.Err == nil {
+			t.Fatalf("expected failure, got %+v", event)
+		}
+		clock.waitTimers(t, 1)
+		clock.Advance(delay - time.Second)
+		if clock.Timers() != 1 {
+			t.Fatalf("polled before the backoff of %v", delay)
+		}
+		clock.Advance(time.Second)
+	}
+	event := <-events
+	if event.Status == nil || !event.Time.Equal(start.Add(6*time.Minute)) {
+		t.Fatalf("unexpected event %+v", event)
+	}
+}
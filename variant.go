@@ -0,0 +1,35 @@
+package deepl
+
+import (
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// WithVariantPreference sets the regional variant used for a bare target
+// language, e.g. {"EN": "EN-GB", "PT": "PT-PT"}. It applies to translate
+// requests and Accept-Language negotiation; a regional target given by the
+// caller is kept.
+func WithVariantPreference(prefs map[string]string) Option {
+	return func(c *Client) error {
+		normalized := make(map[string]string, len(prefs))
+		for base, variant := range prefs {
+			base, variant = strings.ToUpper(strings.TrimSpace(base)), strings.ToUpper(strings.TrimSpace(variant))
+			if strings.Contains(base, "-") || !strings.HasPrefix(variant, base+"-") {
+				return xerrors.Errorf("Failed to set variant preference: %s is not a regional variant of %s", variant, base)
+			}
+			normalized[base] = variant
+		}
+		c.VariantPreference = normalized
+		return nil
+	}
+}
+
+// preferredVariant returns the preferred variant of target if it is a bare
+// language, and target otherwise.
+func (c *Client) preferredVariant(target string) string {
+	if variant, ok := c.VariantPreference[strings.ToUpper(strings.TrimSpace(target))]; ok {
+		return variant
+	}
+	return target
+}
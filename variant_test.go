@@ -0,0 +1,68 @@
+package deepl
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestNegotiateTargetLang_VariantPreference(t *testing.T) {
+	supported := loadTargetLanguages(t)
+	prefs := map[string]string{"EN": "EN-US", "PT": "PT-PT", "ZH": "ZH-HANT"}
+
+	tt := []struct {
+		acceptLanguage string
+
+		expected string
+	}{
+		{acceptLanguage: "en", expected: "EN-US"},
+		{acceptLanguage: "en-GB,en;q=0.9", expected: "EN-GB"},
+		{acceptLanguage: "en-AU", expected: "EN-US"},
+		{acceptLanguage: "pt", expected: "PT-PT"},
+		{acceptLanguage: "zh", expected: "ZH-HANT"},
+		{acceptLanguage: "en-US;q=0, en", expected: "EN-GB"},
+		{acceptLanguage: "de", expected: "DE"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.acceptLanguage, func(t *testing.T) {
+			lang, err := negotiateTargetLang(tc.acceptLanguage, supported, prefs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lang.Code != tc.expected {
+				t.Fatalf("negotiated language wrong. want=%s, got=%s", tc.expected, lang.Code)
+			}
+		})
+	}
+}
+
+func TestClient_VariantPreference(t *testing.T) {
+	for _, prefs := range []map[string]string{{"EN": "PT-PT"}, {"EN": "EN"}, {"EN-US": "EN-US-X"}} {
+		if _, err := New("https://api.deepl.com", nil, WithVariantPreference(prefs)); err == nil {
+			t.Errorf("expected error for %v", prefs)
+		}
+	}
+
+	cli, received, teardown := initTranslateServer(t, prefixTranslations)
+	defer teardown()
+	if err := WithVariantPreference(map[string]string{"en": "en-gb"})(cli); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, target := range []string{"en", "EN-US", "DE"} {
+		if _, err := cli.Translate(context.Background(), TranslateRequest{Text: []string{"Hello"}, TargetLang: target}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// TranslateSentence sends forms unless JSON is requested
+	cli.RequestEncoding = RequestEncodingJSON
+	if _, err := cli.TranslateSentence(context.Background(), "Hello", "", "EN"); err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []string{"EN-GB", "EN-US", "DE", "EN-GB"} {
+		if got := (*received)[i].TargetLang; got != expected {
+			t.Errorf("request %d sent to %s, expected %s", i, got, expected)
+		}
+	}
+}
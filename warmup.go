@@ -0,0 +1,65 @@
+package deepl
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const backgroundWarmupTimeout = 10 * time.Second
+
+// Warmup sends a HEAD request to the API host so that DNS, TCP and TLS setup
+// are done before the first translation and the connection is kept in the
+// HTTPClient's pool. It is authenticated like any other request and its
+// response status is ignored. Clients made with WithPseudoTranslation don't
+// send it. Warmup is safe to call repeatedly and its error is informational
+// only.
+func (c *Client) Warmup(ctx context.Context) error {
+	if c.pseudo != nil {
+		return nil
+	}
+	apiKey, err := c.apiKey()
+	if err != nil {
+		return err
+	}
+	const apiPath = "/v2/usage"
+	r, err := c.newAPIRequest(http.MethodHead, apiPath, nil, apiKey)
+	if err != nil {
+		return err
+	}
+	req, err := r.httpRequest(ctx, c.endpoint(apiPath))
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return xerrors.Errorf("Failed to warm up connection: %w", err)
+	}
+	// the body must be drained for the connection to be reused
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	return nil
+}
+
+// WithWarmup calls Warmup in the background once the client is created.
+// Failures are logged and otherwise ignored.
+func WithWarmup() Option {
+	return func(c *Client) error {
+		c.warmupOnCreate = true
+		return nil
+	}
+}
+
+func (c *Client) backgroundWarmup() {
+	ctx, cancel := context.WithTimeout(context.Background(), backgroundWarmupTimeout)
+	defer cancel()
+
+	if err := c.Warmup(ctx); err != nil {
+		c.logf(ctx, "Warmup failed: %v", err)
+	}
+}
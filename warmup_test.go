@@ -0,0 +1,95 @@
+package deepl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestClient_Warmup(t *testing.T) {
+	requests := make(chan *http.Request, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests <- req
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to get mock server URL: %s", err.Error())
+	}
+	cli := &Client{BaseURL: serverURL, HTTPClient: server.Client(), APIKey: testAPIKey}
+
+	for i := 0; i < 2; i++ {
+		if err := cli.Warmup(context.Background()); err != nil {
+			t.Fatalf("warmup error should be nil. got=%s", err.Error())
+		}
+		req := <-requests
+		if req.Method != http.MethodHead {
+			t.Fatalf("request method wrong. want=%s, got=%s", http.MethodHead, req.Method)
+		}
+		if req.URL.RawQuery != "" {
+			t.Fatalf("warmup should not send a query. got=%s", req.URL.RawQuery)
+		}
+		if req.Header.Get("Authorization") != "DeepL-Auth-Key "+testAPIKey || req.Header.Get("User-Agent") != "Deepl-Go-Client" {
+			t.Fatalf("warmup headers wrong. got=%v", req.Header)
+		}
+	}
+}
+
+func TestClient_WarmupUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	serverURL, _ := url.Parse(server.URL)
+	server.Close()
+
+	cli := &Client{BaseURL: serverURL, HTTPClient: http.DefaultClient, APIKey: testAPIKey}
+	if err := cli.Warmup(context.Background()); err == nil {
+		t.Fatalf("warmup error should not be non-nil. got=nil")
+	}
+}
+
+func TestClient_WarmupPseudo(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests <- req
+	}))
+	defer server.Close()
+
+	cli, err := New(server.URL, nil, WithAPIKey(testAPIKey), WithPseudoTranslation(NewPseudoTranslator()), WithWarmup())
+	if err != nil {
+		t.Fatalf("new error should be nil. got=%s", err.Error())
+	}
+	if err := cli.Warmup(context.Background()); err != nil {
+		t.Fatalf("warmup error should be nil. got=%s", err.Error())
+	}
+	select {
+	case req := <-requests:
+		t.Fatalf("pseudo-translating client sent %s %s", req.Method, req.URL.Path)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWithWarmup(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests <- req
+	}))
+	defer server.Close()
+
+	if _, err := New(server.URL, nil, WithAPIKey(testAPIKey), WithWarmup()); err != nil {
+		t.Fatalf("new error should be nil. got=%s", err.Error())
+	}
+
+	select {
+	case req := <-requests:
+		if req.Method != http.MethodHead {
+			t.Fatalf("request method wrong. want=%s, got=%s", http.MethodHead, req.Method)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("background warmup did not send a request")
+	}
+}
@@ -0,0 +1,89 @@
+package deepl
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// WireFeatures describes the protocol choices of a client, for audit logs
+// of pipelines that must know how requests are sent. Fields are only added,
+// never renamed.
+type WireFeatures struct {
+	// Auth is where the API key is sent, "header" for the Authorization
+	// header or "query" for the auth_key parameter.
+	Auth string `json:"auth"`
+	// TranslateEncoding is the body encoding of Translate and the batch
+	// helpers and LegacyEncoding that of TranslateSentence, "json" or
+	// "form".
+	TranslateEncoding string `json:"translate_encoding"`
+	LegacyEncoding    string `json:"legacy_encoding"`
+	// APIPath is the path the endpoints are resolved under, such as "/v2".
+	APIPath string `json:"api_path"`
+	// Fallback is set when failed requests may be resent to
+	// FallbackBaseURL.
+	Fallback bool `json:"fallback"`
+	// ForceHTTP1 is set when HTTP/2 was disabled by WithForceHTTP1.
+	ForceHTTP1 bool `json:"force_http1"`
+	// IdempotencyKeys is set when every call sends IdempotencyKeyHeader.
+	IdempotencyKeys bool `json:"idempotency_keys"`
+	// NormalizeNewlines is set when texts are sent with \n line endings.
+	NormalizeNewlines bool `json:"normalize_newlines"`
+	// Placeholders is set when ProtectedPatterns replace tokens with
+	// placeholders in the sent texts.
+	Placeholders bool `json:"placeholders"`
+	// Pseudo is set when translate requests are answered by a
+	// PseudoTranslator instead of being sent.
+	Pseudo bool `json:"pseudo"`
+}
+
+// WireFeatures returns the protocol choices of c, derived from the request
+// builder.
+func (c *Client) WireFeatures() WireFeatures {
+	f := WireFeatures{
+		Auth:              "none",
+		TranslateEncoding: bodyEncoding(c.translateBody(&TranslateRequest{}, false)),
+		LegacyEncoding:    bodyEncoding(c.translateBody(&TranslateRequest{}, true)),
+		Fallback:          c.FallbackBaseURL != nil,
+		ForceHTTP1:        c.transport != nil && c.transport.TLSNextProto != nil && !c.transport.ForceAttemptHTTP2,
+		IdempotencyKeys:   c.IdempotencyKeys,
+		NormalizeNewlines: c.NormalizeNewlines,
+		Placeholders:      len(c.ProtectedPatterns) > 0,
+		Pseudo:            c.pseudo != nil,
+	}
+	if c.BaseURL != nil {
+		f.APIPath = path.Dir(resolveEndpoint(c.BaseURL, "/v2/translate").path)
+	}
+	if r, err := c.newAPIRequest(http.MethodPost, "/v2/translate", nil, AuthKeyPlaceholder); err == nil {
+		switch {
+		case r.authorization != "":
+			f.Auth = "header"
+		case r.query.Get("auth_key") != "":
+			f.Auth = "query"
+		}
+	}
+	return f
+}
+
+func bodyEncoding(body RequestBody) string {
+	switch body.(type) {
+	case JSONBody:
+		return "json"
+	case FormBody:
+		return "form"
+	}
+	return fmt.Sprintf("%T", body)
+}
+
+// String returns the features as space-separated name=value pairs, named
+// like their JSON fields.
+func (f WireFeatures) String() string {
+	v := reflect.ValueOf(f)
+	pairs := make([]string, v.NumField())
+	for i := range pairs {
+		pairs[i] = fmt.Sprintf("%s=%v", v.Type().Field(i).Tag.Get("json"), v.Field(i).Interface())
+	}
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,167 @@
+package deepl
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// wireClientFields classifies every field of Client by the WireFeatures
+// field, named by its JSON name, that reflects it, or "" when the field
+// doesn't change how requests are sent. A new field must be added here.
+var wireClientFields = map[string]string{
+	"BaseURL":             "api_path",
+	"HTTPClient":          "",
+	"Logger":              "",
+	"APIKey":              "auth",
+	"APIKeyProvider":      "auth",
+	"LegacyAuthInQuery":   "auth",
+	"MaxRetries":          "",
+	"RetryBackoff":        "",
+	"RequestEncoding":     "translate_encoding",
+	"FallbackBaseURL":     "fallback",
+	"TranslationMemory":   "",
+	"MissHandler":         "",
+	"TranslationCache":    "",
+	"CallObserver":        "",
+	"UsageTracker":        "",
+	"Clock":               "",
+	"SilenceDeprecations": "",
+	"HTMLEntities":        "",
+	"NormalizeNewlines":   "normalize_newlines",
+	"RestoreNewlines":     "",
+	"ProtectedPatterns":   "placeholders",
+	"PostProcessors":      "",
+	"VariantPreference":   "",
+	"DowngradeFormality":  "",
+	"FormalityRetry":      "",
+	"SoftFail":            "",
+	"AuditRecords":        "",
+	"JSONCodec":           "",
+	"PrivateErrors":       "",
+	"IdempotencyKeys":     "idempotency_keys",
+	"ErrorLocalizer":      "",
+	"MaxTextsPerRequest":  "",
+	"MaxRequestBytes":     "",
+	"BatchMaxWait":        "",
+	"BatchMaxItems":       "",
+	"BatchObserver":       "",
+	"RequestsPerSecond":   "",
+	"RateBurst":           "",
+	"MaxConcurrency":      "",
+	"warmupOnCreate":      "",
+	"characterRate":       "",
+	"pseudo":              "pseudo",
+	"truncationCheck":     "",
+	"duplicates":          "",
+	"pseudoWithoutKey":    "pseudo",
+	"shortInputs":         "",
+	"sourcePolicy":        "",
+	"endpointsOnce":       "",
+	"endpoints":           "",
+	"transport":           "force_http1",
+	"pairProfiles":        "",
+	"callDefaults":        "",
+	"presetDefaults":      "",
+	"languagesMu":         "",
+	"languages":           "",
+	"batcherOnce":         "",
+	"batcher":             "",
+	"clockSkew":           "",
+	"planAuto":            "",
+	"retriesSet":          "",
+	"noEnv":               "",
+	"limitsOnce":          "",
+	"limiter":             "",
+	"semaphore":           "",
+	"semaphoreWaits":      "",
+}
+
+func TestWireFeatures_CoverClientFields(t *testing.T) {
+	features := map[string]bool{}
+	wireType := reflect.TypeOf(WireFeatures{})
+	for i := 0; i < wireType.NumField(); i++ {
+		features[wireType.Field(i).Tag.Get("json")] = true
+	}
+
+	clientType := reflect.TypeOf(Client{})
+	for i := 0; i < clientType.NumField(); i++ {
+		name := clientType.Field(i).Name
+		feature, ok := wireClientFields[name]
+		if !ok {
+			t.Errorf("Client.%s is not classified in wireClientFields; add it to WireFeatures if it changes how requests are sent", name)
+		} else if feature != "" && !features[feature] {
+			t.Errorf("Client.%s maps to missing WireFeatures field %q", name, feature)
+		}
+	}
+	if len(wireClientFields) != clientType.NumField() {
+		t.Errorf("wireClientFields lists %d fields, Client has %d", len(wireClientFields), clientType.NumField())
+	}
+}
+
+func TestClient_WireFeatures(t *testing.T) {
+	tt := []struct {
+		name string
+
+		baseURL string
+		opts    []Option
+		modify  func(c *Client)
+
+		expected string
+	}{
+		{
+			name: "defaults",
+
+			baseURL: "https://api.deepl.com",
+
+			expected: "auth=header translate_encoding=json legacy_encoding=form api_path=/v2 fallback=false force_http1=false idempotency_keys=false normalize_newlines=false placeholders=false pseudo=false",
+		},
+		{
+			name: "options",
+
+			baseURL: "https://proxy.example.com/deepl",
+			opts: []Option{
+				WithRequestEncoding(RequestEncodingForm),
+				WithFallbackBaseURL("https://api-free.deepl.com"),
+				WithForceHTTP1(),
+				WithIdempotencyKeys(),
+				WithPseudoTranslation(NewPseudoTranslator()),
+			},
+			modify: func(c *Client) {
+				c.NormalizeNewlines = true
+				c.ProtectedPatterns = []*regexp.Regexp{regexp.MustCompile(`\d+`)}
+			},
+
+			expected: "auth=header translate_encoding=form legacy_encoding=form api_path=/deepl/v2 fallback=true force_http1=true idempotency_keys=true normalize_newlines=true placeholders=true pseudo=true",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, err := New(tc.baseURL, nil, append([]Option{WithAPIKey(testAPIKey)}, tc.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.modify != nil {
+				tc.modify(cli)
+			}
+			features := cli.WireFeatures()
+			if features.String() != tc.expected {
+				t.Fatalf("features wrong.\nwant=%s\ngot =%s", tc.expected, features)
+			}
+			if !strings.Contains(cli.ExplainConfig(), "Wire: "+tc.expected+"\n") {
+				t.Fatalf("features missing from config:\n%s", cli.ExplainConfig())
+			}
+			b, err := json.Marshal(features)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded WireFeatures
+			if err := json.Unmarshal(b, &decoded); err != nil || decoded != features {
+				t.Fatalf("JSON round trip got %+v, %v", decoded, err)
+			}
+		})
+	}
+}